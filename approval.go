@@ -0,0 +1,172 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	bookingApproverError  = errors.New("User may not approve bookings of the item")
+	approvalRequiredError = errors.New("Item requires approval of its bookings")
+)
+
+// approvalExpiredReason is the reason of the rejection of a pending booking whose hold is over
+const approvalExpiredReason = "Not approved in time"
+
+// ApprovalSource tells which items need their bookings approved and who may approve them.
+type ApprovalSource interface {
+	// ApprovalHold returns how long a new booking of an item is held pending approval, 0 if the item needs no approval
+	ApprovalHold(itemId string) (time.Duration, error)
+	// IsApprover tells whether a user may approve or reject the pending bookings of an item
+	IsApprover(itemId, userId string) (bool, error)
+}
+
+// ItemApproversFunc returns the ids of the users who may approve the bookings of an item besides its owner.
+type ItemApproversFunc func(itemId string) ([]string, error)
+
+/*
+ApprovalRegistry holds which items need their bookings approved, and is an ApprovalSource.
+
+The bookings of an item are approved by its owner, the UserId of the item, or by the users
+given by its ItemApproversFunc, such as the admins of the groups of the item.
+
+An ApprovalRegistry is safe for concurrent use.
+*/
+type ApprovalRegistry struct {
+	mu        sync.RWMutex
+	holds     map[string]time.Duration // Hold of the bookings of every item requiring approval
+	items     IItemRepository[Item]
+	approvers ItemApproversFunc
+}
+
+var _ ApprovalSource = (*ApprovalRegistry)(nil)
+
+/*
+NewApprovalRegistry creates an ApprovalRegistry where no item requires approval.
+
+Parameters:
+  - items: The repository the owners of the items are read from
+  - approvers: Where the other approvers of an item are read from, such as
+    MemoryGroupService.ItemAdminIds. Nil if only owners approve
+
+Returns:
+  - A pointer to a new ApprovalRegistry
+*/
+func NewApprovalRegistry(items IItemRepository[Item], approvers ItemApproversFunc) *ApprovalRegistry {
+	return &ApprovalRegistry{holds: map[string]time.Duration{}, items: items, approvers: approvers}
+}
+
+/*
+Require sets an item to require approval of its bookings.
+
+Parameters:
+  - itemId: The id of the item
+  - hold: How long a new booking blocks its time pending approval before it expires. The item
+    stops requiring approval if it is 0 or less
+*/
+func (r *ApprovalRegistry) Require(itemId string, hold time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hold <= 0 {
+		delete(r.holds, itemId)
+	} else {
+		r.holds[itemId] = hold
+	}
+}
+
+/*
+ApprovalHold returns how long a new booking of an item is held pending approval.
+
+Parameters:
+  - itemId: The id of the item
+
+Returns:
+  - The hold of the bookings of the item, 0 if the item does not require approval
+  - An error, never returned by this implementation
+*/
+func (r *ApprovalRegistry) ApprovalHold(itemId string) (time.Duration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.holds[itemId], nil
+}
+
+/*
+IsApprover tells whether a user may approve or reject the pending bookings of an item.
+
+Parameters:
+  - itemId: The id of the item
+  - userId: The id of the user
+
+Returns:
+  - Whether the user owns the item or is one of its approvers
+  - An error if the item or its approvers cannot be read
+*/
+func (r *ApprovalRegistry) IsApprover(itemId, userId string) (bool, error) {
+	if userId == "" {
+		return false, nil
+	}
+	item, err := r.items.GetItem(itemId)
+	if err != nil {
+		return false, err
+	} else if item.UserId == userId {
+		return true, nil
+	}
+	if r.approvers == nil {
+		return false, nil
+	}
+	approvers, err := r.approvers(itemId)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(approvers, userId), nil
+}
+
+// checkApprover returns an error if a user may not approve or reject a pending booking
+func checkApprover(source ApprovalSource, booking *Booking, userId string) error {
+	approver, err := source.IsApprover(booking.ItemId, userId)
+	if err != nil {
+		return err
+	} else if !approver {
+		return fmt.Errorf("%w: %q for booking %q of item %q", bookingApproverError, userId, booking.Id, booking.ItemId)
+	}
+	return nil
+}
+
+// checkApprovalRequired returns an error if an item requires approval of its bookings
+func checkApprovalRequired(source ApprovalSource, itemId string) error {
+	if source == nil {
+		return nil
+	}
+	hold, err := source.ApprovalHold(itemId)
+	if err != nil {
+		return err
+	} else if hold > 0 {
+		return fmt.Errorf("%w: %q", approvalRequiredError, itemId)
+	}
+	return nil
+}
+
+// holdForApproval makes an active booking pending if its item requires approval, until its hold is over
+func holdForApproval(source ApprovalSource, booking *Booking, now time.Time) error {
+	if source == nil || !booking.State.Active() {
+		return nil
+	}
+	hold, err := source.ApprovalHold(booking.ItemId)
+	if err != nil || hold <= 0 {
+		return err
+	}
+	booking.State, booking.HoldUntil = BookingPending, now.Add(hold)
+	return nil
+}
+
+// expireHold rejects a pending booking whose hold is over at a given time, telling whether it did
+func expireHold(booking *Booking, now time.Time) bool {
+	if booking.State != BookingPending || booking.HoldUntil.IsZero() || booking.HoldUntil.After(now) {
+		return false
+	}
+	// The rejection is dated when the hold ended, so it is the same whenever it is noticed
+	return booking.Transition(BookingRejected, booking.HoldUntil, "", approvalExpiredReason) == nil
+}
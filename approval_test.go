@@ -0,0 +1,189 @@
+package bookk
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestApprovalRegistry creates a registry of the test items, where c is an admin of group g with user b, so it approves z
+func newTestApprovalRegistry(t *testing.T) *ApprovalRegistry {
+	items := newTestItemRepository(t)
+	groups := NewMemoryGroupService(newTestUserRepository(t), items)
+	groups.CreateGroup(Group{BaseGroup: BaseGroup{Id: "g"}})
+	groups.AddUserToGroup("g", "b")
+	groups.AddUserToGroup("g", "c")
+	if err := groups.SetGroupAdmin("g", "c", true); err != nil {
+		t.Fatalf("Admin should be set. Instead: %v", err)
+	}
+	return NewApprovalRegistry(items, groups.ItemAdminIds)
+}
+
+func TestApprovalRegistry(t *testing.T) {
+	registry := newTestApprovalRegistry(t)
+	registry.Require("z", time.Hour)
+	registry.Require("x", time.Hour)
+	registry.Require("x", 0)
+
+	if hold, err := registry.ApprovalHold("z"); err != nil || hold != time.Hour {
+		t.Errorf("No expected hold:\nExpecting\t: %v\nRecieved\t: %v %v", time.Hour, hold, err)
+	}
+	if hold, _ := registry.ApprovalHold("x"); hold != 0 {
+		t.Errorf("Item should not require approval anymore. Instead: %v", hold)
+	}
+
+	type TestCase struct {
+		itemId   string
+		userId   string
+		expected bool
+	}
+	testCases := [6]TestCase{
+		{"z", "b", true},
+		{"z", "c", true},
+		{"z", "a", false},
+		{"z", "", false},
+		{"x", "a", true},
+		{"x", "c", false},
+	}
+	for _, testCase := range testCases {
+		if approver, err := registry.IsApprover(testCase.itemId, testCase.userId); err != nil || approver != testCase.expected {
+			t.Errorf("No expected approver %q of %q:\nExpecting\t: %v\nRecieved\t: %v %v", testCase.userId, testCase.itemId, testCase.expected, approver, err)
+		}
+	}
+	if _, err := registry.IsApprover("missing", "a"); !errors.Is(err, itemNotFoundError) {
+		t.Errorf("Approvers of a missing item should not be found. Instead: %v", err)
+	}
+}
+
+func TestMemoryBookingServiceApprovals(t *testing.T) {
+	now := testTime
+	registry := newTestApprovalRegistry(t)
+	registry.Require("z", 2*time.Hour)
+	service := NewMemoryBookingService(WithApprovals(registry), WithClock(func() time.Time { return now }))
+
+	pending, err := service.CreateBooking(*hoursBooking("pending", "z", 0, 1))
+	if err != nil || pending.State != BookingPending || !pending.HoldUntil.Equal(testTime.Add(2*time.Hour)) {
+		t.Fatalf("Booking should be held pending approval. Instead: %+v %v", pending, err)
+	}
+	if free, _ := service.CreateBooking(*hoursBooking("free", "x", 0, 1)); free.State != BookingConfirmed {
+		t.Errorf("Booking of an item without approval should be confirmed. Instead: %+v", free)
+	}
+	if _, err := service.CreateBooking(*hoursBooking("colliding", "z", 0.5, 1.5)); !errors.Is(err, bookingConflictError) {
+		t.Errorf("Pending booking should block its time. Instead: %v", err)
+	}
+
+	t.Run("Approval", func(t *testing.T) {
+		if _, err := service.TransitionBooking("pending", BookingConfirmed, "a", ""); !errors.Is(err, bookingApproverError) {
+			t.Errorf("User other than the owner or admins should not approve. Instead: %v", err)
+		}
+		confirmed, err := service.TransitionBooking("pending", BookingConfirmed, "c", "Lab is free")
+		if err != nil || confirmed.State != BookingConfirmed || confirmed.Transitions[0].ActorId != "c" {
+			t.Fatalf("Group admin should approve. Instead: %+v %v", confirmed, err)
+		}
+
+		now = testTime.Add(3 * time.Hour)
+		if stored, _ := service.GetBookingById("pending"); stored.State != BookingConfirmed {
+			t.Errorf("Approved booking should not expire. Instead: %+v", stored)
+		}
+		now = testTime
+	})
+
+	t.Run("Rejection and cancellation", func(t *testing.T) {
+		service.CreateBooking(*hoursBooking("rejected", "z", 2, 3))
+		if rejected, err := service.TransitionBooking("rejected", BookingRejected, "b", "Maintenance"); err != nil || rejected.State != BookingRejected {
+			t.Errorf("Owner should reject. Instead: %+v %v", rejected, err)
+		}
+		service.CreateBooking(*hoursBooking("cancelled", "z", 2, 3))
+		if cancelled, err := service.TransitionBooking("cancelled", BookingCancelled, "a", ""); err != nil || cancelled.State != BookingCancelled {
+			t.Errorf("Anyone should cancel a pending booking. Instead: %+v %v", cancelled, err)
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		service.CreateBooking(*hoursBooking("expiring", "z", 4, 5))
+		now = testTime.Add(2 * time.Hour)
+
+		expired, _ := service.GetBookingById("expiring")
+		if expired.State != BookingRejected || len(expired.Transitions) != 1 || expired.Transitions[0].Reason != approvalExpiredReason ||
+			!expired.Transitions[0].At.Equal(expired.HoldUntil) {
+			t.Errorf("Booking should be rejected once its hold is over. Instead: %+v", expired)
+		}
+		if _, err := service.TransitionBooking("expiring", BookingConfirmed, "b", ""); !errors.Is(err, bookingTransitionError) {
+			t.Errorf("Expired booking should not be approved. Instead: %v", err)
+		}
+		if _, err := service.CreateBooking(*hoursBooking("instead", "z", 4, 5)); err != nil {
+			t.Errorf("Expired booking should free its time. Instead: %v", err)
+		}
+	})
+
+	t.Run("Restricted items", func(t *testing.T) {
+		moved, _ := service.GetBookingById("free")
+		moved.ItemId = "z"
+		if err := service.UpdateBooking(moved); !errors.Is(err, approvalRequiredError) {
+			t.Errorf("Confirmed booking should not move to an item requiring approval. Instead: %v", err)
+		}
+		if _, err := service.CreateRecurringBooking(newTestRecurringBooking(t, "series", "z", "FREQ=WEEKLY;COUNT=2")); !errors.Is(err, approvalRequiredError) {
+			t.Errorf("Recurring booking of an item requiring approval should not be created. Instead: %v", err)
+		}
+	})
+}
+
+func TestPostgresBookingServiceApprovals(t *testing.T) {
+	now := testTime
+	registry := newTestApprovalRegistry(t)
+	registry.Require("z", 2*time.Hour)
+	db, fake := newFakeDatabase(t)
+	service := NewPostgresBookingService(db, WithPostgresApprovals(registry), WithPostgresClock(func() time.Time { return now }))
+	period, _ := NewTimeRange(testTime, testTime.Add(time.Hour), TimeRangeIlEu)
+	row := func(state string, itemId string) []driver.Value {
+		return []driver.Value{"pending", "user", itemId, testTime, period.ToTstzrangeString(), "", state, "[]", testTime.Add(2 * time.Hour)}
+	}
+
+	fake.expect("WHERE state = 'pending' AND hold_until <= $1", testTime, approvalExpiredReason).willAffect(0)
+	insert := expectStoreBooking(fake, "INSERT INTO bookings").willAffect(1)
+	pending, err := service.CreateBooking(*hoursBooking("pending", "z", 0, 1))
+	if err != nil || pending.State != BookingPending || !pending.HoldUntil.Equal(testTime.Add(2*time.Hour)) {
+		t.Fatalf("Booking should be held pending approval. Instead: %+v %v", pending, err)
+	}
+	if len(insert.received) != 9 || insert.received[6] != "pending" || insert.received[8] != pending.HoldUntil {
+		t.Errorf("Booking should be stored pending with its hold. Instead: %v", insert.received)
+	}
+
+	t.Run("Approval", func(t *testing.T) {
+		fake.expect("BEGIN")
+		fake.expect("FOR UPDATE", "pending").willReturnRows(postgresBookingColumnNames, row("pending", "z"))
+		fake.expect("ROLLBACK")
+		if _, err := service.TransitionBooking("pending", BookingConfirmed, "a", ""); !errors.Is(err, bookingApproverError) {
+			t.Errorf("User other than the owner or admins should not approve. Instead: %v", err)
+		}
+
+		fake.expect("BEGIN")
+		fake.expect("FOR UPDATE", "pending").willReturnRows(postgresBookingColumnNames, row("pending", "z"))
+		fake.expect("UPDATE bookings SET state = $2, transitions = $3 WHERE id = $1").willAffect(1)
+		fake.expect("COMMIT")
+		if confirmed, err := service.TransitionBooking("pending", BookingConfirmed, "c", ""); err != nil || confirmed.State != BookingConfirmed {
+			t.Errorf("Group admin should approve. Instead: %+v %v", confirmed, err)
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		now = testTime.Add(2 * time.Hour)
+		defer func() { now = testTime }()
+
+		fake.expect("FROM bookings WHERE id = $1", "pending").willReturnRows(postgresBookingColumnNames, row("pending", "z"))
+		expired, err := service.GetBookingById("pending")
+		if err != nil || expired.State != BookingRejected || len(expired.Transitions) != 1 || expired.Transitions[0].Reason != approvalExpiredReason {
+			t.Errorf("Booking should be read rejected once its hold is over. Instead: %+v %v", expired, err)
+		}
+	})
+
+	t.Run("Restricted items", func(t *testing.T) {
+		fake.expect("WHERE state = 'pending' AND hold_until <= $1").willAffect(0)
+		fake.expect("SELECT state, item_id FROM bookings WHERE id = $1", "free").willReturnRows([]string{"state", "item_id"}, []driver.Value{"confirmed", "x"})
+		moved := hoursBooking("free", "z", 0, 1)
+		if err := service.UpdateBooking(moved); !errors.Is(err, approvalRequiredError) {
+			t.Errorf("Confirmed booking should not move to an item requiring approval. Instead: %v", err)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package bookk
+
+import (
+	"time"
+)
+
+// ItemBookingsReader reads the bookings of an item, as MemoryBookingService and PostgresBookingService do.
+type ItemBookingsReader interface {
+	GetBookingsByTimeRangeAndItemId(itemId string, timeRange TimeRange) ([]*Booking, error)
+}
+
+// SlotHoldsReader reads the holds of an item, as MemoryBookingService and PostgresBookingService do.
+type SlotHoldsReader interface {
+	GetSlotHoldsByTimeRangeAndItemId(itemId string, timeRange TimeRange) ([]*SlotHold, error)
+}
+
+// OpeningHoursSource tells when items may be booked.
+type OpeningHoursSource interface {
+	// OpeningHours returns the periods an item is open within a window
+	OpeningHours(itemId string, window TimeRange) (MultiTimeRange, error)
+}
+
+// AvailabilityOptions tells which free periods are useful for new bookings.
+type AvailabilityOptions struct {
+	MinSlot   time.Duration // Minimum duration of a free period. Shorter periods are dropped
+	Buffer    time.Duration // Time kept free before and after every booking
+	Alignment time.Duration // Free periods start and end at multiples of it, e.g. on the half hour. 24 hours align to calendar days
+}
+
+// AvailabilityCalculator finds when items are free to be booked.
+type AvailabilityCalculator struct {
+	bookings     ItemBookingsReader
+	openingHours OpeningHoursSource
+	checker      *ConflictChecker
+}
+
+// AvailabilityCalculatorOption configures an AvailabilityCalculator on creation.
+type AvailabilityCalculatorOption func(*AvailabilityCalculator)
+
+// WithAvailabilityConflictChecker sets how the time taken by bookings is built. It should be the ConflictChecker of the booking service. Defaults to DefaultConflictChecker.
+func WithAvailabilityConflictChecker(checker *ConflictChecker) AvailabilityCalculatorOption {
+	return func(c *AvailabilityCalculator) {
+		c.checker = checker
+	}
+}
+
+/*
+NewAvailabilityCalculator creates an AvailabilityCalculator.
+
+Parameters:
+  - bookings: Where the bookings of the items are read from
+  - openingHours: When items are open. If it is nil, items are always open
+  - options: Functional options such as WithAvailabilityConflictChecker
+
+Returns:
+  - A pointer to a new AvailabilityCalculator
+*/
+func NewAvailabilityCalculator(bookings ItemBookingsReader, openingHours OpeningHoursSource, options ...AvailabilityCalculatorOption) *AvailabilityCalculator {
+	c := &AvailabilityCalculator{bookings: bookings, openingHours: openingHours, checker: DefaultConflictChecker()}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+/*
+Availability finds the free periods of an item within a window.
+
+Free periods are the opening hours of the item within the window, minus the time of every
+active booking, as built by the ConflictChecker of the calculator and widened by the buffer.
+When the bookings are read from a SlotHoldsReader, the time of the holds not expired is taken
+out as the time of bookings. Then each period is shrunk to the alignment and dropped if it is
+shorter than the minimum slot.
+
+Parameters:
+  - itemId: The id of the item
+  - window: The time range to look for free periods in
+  - options: The buffer, alignment and minimum slot of the free periods. The zero value keeps
+    every free period as it is
+
+Returns:
+  - The free periods, normalized
+  - An error if the opening hours or bookings of the item cannot be read
+*/
+func (c *AvailabilityCalculator) Availability(itemId string, window TimeRange, options AvailabilityOptions) (MultiTimeRange, error) {
+	free := NewMultiTimeRange(&window)
+	if c.openingHours != nil {
+		open, err := c.openingHours.OpeningHours(itemId, window)
+		if err != nil {
+			return nil, err
+		}
+		free = free.Intersect(open.Normalize())
+	}
+
+	// Bookings just outside the window may still take part of it with their buffer
+	extended := *window.Extend(options.Buffer, options.Buffer)
+	bookings, err := c.bookings.GetBookingsByTimeRangeAndItemId(itemId, extended)
+	if err != nil {
+		return nil, err
+	}
+	if holdsReader, isHoldsReader := c.bookings.(SlotHoldsReader); isHoldsReader {
+		holds, err := holdsReader.GetSlotHoldsByTimeRangeAndItemId(itemId, extended)
+		if err != nil {
+			return nil, err
+		}
+		for _, hold := range holds {
+			bookings = append(bookings, hold.booking())
+		}
+	}
+	for _, booking := range bookings {
+		if !booking.State.Active() {
+			continue
+		}
+		taken, err := c.checker.BookingTimeRange(booking)
+		if err != nil {
+			return nil, err
+		}
+		free.Remove(*taken.Extend(options.Buffer, options.Buffer))
+	}
+
+	available := MultiTimeRange{}
+	for _, period := range free {
+		if options.Alignment > 0 {
+			period = alignTimeRange(period, options.Alignment)
+		}
+		if !period.IsEmpty() && period.Duration() >= options.MinSlot {
+			available = append(available, period)
+		}
+	}
+	return available, nil
+}
+
+// alignTimeRange shrinks a range to [lower, upper) where both bounds are multiples of the alignment. Non finite bounds are kept
+func alignTimeRange(t *TimeRange, alignment time.Duration) *TimeRange {
+	aligned := t.Clone()
+	if aligned.lowerLimit == TimeRangeFinite {
+		lower := truncateTo(t.lowerBound, alignment)
+		if lower.Before(t.lowerBound) || !t.lowerInclusion() {
+			lower = nextGranule(lower, alignment)
+		}
+		aligned.lowerBound = lower
+		aligned.boundsConf = boundsFromInclusion(true, aligned.upperInclusion())
+	}
+	if aligned.upperLimit == TimeRangeFinite {
+		aligned.upperBound = truncateTo(t.upperBound, alignment)
+		aligned.boundsConf = boundsFromInclusion(aligned.lowerInclusion(), false)
+	}
+	if aligned.lowerLimit == TimeRangeFinite && aligned.upperLimit == TimeRangeFinite && !aligned.lowerBound.Before(aligned.upperBound) {
+		return EmptyTimeRange()
+	}
+	return aligned
+}
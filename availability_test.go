@@ -0,0 +1,133 @@
+package bookk
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// openingHoursFunc is an OpeningHoursSource defined by a function
+type openingHoursFunc func(itemId string, window TimeRange) (MultiTimeRange, error)
+
+func (f openingHoursFunc) OpeningHours(itemId string, window TimeRange) (MultiTimeRange, error) {
+	return f(itemId, window)
+}
+
+func newTestAvailabilityCalculator(t *testing.T, openingHours OpeningHoursSource) *AvailabilityCalculator {
+	bookings := NewMemoryBookingService()
+	cancelled := *hoursBooking("cancelled", "item", 6, 7)
+	for _, booking := range []Booking{
+		*hoursBooking("", "item", 1, 2),
+		*hoursBooking("", "item", 4, 5),
+		*hoursBooking("", "item", 10.25, 11),
+		*hoursBooking("", "other", 0, 10),
+		cancelled,
+	} {
+		if _, err := bookings.CreateBooking(booking); err != nil {
+			t.Fatalf("Booking should be created. Instead: %v", err)
+		}
+	}
+	if _, err := bookings.TransitionBooking(cancelled.Id, BookingCancelled, "", ""); err != nil {
+		t.Fatalf("Booking should be cancelled. Instead: %v", err)
+	}
+	return NewAvailabilityCalculator(bookings, openingHours)
+}
+
+func TestAvailabilityCalculator(t *testing.T) {
+	calculator := newTestAvailabilityCalculator(t, nil)
+
+	type TestCase struct {
+		name     string
+		options  AvailabilityOptions
+		expected string
+	}
+
+	testCases := [5]TestCase{
+		{"Without options", AvailabilityOptions{}, "[0,1) [2,4) [5,10)"},
+		{"Buffer", AvailabilityOptions{Buffer: 30 * time.Minute}, "[0,0.5) [2.5,3.5) [5.5,9.75)"},
+		{"Minimum slot", AvailabilityOptions{Buffer: 30 * time.Minute, MinSlot: time.Hour}, "[2.5,3.5) [5.5,9.75)"},
+		{"Alignment", AvailabilityOptions{Buffer: 10 * time.Minute, Alignment: 30 * time.Minute}, "[0,0.5) [2.5,3.5) [5.5,10)"},
+		{"Alignment and minimum slot", AvailabilityOptions{Buffer: 10 * time.Minute, Alignment: time.Hour, MinSlot: time.Hour}, "[6,10)"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			free, err := calculator.Availability("item", *hoursRange(0, 10, TimeRangeIlEu), testCase.options)
+			if err != nil {
+				t.Fatalf("Availability should be found. Instead: %v", err)
+			}
+			if repr := multiHoursRepr(free); repr != testCase.expected {
+				t.Errorf("No expected availability:\nExpecting\t: %s\nRecieved\t: %s", testCase.expected, repr)
+			}
+		})
+	}
+}
+
+func TestAvailabilityCalculatorOpeningHours(t *testing.T) {
+	calculator := newTestAvailabilityCalculator(t, openingHoursFunc(func(itemId string, window TimeRange) (MultiTimeRange, error) {
+		if itemId != "item" {
+			return nil, errors.New("Item not found")
+		}
+		return NewMultiTimeRange(hoursRange(0.5, 3, TimeRangeIlEu), hoursRange(3.5, 8, TimeRangeIlEu), hoursRange(12, 14, TimeRangeIlEu)), nil
+	}))
+
+	free, err := calculator.Availability("item", *hoursRange(0, 10, TimeRangeIlEu), AvailabilityOptions{})
+	if err != nil {
+		t.Fatalf("Availability should be found. Instead: %v", err)
+	}
+	if expected := "[0.5,1) [2,3) [3.5,4) [5,8)"; multiHoursRepr(free) != expected {
+		t.Errorf("No expected availability:\nExpecting\t: %s\nRecieved\t: %s", expected, multiHoursRepr(free))
+	}
+
+	if _, err := calculator.Availability("missing", *hoursRange(0, 10, TimeRangeIlEu), AvailabilityOptions{}); err == nil {
+		t.Errorf("Availability should fail if the opening hours cannot be read")
+	}
+}
+
+func TestAvailabilityCalculatorSlotHolds(t *testing.T) {
+	bookings := NewMemoryBookingService()
+	if _, err := bookings.HoldSlot("user", "item", *hoursRange(2, 3, TimeRangeIlEu), time.Hour); err != nil {
+		t.Fatalf("Slot should be held. Instead: %v", err)
+	}
+
+	free, err := NewAvailabilityCalculator(bookings, nil).Availability("item", *hoursRange(0, 5, TimeRangeIlEu), AvailabilityOptions{})
+	if err != nil {
+		t.Fatalf("Availability should be found. Instead: %v", err)
+	}
+	if expected := "[0,2) [3,5)"; multiHoursRepr(free) != expected {
+		t.Errorf("Held time should not be free:\nExpecting\t: %s\nRecieved\t: %s", expected, multiHoursRepr(free))
+	}
+}
+
+func TestAvailabilityCalculatorConflictChecker(t *testing.T) {
+	bookings := NewMemoryBookingService()
+	if _, err := bookings.CreateBooking(*hoursBooking("", "item", 1, 2)); err != nil {
+		t.Fatalf("Booking should be created. Instead: %v", err)
+	}
+
+	if free, _ := NewAvailabilityCalculator(bookings, nil).Availability("item", *hoursRange(0, 3, TimeRangeIlEu), AvailabilityOptions{}); multiHoursRepr(free) != "[0,1) [2,3)" {
+		t.Errorf("No expected availability:\nExpecting\t: %s\nRecieved\t: %s", "[0,1) [2,3)", multiHoursRepr(free))
+	}
+
+	checker, _ := NewConflictChecker(TimeRangeBoundsInclusion)
+	free, err := NewAvailabilityCalculator(bookings, nil, WithAvailabilityConflictChecker(checker)).Availability("item", *hoursRange(0, 3, TimeRangeIlEu), AvailabilityOptions{})
+	if err != nil {
+		t.Fatalf("Availability should be found. Instead: %v", err)
+	}
+	if expected := "[0,1) (2,3)"; multiHoursRepr(free) != expected {
+		t.Errorf("Bookings should take their time as built by the checker:\nExpecting\t: %s\nRecieved\t: %s", expected, multiHoursRepr(free))
+	}
+}
+
+func TestAlignTimeRange(t *testing.T) {
+	if aligned := alignTimeRange(hoursRange(1, 3, TimeRangeElIu), time.Hour); !aligned.Equal(hoursRange(2, 3, TimeRangeIlEu)) {
+		t.Errorf("Excluded aligned lower bound should move to the next alignment. Instead: %s", aligned.Verbose())
+	}
+	if aligned := alignTimeRange(hoursRange(1.5, 2.5, TimeRangeIlEu), time.Hour); !aligned.IsEmpty() {
+		t.Errorf("Range without an aligned hour should be empty. Instead: %s", aligned.Verbose())
+	}
+	since, _ := NewTimeRangeFrom(testTime.Add(90*time.Minute), TimeRangeIlEu)
+	if aligned := alignTimeRange(since, 24*time.Hour); aligned.UpperLimit() != TimeRangeUnbounded || !aligned.LowerBound().Equal(testTime.Add(24*time.Hour)) {
+		t.Errorf("Day alignment should move to the next midnight. Instead: %s", aligned.Verbose())
+	}
+}
@@ -16,7 +16,9 @@ type BaseBooking struct {
 type Booking struct {
 	BaseBooking
 	Description string
-	Cancelled   bool
+	State       BookingState
+	Transitions []BookingTransition // Changes of State, from the oldest to the newest
+	HoldUntil   time.Time           // When a pending booking is rejected unless it was approved, zero if it waits forever
 }
 
 type IBookingService[T any] interface {
@@ -30,4 +32,5 @@ type IBookingService[T any] interface {
 	CreateBooking(booking Booking) (*Booking, error)
 	UpdateBooking(booking *Booking) error
 	DeleteBooking(bookingId string) error
+	TransitionBooking(bookingId string, state BookingState, actorId, reason string) (*Booking, error)
 }
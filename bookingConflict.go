@@ -0,0 +1,126 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	bookingConflictError  = errors.New("Booking conflicts with existing bookings")
+	bookingTimeRangeError = errors.New("Booking ends before it starts")
+)
+
+/*
+ConflictError describes a booking whose time collides with other bookings of the same item.
+
+It matches the generic booking conflict error with errors.Is, so callers may either inspect the
+colliding bookings with errors.As or just check whether there was a conflict.
+*/
+type ConflictError struct {
+	BookingId      string   // Id of the proposed booking, empty if it was not created yet
+	ItemId         string   // Item booked by all the colliding bookings
+	ConflictingIds []string // Ids of the existing bookings colliding with the proposed one
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("Booking %q conflicts with bookings [%s] of item %q", e.BookingId, strings.Join(e.ConflictingIds, ", "), e.ItemId)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == bookingConflictError
+}
+
+// ConflictChecker detects bookings of the same item whose time ranges share any instant.
+type ConflictChecker struct {
+	bounds TimeRangeBound
+}
+
+/*
+NewConflictChecker creates a ConflictChecker that builds the time range of every booking
+from its StartsAt and EndsAt with the given inclusion/exclusion configuration.
+
+With TimeRangeIlEu a booking may start at the very instant the previous one ends, while with
+TimeRangeBoundsInclusion both bookings would share that instant and collide.
+
+Parameters:
+  - bounds: Configuration for inclusion/exclusion of the booking bounds (use the predefined constants:
+    TimeRangeBoundsExclusion, TimeRangeBoundsInclusion, TimeRangeIlEu, or TimeRangeElIu)
+
+Returns:
+  - A pointer to a new ConflictChecker
+  - An error if the bounds configuration is invalid
+*/
+func NewConflictChecker(bounds TimeRangeBound) (*ConflictChecker, error) {
+	if bounds > TimeRangeBoundsInclusion {
+		return nil, timeRangeInitializationNotRecognizedBoundError
+	}
+	return &ConflictChecker{bounds: bounds}, nil
+}
+
+// DefaultConflictChecker creates a ConflictChecker where bookings are [StartsAt, EndsAt), so back to back bookings do not collide.
+func DefaultConflictChecker() *ConflictChecker {
+	return &ConflictChecker{bounds: TimeRangeIlEu}
+}
+
+/*
+BookingTimeRange builds the time range covered by a booking.
+
+Parameters:
+  - booking: The booking to read StartsAt and EndsAt from
+
+Returns:
+  - The TimeRange of the booking with the bounds configuration of the checker
+  - An error if the booking ends before it starts
+*/
+func (c *ConflictChecker) BookingTimeRange(booking *Booking) (*TimeRange, error) {
+	r, err := NewTimeRange(booking.StartsAt, booking.EndsAt, c.bounds)
+	if err != nil {
+		return nil, fmt.Errorf("%w: booking %q", bookingTimeRangeError, booking.Id)
+	}
+	return r, nil
+}
+
+/*
+Check looks for existing bookings colliding with a proposed booking.
+
+Only bookings of the same item are compared. Bookings whose state is not active, such as
+cancelled or completed ones, never collide, and an existing booking with the same Id as the
+proposed one is ignored, so a booking being updated does not collide with its own previous version.
+
+Parameters:
+  - proposed: The booking to be created or updated
+  - existing: The bookings already stored, which may belong to any item
+
+Returns:
+  - A *ConflictError listing the colliding bookings in the order given, or nil if there is none
+  - An error if any of the bookings compared ends before it starts
+*/
+func (c *ConflictChecker) Check(proposed *Booking, existing []*Booking) error {
+	if !proposed.State.Active() {
+		return nil
+	}
+	proposedRange, err := c.BookingTimeRange(proposed)
+	if err != nil {
+		return err
+	}
+
+	var conflictingIds []string
+	for _, booking := range existing {
+		if booking.ItemId != proposed.ItemId || !booking.State.Active() || (proposed.Id != "" && booking.Id == proposed.Id) {
+			continue
+		}
+		bookingRange, err := c.BookingTimeRange(booking)
+		if err != nil {
+			return err
+		}
+		if proposedRange.Relation(bookingRange).SharesInstants() {
+			conflictingIds = append(conflictingIds, booking.Id)
+		}
+	}
+
+	if conflictingIds == nil {
+		return nil
+	}
+	return &ConflictError{BookingId: proposed.Id, ItemId: proposed.ItemId, ConflictingIds: conflictingIds}
+}
@@ -0,0 +1,87 @@
+package bookk
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// hoursBooking builds a Booking relative to testTime using hours as unit
+func hoursBooking(id, itemId string, startsAt, endsAt float64) *Booking {
+	return &Booking{BaseBooking: BaseBooking{
+		Id:       id,
+		ItemId:   itemId,
+		StartsAt: testTime.Add(time.Duration(startsAt * float64(time.Hour))),
+		EndsAt:   testTime.Add(time.Duration(endsAt * float64(time.Hour))),
+	}}
+}
+
+func TestConflictCheckerCheck(t *testing.T) {
+	cancelled := hoursBooking("cancelled", "item", 1, 2)
+	cancelled.State = BookingCancelled
+	existing := []*Booking{
+		hoursBooking("a", "item", 0, 1),
+		hoursBooking("b", "item", 2, 3),
+		hoursBooking("c", "other", 1, 2),
+		cancelled,
+	}
+
+	type TestCase struct {
+		name     string
+		checker  *ConflictChecker
+		proposed *Booking
+		expected []string
+	}
+
+	inclusive, _ := NewConflictChecker(TimeRangeBoundsInclusion)
+	testCases := [6]TestCase{
+		{"Free slot", DefaultConflictChecker(), hoursBooking("", "item", 1, 2), nil},
+		{"Back to back", DefaultConflictChecker(), hoursBooking("", "item", 0.5, 2.5), []string{"a", "b"}},
+		{"Inclusive bounds", inclusive, hoursBooking("", "item", 1, 2), []string{"a", "b"}},
+		{"Other item", DefaultConflictChecker(), hoursBooking("", "other", 0, 1), nil},
+		{"Same booking", DefaultConflictChecker(), hoursBooking("a", "item", 0, 1.5), nil},
+		{"Updated booking", DefaultConflictChecker(), hoursBooking("a", "item", 0, 2.5), []string{"b"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.checker.Check(testCase.proposed, existing)
+			if testCase.expected == nil {
+				if err != nil {
+					t.Errorf("There should be no conflict. Instead: %v", err)
+				}
+				return
+			}
+
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) || !errors.Is(err, bookingConflictError) {
+				t.Fatalf("Error should be a conflict error. Instead: %v", err)
+			}
+			if !slices.Equal(conflict.ConflictingIds, testCase.expected) || conflict.ItemId != testCase.proposed.ItemId {
+				t.Errorf("No expected conflict:\nExpecting\t: %v\nRecieved\t: %v", testCase.expected, conflict.ConflictingIds)
+			}
+		})
+	}
+
+	t.Run("Cancelled proposal", func(t *testing.T) {
+		proposed := hoursBooking("", "item", 0, 3)
+		proposed.State = BookingCancelled
+		if err := DefaultConflictChecker().Check(proposed, existing); err != nil {
+			t.Errorf("Cancelled booking should never conflict. Instead: %v", err)
+		}
+	})
+
+	t.Run("Invalid booking", func(t *testing.T) {
+		err := DefaultConflictChecker().Check(hoursBooking("", "item", 2, 1), existing)
+		if !errors.Is(err, bookingTimeRangeError) {
+			t.Errorf("Booking ending before it starts should fail. Instead: %v", err)
+		}
+	})
+}
+
+func TestNewConflictChecker(t *testing.T) {
+	if _, err := NewConflictChecker(TimeRangeBoundsInclusion + 1); !errors.Is(err, timeRangeInitializationNotRecognizedBoundError) {
+		t.Errorf("Unrecognized bounds should fail. Instead: %v", err)
+	}
+}
@@ -0,0 +1,669 @@
+package bookk
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	icalendarError                  = errors.New("iCalendar not recognized")
+	icalendarRecurrenceSupportError = errors.New("Booking service does not keep recurring bookings")
+)
+
+const (
+	icalendarProductId  = "-//bookk//bookk//EN"
+	icalendarLineLength = 75 // Octets of a content line before it is folded
+	icalendarUserId     = "X-BOOKK-USER-ID"
+	icalendarItemId     = "X-BOOKK-ITEM-ID"
+	icalendarState      = "X-BOOKK-STATE"
+)
+
+/*
+ICalendar is a set of bookings read from or written to an iCalendar (.ics) file, as defined by
+RFC 5545.
+
+Every booking is a VEVENT whose UID is the booking id. Recurring bookings are a VEVENT with
+RRULE, RDATE and EXDATE, followed by a VEVENT with RECURRENCE-ID for every changed occurrence.
+The user and item of the bookings are kept in the X-BOOKK-USER-ID and X-BOOKK-ITEM-ID
+properties, which other calendars ignore.
+
+STATUS is TENTATIVE for pending bookings, CANCELLED for cancelled, rejected and no-show ones,
+and CONFIRMED otherwise. The exact state is kept in the X-BOOKK-STATE property, which is read
+before STATUS when both are found.
+*/
+type ICalendar struct {
+	Bookings          []*Booking
+	RecurringBookings []*RecurringBooking
+}
+
+// icalendarWriter writes content lines folded at 75 octets, remembering the first error
+type icalendarWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *icalendarWriter) line(line string) {
+	if w.err != nil {
+		return
+	}
+	// Folded lines start with a space, which takes an octet of the line
+	for length := icalendarLineLength; len(line) > length; length = icalendarLineLength - 1 {
+		// Folds never split a UTF-8 sequence
+		cut := length
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, w.err = w.w.WriteString(line[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		line = line[cut:]
+	}
+	_, w.err = w.w.WriteString(line + "\r\n")
+}
+
+var icalendarTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+var icalendarTextUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// icalendarLocation returns the location whose IANA name a time is written with as TZID, nil if
+// it is written in UTC, as the local location and zones without IANA name, such as a
+// time.FixedZone, could not be read back from their name
+func icalendarLocation(t time.Time) *time.Location {
+	location := t.Location()
+	if location == time.UTC || location == time.Local || location.String() == "" || location.String() == "UTC" {
+		return nil
+	} else if _, err := time.LoadLocation(location.String()); err != nil {
+		return nil
+	}
+	return location
+}
+
+// icalendarTime writes a date-time property in UTC, or with the TZID of its location so
+// recurring bookings keep their wall clock time, see icalendarLocation
+func icalendarTime(name string, times ...time.Time) string {
+	if len(times) == 0 {
+		return name + ":"
+	}
+	location := icalendarLocation(times[0])
+	values := make([]string, len(times))
+	if location == nil {
+		for i, t := range times {
+			values[i] = t.UTC().Format("20060102T150405Z")
+		}
+		return name + ":" + strings.Join(values, ",")
+	}
+	for i, t := range times {
+		values[i] = t.In(location).Format("20060102T150405")
+	}
+	return name + ";TZID=" + location.String() + ":" + strings.Join(values, ",")
+}
+
+// icalendarZones gathers the locations written as TZID, with the first and last times written in each
+type icalendarZones struct {
+	locations []*time.Location
+	spans     map[*time.Location][2]time.Time
+}
+
+func (z *icalendarZones) add(times ...time.Time) {
+	for _, t := range times {
+		location := icalendarLocation(t)
+		if location == nil {
+			continue
+		}
+		span, found := z.spans[location]
+		if !found {
+			z.locations = append(z.locations, location)
+			span = [2]time.Time{t, t}
+		}
+		if t.Before(span[0]) {
+			span[0] = t
+		}
+		if t.After(span[1]) {
+			span[1] = t
+		}
+		z.spans[location] = span
+	}
+}
+
+// timezone writes a VTIMEZONE with the observances of a location along the whole years from first to last
+func (w *icalendarWriter) timezone(location *time.Location, first, last time.Time) {
+	offset := func(seconds int) string {
+		return time.Date(2000, 1, 1, 0, 0, 0, 0, time.FixedZone("", seconds)).Format("-0700")
+	}
+
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + location.String())
+	end := time.Date(last.In(location).Year()+1, 1, 1, 0, 0, 0, 0, location)
+	for t := time.Date(first.In(location).Year(), 1, 1, 0, 0, 0, 0, location); t.Before(end); {
+		name, offsetTo := t.Zone()
+		start, next := t.ZoneBounds()
+		// The onset is written in the offset before it, and zones without transitions start at any time
+		offsetFrom, onset := offsetTo, "19700101T000000"
+		if !start.IsZero() {
+			_, offsetFrom = start.Add(-time.Nanosecond).Zone()
+			onset = start.In(time.FixedZone("", offsetFrom)).Format("20060102T150405")
+		}
+
+		observance := "STANDARD"
+		if t.IsDST() {
+			observance = "DAYLIGHT"
+		}
+		w.line("BEGIN:" + observance)
+		w.line("DTSTART:" + onset)
+		w.line("TZOFFSETFROM:" + offset(offsetFrom))
+		w.line("TZOFFSETTO:" + offset(offsetTo))
+		w.line("TZNAME:" + icalendarTextEscaper.Replace(name))
+		w.line("END:" + observance)
+
+		if next.IsZero() {
+			break
+		}
+		t = next
+	}
+	w.line("END:VTIMEZONE")
+}
+
+// event writes the properties shared by every VEVENT of a booking
+func (w *icalendarWriter) event(base BaseBooking, uid string, startsAt, endsAt time.Time, description string, state BookingState, properties func()) {
+	stamp := base.CreatedAt
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + icalendarTextEscaper.Replace(uid))
+	w.line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+	if !base.CreatedAt.IsZero() {
+		w.line("CREATED:" + base.CreatedAt.UTC().Format("20060102T150405Z"))
+	}
+	w.line(icalendarTime("DTSTART", startsAt))
+	w.line(icalendarTime("DTEND", endsAt))
+	if properties != nil {
+		properties()
+	}
+	if description != "" {
+		w.line("DESCRIPTION:" + icalendarTextEscaper.Replace(description))
+	}
+	switch state.orDefault() {
+	case BookingPending:
+		w.line("STATUS:TENTATIVE")
+	case BookingCancelled, BookingRejected, BookingNoShow:
+		w.line("STATUS:CANCELLED")
+	default:
+		w.line("STATUS:CONFIRMED")
+	}
+	w.line(icalendarState + ":" + string(state.orDefault()))
+	if base.UserId != "" {
+		w.line(icalendarUserId + ":" + icalendarTextEscaper.Replace(base.UserId))
+	}
+	if base.ItemId != "" {
+		w.line(icalendarItemId + ":" + icalendarTextEscaper.Replace(base.ItemId))
+	}
+	w.line("END:VEVENT")
+}
+
+/*
+Encode writes the calendar as an iCalendar file.
+
+Times are written with the TZID of their location when it is an IANA time zone, and in UTC
+otherwise, such as for a time.FixedZone. A VTIMEZONE is written for every TZID, with its
+observances along the years of the times written.
+
+The CreatedAt of every booking is written as CREATED, when it is not zero, and as DTSTAMP, which
+is the current time otherwise. Bookings sharing an id, as occurrences of a recurring booking
+listed as bookings do, get their start appended to the UID, so calendars do not take them for
+the same event.
+
+Parameters:
+  - w: Where the calendar is written
+
+Returns:
+  - An error if the calendar cannot be written
+*/
+func (c *ICalendar) Encode(w io.Writer) error {
+	writer := &icalendarWriter{w: bufio.NewWriter(w)}
+	writer.line("BEGIN:VCALENDAR")
+	writer.line("VERSION:2.0")
+	writer.line("PRODID:" + icalendarProductId)
+	writer.line("CALSCALE:GREGORIAN")
+
+	zones := &icalendarZones{spans: map[*time.Location][2]time.Time{}}
+	for _, booking := range c.Bookings {
+		zones.add(booking.StartsAt, booking.EndsAt)
+	}
+	for _, series := range c.RecurringBookings {
+		zones.add(series.StartsAt, series.EndsAt)
+		zones.add(series.RDates...)
+		zones.add(series.ExDates...)
+		for _, change := range series.Changes {
+			zones.add(change.StartsAt, change.EndsAt, change.RecurrenceId.In(series.StartsAt.Location()))
+		}
+	}
+	for _, location := range zones.locations {
+		span := zones.spans[location]
+		writer.timezone(location, span[0], span[1])
+	}
+
+	ids := map[string]int{}
+	for _, booking := range c.Bookings {
+		ids[booking.Id]++
+	}
+	for _, booking := range c.Bookings {
+		uid := booking.Id
+		if ids[uid] > 1 {
+			uid += "-" + booking.StartsAt.UTC().Format("20060102T150405Z")
+		}
+		writer.event(booking.BaseBooking, uid, booking.StartsAt, booking.EndsAt, booking.Description, booking.State, nil)
+	}
+
+	for _, series := range c.RecurringBookings {
+		writer.event(series.BaseBooking, series.Id, series.StartsAt, series.EndsAt, series.Description, occurrenceState(series.Cancelled), func() {
+			if series.Rule != nil {
+				writer.line("RRULE:" + series.Rule.String())
+			}
+			if len(series.RDates) > 0 {
+				writer.line(icalendarTime("RDATE", series.RDates...))
+			}
+			if len(series.ExDates) > 0 {
+				writer.line(icalendarTime("EXDATE", series.ExDates...))
+			}
+		})
+		for _, change := range series.Changes {
+			writer.event(series.BaseBooking, series.Id, change.StartsAt, change.EndsAt, change.Description, occurrenceState(change.Cancelled), func() {
+				writer.line(icalendarTime("RECURRENCE-ID", change.RecurrenceId.In(series.StartsAt.Location())))
+			})
+		}
+	}
+
+	writer.line("END:VCALENDAR")
+	if writer.err != nil {
+		return writer.err
+	}
+	return writer.w.Flush()
+}
+
+// icalendarProperty is a content line split into its name, parameters and value
+type icalendarProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICalendarProperty splits a content line such as DTSTART;TZID=Europe/Madrid:20250107T100000
+func parseICalendarProperty(line string) (icalendarProperty, error) {
+	property := icalendarProperty{params: map[string]string{}}
+	quoted, start := false, 0
+	var fields []string
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == ';':
+			fields = append(fields, line[start:i])
+			start = i + 1
+		case !quoted && r == ':':
+			fields = append(fields, line[start:i])
+			property.name = strings.ToUpper(fields[0])
+			for _, param := range fields[1:] {
+				name, value, _ := strings.Cut(param, "=")
+				property.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+			}
+			property.value = line[i+1:]
+			return property, nil
+		}
+	}
+	return property, errors.New("missing value")
+}
+
+// times reads a DATE or DATE-TIME property, with a comma separated list of values
+func (p icalendarProperty) times(location *time.Location) ([]time.Time, bool, error) {
+	if tzid, found := p.params["TZID"]; found {
+		var err error
+		if location, err = time.LoadLocation(tzid); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var times []time.Time
+	date := p.params["VALUE"] == "DATE"
+	for _, value := range strings.Split(p.value, ",") {
+		var t time.Time
+		var err error
+		switch {
+		case p.params["VALUE"] == "PERIOD":
+			return nil, false, errors.New("periods are not supported")
+		case strings.HasSuffix(value, "Z"):
+			t, err = time.Parse("20060102T150405Z", value)
+		case len(value) == len("20060102"):
+			t, err = time.ParseInLocation("20060102", value, location)
+			date = true
+		default:
+			t, err = time.ParseInLocation("20060102T150405", value, location)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		times = append(times, t)
+	}
+	return times, date, nil
+}
+
+// icalendarEvent holds the properties of a VEVENT being read
+type icalendarEvent struct {
+	booking      Booking
+	date         bool // DTSTART is a date, so the event lasts whole days
+	hasEnd       bool
+	duration     *iso8601Duration
+	rule         string
+	rdates       []time.Time
+	exdates      []time.Time
+	recurrenceId *time.Time
+	status       BookingState // State told by STATUS
+	state        BookingState // State told by X-BOOKK-STATE, which is more precise than STATUS
+}
+
+// set reads a property of the event
+func (e *icalendarEvent) set(property icalendarProperty, location *time.Location) error {
+	switch property.name {
+	case "UID":
+		e.booking.Id = icalendarTextUnescaper.Replace(property.value)
+	case "DESCRIPTION":
+		e.booking.Description = icalendarTextUnescaper.Replace(property.value)
+	case "STATUS":
+		switch strings.ToUpper(property.value) {
+		case "TENTATIVE":
+			e.status = BookingPending
+		case "CANCELLED":
+			e.status = BookingCancelled
+		default:
+			e.status = BookingConfirmed
+		}
+	case icalendarState:
+		state := BookingState(strings.ToLower(property.value))
+		if !state.Valid() {
+			return fmt.Errorf("%w: %q", bookingStateError, property.value)
+		}
+		e.state = state
+	case "CREATED":
+		created, err := time.Parse("20060102T150405Z", property.value)
+		if err != nil {
+			return err
+		}
+		e.booking.CreatedAt = created
+	case icalendarUserId:
+		e.booking.UserId = icalendarTextUnescaper.Replace(property.value)
+	case icalendarItemId:
+		e.booking.ItemId = icalendarTextUnescaper.Replace(property.value)
+	case "RRULE":
+		e.rule = property.value
+	case "DTSTART", "DTEND", "RDATE", "EXDATE", "RECURRENCE-ID":
+		times, date, err := property.times(location)
+		if err != nil {
+			return err
+		}
+		switch property.name {
+		case "DTSTART":
+			e.booking.StartsAt, e.date = times[0], date
+		case "DTEND":
+			e.booking.EndsAt, e.hasEnd = times[0], true
+		case "RDATE":
+			e.rdates = append(e.rdates, times...)
+		case "EXDATE":
+			e.exdates = append(e.exdates, times...)
+		case "RECURRENCE-ID":
+			e.recurrenceId = &times[0]
+		}
+	case "DURATION":
+		duration, err := parseISO8601Duration(strings.TrimPrefix(property.value, "+"))
+		if err != nil {
+			return err
+		}
+		e.duration = &duration
+	}
+	return nil
+}
+
+// end fills the end of an event from its DURATION. Without DTEND nor DURATION, an event lasts a day if it starts on a date and nothing otherwise
+func (e *icalendarEvent) end() {
+	if e.hasEnd {
+		return
+	} else if e.duration != nil {
+		e.booking.EndsAt = e.duration.addTo(e.booking.StartsAt, 1)
+		return
+	}
+	e.booking.EndsAt = e.booking.StartsAt
+	if e.date {
+		e.booking.EndsAt = e.booking.StartsAt.AddDate(0, 0, 1)
+	}
+}
+
+/*
+DecodeICalendar reads the bookings of an iCalendar (.ics) file.
+
+Every VEVENT becomes a booking, or a recurring booking if it has an RRULE or RDATE. VEVENTs with
+a RECURRENCE-ID become changes of the recurring booking with the same UID, or single bookings if
+there is none. Components other than VEVENT, such as VTIMEZONE or VALARM, are skipped, and time
+zones are read from their TZID as IANA names.
+
+Parameters:
+  - r: Where the calendar is read from
+  - location: The location of floating times and dates, which have no time zone
+
+Returns:
+  - A pointer to the ICalendar
+  - An error if the calendar cannot be read or an event is not valid
+*/
+func DecodeICalendar(r io.Reader, location *time.Location) (*ICalendar, error) {
+	// Unfold the content lines first
+	var lines []string
+	var numbers []int
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+		} else if line != "" {
+			lines, numbers = append(lines, line), append(numbers, number)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []*icalendarEvent
+	var event *icalendarEvent
+	var components []string
+	for i, line := range lines {
+		property, err := parseICalendarProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", icalendarError, numbers[i], err)
+		}
+
+		switch property.name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(property.value))
+			if len(components) == 2 && components[1] == "VEVENT" {
+				event = &icalendarEvent{}
+			}
+		case "END":
+			if len(components) == 0 || components[len(components)-1] != strings.ToUpper(property.value) {
+				return nil, fmt.Errorf("%w: line %d: unexpected END:%s", icalendarError, numbers[i], property.value)
+			}
+			if len(components) == 2 && event != nil {
+				if event.booking.Id == "" || event.booking.StartsAt.IsZero() {
+					return nil, fmt.Errorf("%w: line %d: event without UID or DTSTART", icalendarError, numbers[i])
+				}
+				event.end()
+				events, event = append(events, event), nil
+			}
+			components = components[:len(components)-1]
+		default:
+			// Properties of nested components, such as alarms, are not the ones of the event
+			if event != nil && len(components) == 2 {
+				if err := event.set(property, location); err != nil {
+					return nil, fmt.Errorf("%w: line %d: %s: %v", icalendarError, numbers[i], property.name, err)
+				}
+			}
+		}
+	}
+	if len(components) > 0 {
+		return nil, fmt.Errorf("%w: missing END:%s", icalendarError, components[len(components)-1])
+	}
+	return newICalendar(events)
+}
+
+// newICalendar groups the events read into bookings and recurring bookings
+func newICalendar(events []*icalendarEvent) (*ICalendar, error) {
+	calendar := &ICalendar{Bookings: []*Booking{}, RecurringBookings: []*RecurringBooking{}}
+	series := map[string]*RecurringBooking{}
+	for _, event := range events {
+		event.booking.State = event.status.orDefault()
+		if event.state != "" {
+			event.booking.State = event.state
+		}
+	}
+	for _, event := range events {
+		if (event.rule == "" && event.rdates == nil) || event.recurrenceId != nil {
+			continue
+		}
+		recurring := &RecurringBooking{
+			BaseBooking: event.booking.BaseBooking, Description: event.booking.Description, Cancelled: !event.booking.State.Active(),
+			RDates: event.rdates, ExDates: event.exdates,
+		}
+		if event.rule != "" {
+			rule, err := ParseRecurrenceRule(event.rule, event.booking.StartsAt.Location())
+			if err != nil {
+				return nil, fmt.Errorf("%w: event %q: %v", icalendarError, event.booking.Id, err)
+			}
+			recurring.Rule = rule
+		}
+		series[recurring.Id] = recurring
+		calendar.RecurringBookings = append(calendar.RecurringBookings, recurring)
+	}
+
+	for _, event := range events {
+		if recurring, found := series[event.booking.Id]; found && event.recurrenceId != nil {
+			recurring.Changes = append(recurring.Changes, Occurrence{
+				RecurrenceId: *event.recurrenceId, StartsAt: event.booking.StartsAt, EndsAt: event.booking.EndsAt,
+				Description: event.booking.Description, Cancelled: !event.booking.State.Active(),
+			})
+		} else if !found {
+			calendar.Bookings = append(calendar.Bookings, &event.booking)
+		}
+	}
+	return calendar, nil
+}
+
+// recurringBookingCreator is a booking service keeping recurring bookings, as MemoryBookingService does
+type recurringBookingCreator interface {
+	CreateRecurringBooking(booking RecurringBooking) (*RecurringBooking, error)
+}
+
+// ICalendarImport reports which bookings of a calendar were imported.
+type ICalendarImport struct {
+	Bookings          []*Booking          // Bookings created
+	RecurringBookings []*RecurringBooking // Recurring bookings created
+	Conflicts         []*ConflictError    // Bookings not created as they collide with existing ones
+	Errors            []error             // Bookings not created for any other reason
+}
+
+// icalendarImportReason is the reason of the transitions moving an imported booking to its state
+const icalendarImportReason = "Imported"
+
+// icalendarStatePath lists the states a new booking moves through to reach a state, starting with the one it is created in
+func icalendarStatePath(state BookingState) []BookingState {
+	switch state.orDefault() {
+	case BookingCheckedIn:
+		return []BookingState{BookingConfirmed, BookingCheckedIn}
+	case BookingCompleted:
+		return []BookingState{BookingConfirmed, BookingCheckedIn, BookingCompleted}
+	case BookingNoShow:
+		return []BookingState{BookingConfirmed, BookingNoShow}
+	case BookingCancelled:
+		return []BookingState{BookingConfirmed, BookingCancelled}
+	case BookingRejected:
+		return []BookingState{BookingPending, BookingRejected}
+	}
+	return []BookingState{state}
+}
+
+// importBooking creates a booking in its first state and moves it to its own along its lifecycle, removing it if it cannot
+func importBooking(service IBookingService[Booking], booking Booking) (*Booking, error) {
+	path := icalendarStatePath(booking.State)
+	booking.State = path[0]
+	created, err := service.CreateBooking(booking)
+	if err != nil {
+		return nil, err
+	}
+	id := created.Id
+	for _, state := range path[1:] {
+		if created, err = service.TransitionBooking(id, state, "", icalendarImportReason); err != nil {
+			if deleteErr := service.DeleteBooking(id); deleteErr != nil {
+				return nil, errors.Join(err, deleteErr)
+			}
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+/*
+ImportICalendar creates the bookings of a calendar in a booking service.
+
+Every booking is created on its own, so a booking that cannot be created does not prevent the
+others from being created. As bookings are only created pending or confirmed, bookings in other
+states are created in one of them and moved to their state with TransitionBooking, recording
+the transitions. If a booking cannot be moved to its state, it is removed, and the error removing
+it is reported along. Bookings no longer active are imported first, so they free their time
+before the others are created, but they still collide with the bookings of the service.
+
+The id of every booking is the UID of its event, and its user and item are the ones written by
+ICalendar.Encode; set them on the calendar before importing events from other calendars.
+
+Parameters:
+  - service: Where the bookings are created. Recurring bookings need a service keeping them, as
+    MemoryBookingService
+  - calendar: The bookings to create
+
+Returns:
+  - The bookings created, and the conflicts and errors of the ones that were not
+*/
+func ImportICalendar(service IBookingService[Booking], calendar *ICalendar) *ICalendarImport {
+	imported := &ICalendarImport{Bookings: []*Booking{}, RecurringBookings: []*RecurringBooking{}}
+	report := func(err error) {
+		var conflict *ConflictError
+		if errors.As(err, &conflict) {
+			imported.Conflicts = append(imported.Conflicts, conflict)
+		} else {
+			imported.Errors = append(imported.Errors, err)
+		}
+	}
+
+	for _, active := range [2]bool{false, true} {
+		for _, booking := range calendar.Bookings {
+			if booking.State.Active() != active {
+				continue
+			} else if created, err := importBooking(service, *booking); err != nil {
+				report(err)
+			} else {
+				imported.Bookings = append(imported.Bookings, created)
+			}
+		}
+	}
+
+	creator, supported := service.(recurringBookingCreator)
+	for _, series := range calendar.RecurringBookings {
+		if !supported {
+			report(fmt.Errorf("%w: %q", icalendarRecurrenceSupportError, series.Id))
+		} else if created, err := creator.CreateRecurringBooking(*cloneRecurringBooking(series)); err != nil {
+			report(err)
+		} else {
+			imported.RecurringBookings = append(imported.RecurringBookings, created)
+		}
+	}
+	return imported
+}
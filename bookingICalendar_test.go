@@ -0,0 +1,261 @@
+package bookk
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICalendarEncode(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	calendar := &ICalendar{Bookings: []*Booking{
+		{
+			BaseBooking: BaseBooking{Id: "first", UserId: "user", ItemId: "room", CreatedAt: createdAt,
+				StartsAt: time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC), EndsAt: time.Date(2025, 1, 7, 10, 0, 0, 0, time.UTC)},
+			Description: "Room 1, floor 2; bring\nthe keys",
+		},
+		{
+			BaseBooking: BaseBooking{Id: "second", CreatedAt: createdAt,
+				StartsAt: recurrenceTime(t, "2025-01-08 10:00"), EndsAt: recurrenceTime(t, "2025-01-08 11:00")},
+			Description: strings.Repeat("Meeting ñ ", 12),
+			State:       BookingNoShow,
+		},
+	}}
+
+	var output bytes.Buffer
+	if err := calendar.Encode(&output); err != nil {
+		t.Fatalf("Calendar should be encoded. Instead: %v", err)
+	}
+	encoded := output.String()
+
+	for _, line := range [12]string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//bookk//bookk//EN\r\n",
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Madrid\r\nBEGIN:STANDARD\r\nDTSTART:20241027T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nEND:STANDARD\r\n",
+		"BEGIN:DAYLIGHT\r\nDTSTART:20250330T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT\r\nBEGIN:STANDARD\r\nDTSTART:20251026T030000\r\n",
+		"UID:first\r\nDTSTAMP:20250101T120000Z\r\nCREATED:20250101T120000Z\r\nDTSTART:20250107T090000Z\r\nDTEND:20250107T100000Z\r\n",
+		`DESCRIPTION:Room 1\, floor 2\; bring\nthe keys` + "\r\n",
+		"STATUS:CONFIRMED\r\nX-BOOKK-STATE:confirmed\r\nX-BOOKK-USER-ID:user\r\nX-BOOKK-ITEM-ID:room\r\nEND:VEVENT\r\n",
+		"DTSTART;TZID=Europe/Madrid:20250108T100000\r\n",
+		"DTEND;TZID=Europe/Madrid:20250108T110000\r\n",
+		"Meeting \r\n ñ Meeting",
+		"STATUS:CANCELLED\r\nX-BOOKK-STATE:no-show\r\n",
+		"END:VCALENDAR\r\n",
+		"BEGIN:VEVENT\r\nUID:second\r\n",
+	} {
+		if !strings.Contains(encoded, line) {
+			t.Errorf("Calendar should contain %q. Instead:\n%s", line, encoded)
+		}
+	}
+	for _, line := range strings.Split(encoded, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Line should be folded at 75 octets: %q", line)
+		}
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		series := newTestRecurringBooking(t, "series", "room", "FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20250228T225959Z")
+		series.CreatedAt = createdAt
+		series.Description = "Weekly"
+		series.ExDates = []time.Time{recurrenceTime(t, "2025-01-09 10:00")}
+		series.RDates = []time.Time{recurrenceTime(t, "2025-01-11 10:00")}
+		series.Changes = []Occurrence{{
+			RecurrenceId: recurrenceTime(t, "2025-01-14 10:00"), StartsAt: recurrenceTime(t, "2025-01-14 12:00"),
+			EndsAt: recurrenceTime(t, "2025-01-14 13:00"), Description: "Moved", Cancelled: true,
+		}}
+		calendar.RecurringBookings = []*RecurringBooking{&series}
+
+		var output bytes.Buffer
+		calendar.Encode(&output)
+		decoded, err := DecodeICalendar(&output, time.UTC)
+		if err != nil {
+			t.Fatalf("Calendar should be decoded. Instead: %v", err)
+		}
+
+		if len(decoded.Bookings) != 2 {
+			t.Fatalf("No expected bookings. Instead: %v", decoded.Bookings)
+		}
+		for i, booking := range decoded.Bookings {
+			expected := calendar.Bookings[i]
+			if booking.Id != expected.Id || booking.UserId != expected.UserId || booking.ItemId != expected.ItemId ||
+				!booking.StartsAt.Equal(expected.StartsAt) || !booking.EndsAt.Equal(expected.EndsAt) ||
+				booking.Description != expected.Description || booking.State != expected.State.orDefault() ||
+				!booking.CreatedAt.Equal(expected.CreatedAt) {
+				t.Errorf("No expected booking:\nExpecting\t: %+v\nRecieved\t: %+v", expected, booking)
+			}
+		}
+
+		if len(decoded.RecurringBookings) != 1 {
+			t.Fatalf("No expected recurring bookings. Instead: %v", decoded.RecurringBookings)
+		}
+		window, _ := NewTimeRange(recurrenceTime(t, "2025-01-01 00:00"), recurrenceTime(t, "2025-04-01 00:00"), TimeRangeIlEu)
+		expected, _ := series.Occurrences(*window)
+		occurrences, _ := decoded.RecurringBookings[0].Occurrences(*window)
+		if occurrencesRepr(occurrences) != occurrencesRepr(expected) || occurrences[2].Description != "Moved" {
+			t.Errorf("No expected occurrences:\nExpecting\t: %s\nRecieved\t: %s", occurrencesRepr(expected), occurrencesRepr(occurrences))
+		}
+		if decoded := decoded.RecurringBookings[0]; !decoded.CreatedAt.Equal(series.CreatedAt) {
+			t.Errorf("No expected creation time:\nExpecting\t: %v\nRecieved\t: %v", series.CreatedAt, decoded.CreatedAt)
+		}
+		if decoded := decoded.RecurringBookings[0]; decoded.Rule.String() != series.Rule.String() || decoded.StartsAt.Location().String() != "Europe/Madrid" {
+			t.Errorf("No expected rule:\nExpecting\t: %s\nRecieved\t: %s in %s", series.Rule, decoded.Rule, decoded.StartsAt.Location())
+		}
+	})
+
+	t.Run("Fixed zone", func(t *testing.T) {
+		zone := time.FixedZone("", 2*60*60)
+		fixed := &ICalendar{Bookings: []*Booking{{BaseBooking: BaseBooking{Id: "fixed", CreatedAt: createdAt,
+			StartsAt: time.Date(2025, 1, 7, 9, 0, 0, 0, zone), EndsAt: time.Date(2025, 1, 7, 10, 0, 0, 0, zone)}}}}
+
+		var output bytes.Buffer
+		fixed.Encode(&output)
+		if encoded := output.String(); strings.Contains(encoded, "TZID") || !strings.Contains(encoded, "DTSTART:20250107T070000Z\r\n") {
+			t.Errorf("Time in a zone without IANA name should be written in UTC. Instead:\n%s", encoded)
+		}
+		decoded, err := DecodeICalendar(&output, time.UTC)
+		if err != nil || len(decoded.Bookings) != 1 || !decoded.Bookings[0].StartsAt.Equal(fixed.Bookings[0].StartsAt) ||
+			!decoded.Bookings[0].EndsAt.Equal(fixed.Bookings[0].EndsAt) {
+			t.Errorf("No expected booking:\nExpecting\t: %+v\nRecieved\t: %+v %v", fixed.Bookings, decoded, err)
+		}
+	})
+}
+
+func TestDecodeICalendar(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"PRODID:-//Google Inc//Google Calendar 70.9054//EN",
+		"VERSION:2.0",
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Madrid",
+		"BEGIN:STANDARD",
+		"DTSTART:19701025T030000",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20250110",
+		"UID:all-day@google.com",
+		"ATTENDEE;CN=\"Doe: John\";PARTSTAT=ACCEPTED:mailto:john@example.com",
+		"DESCRIPTION:Long descrip",
+		" tion folded",
+		"BEGIN:VALARM",
+		"DESCRIPTION:Reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DURATION:PT1H30M",
+		"DTSTART:20250111T100000",
+		"UID:floating",
+		"STATUS:TENTATIVE",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	madrid := recurrenceTime(t, "2025-01-01 00:00").Location()
+	calendar, err := DecodeICalendar(strings.NewReader(ics), madrid)
+	if err != nil {
+		t.Fatalf("Calendar should be decoded. Instead: %v", err)
+	}
+	if len(calendar.Bookings) != 2 || len(calendar.RecurringBookings) != 0 {
+		t.Fatalf("No expected bookings. Instead: %v", calendar.Bookings)
+	}
+
+	allDay, floating := calendar.Bookings[0], calendar.Bookings[1]
+	if allDay.Id != "all-day@google.com" || allDay.Description != "Long description folded" ||
+		!allDay.StartsAt.Equal(recurrenceTime(t, "2025-01-10 00:00")) || !allDay.EndsAt.Equal(recurrenceTime(t, "2025-01-11 00:00")) || allDay.State != BookingConfirmed {
+		t.Errorf("No expected all day booking. Instead: %+v", allDay)
+	}
+	if !floating.StartsAt.Equal(recurrenceTime(t, "2025-01-11 10:00")) || !floating.EndsAt.Equal(recurrenceTime(t, "2025-01-11 11:30")) || floating.State != BookingPending {
+		t.Errorf("No expected floating booking. Instead: %+v", floating)
+	}
+
+	for name, ics := range map[string]string{
+		"Missing END":   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:event\r\nDTSTART:20250111T100000Z\r\nEND:VEVENT",
+		"Missing UID":   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20250111T100000Z\r\nEND:VEVENT\r\nEND:VCALENDAR",
+		"Wrong DTSTART": "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:event\r\nDTSTART:2025-01-11\r\nEND:VEVENT\r\nEND:VCALENDAR",
+		"Unknown TZID":  "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:event\r\nDTSTART;TZID=Mars/Olympus:20250111T100000\r\nEND:VEVENT\r\nEND:VCALENDAR",
+		"Wrong RRULE":   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:event\r\nDTSTART:20250111T100000Z\r\nRRULE:FREQ=SECONDLY\r\nEND:VEVENT\r\nEND:VCALENDAR",
+		"No value":      "BEGIN:VCALENDAR\r\nSUMMARY\r\nEND:VCALENDAR",
+		"Unknown state": "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:event\r\nDTSTART:20250111T100000Z\r\nX-BOOKK-STATE:lost\r\nEND:VEVENT\r\nEND:VCALENDAR",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeICalendar(strings.NewReader(ics), time.UTC); !errors.Is(err, icalendarError) {
+				t.Errorf("Calendar should not be decoded. Instead: %v", err)
+			}
+		})
+	}
+}
+
+// failingTransitionService is a booking service failing to move bookings and to remove them
+type failingTransitionService struct {
+	*MemoryBookingService
+}
+
+func (s *failingTransitionService) TransitionBooking(bookingId string, state BookingState, actorId, reason string) (*Booking, error) {
+	return nil, bookingTransitionError
+}
+
+func (s *failingTransitionService) DeleteBooking(bookingId string) error {
+	return bookingNotFoundError
+}
+
+func TestImportICalendar(t *testing.T) {
+	booking := func(id, startsAt, endsAt string) *Booking {
+		return &Booking{BaseBooking: BaseBooking{Id: id, UserId: "user", ItemId: "room", StartsAt: recurrenceTime(t, startsAt), EndsAt: recurrenceTime(t, endsAt)}}
+	}
+	series := newTestRecurringBooking(t, "series", "room", "FREQ=WEEKLY;COUNT=3")
+	withdrawn := booking("withdrawn", "2025-01-06 10:00", "2025-01-06 11:00")
+	withdrawn.State = BookingCancelled
+	calendar := &ICalendar{
+		Bookings: []*Booking{
+			booking("imported", "2025-01-06 10:00", "2025-01-06 11:00"),
+			withdrawn,
+			booking("colliding", "2025-01-06 10:30", "2025-01-06 11:30"),
+			booking("existing", "2025-01-08 10:00", "2025-01-08 11:00"),
+		},
+		RecurringBookings: []*RecurringBooking{&series},
+	}
+
+	t.Run("Memory", func(t *testing.T) {
+		service := NewMemoryBookingService()
+		service.CreateBooking(*booking("existing", "2025-02-01 10:00", "2025-02-01 11:00"))
+
+		imported := ImportICalendar(service, calendar)
+		if ids := bookingIds(imported.Bookings); !slices.Equal(ids, []string{"withdrawn", "imported"}) || len(imported.RecurringBookings) != 1 {
+			t.Errorf("No expected imported bookings. Instead: %v and %v", ids, imported.RecurringBookings)
+		}
+		if cancelled := imported.Bookings[0]; cancelled.State != BookingCancelled || len(cancelled.Transitions) != 1 || cancelled.Transitions[0].Reason != icalendarImportReason {
+			t.Errorf("Cancelled booking should be imported moving to its state. Instead: %+v", cancelled)
+		}
+		if len(imported.Conflicts) != 1 || imported.Conflicts[0].BookingId != "colliding" || !slices.Equal(imported.Conflicts[0].ConflictingIds, []string{"imported"}) {
+			t.Errorf("No expected conflicts. Instead: %v", imported.Conflicts)
+		}
+		if len(imported.Errors) != 1 || !errors.Is(imported.Errors[0], bookingAlreadyExistsError) {
+			t.Errorf("No expected errors. Instead: %v", imported.Errors)
+		}
+	})
+
+	t.Run("Failed transition", func(t *testing.T) {
+		service := &failingTransitionService{MemoryBookingService: NewMemoryBookingService()}
+		imported := ImportICalendar(service, &ICalendar{Bookings: []*Booking{withdrawn}})
+		if len(imported.Bookings) != 0 || len(imported.Errors) != 1 || !errors.Is(imported.Errors[0], bookingTransitionError) ||
+			!errors.Is(imported.Errors[0], bookingNotFoundError) {
+			t.Errorf("Errors moving and removing the booking should be reported. Instead: %v", imported.Errors)
+		}
+	})
+
+	t.Run("Without recurring bookings", func(t *testing.T) {
+		store, err := OpenFileStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("File store should be opened. Instead: %v", err)
+		}
+		defer store.Close()
+
+		// Only the methods of IBookingService are left, so recurring bookings are not kept
+		imported := ImportICalendar(struct{ IBookingService[Booking] }{store.Bookings()}, calendar)
+		if len(imported.Bookings) != 3 || len(imported.Errors) != 1 || !errors.Is(imported.Errors[0], icalendarRecurrenceSupportError) {
+			t.Errorf("Recurring bookings should not be imported. Instead: %v", imported.Errors)
+		}
+	})
+}
@@ -0,0 +1,605 @@
+package bookk
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	bookingNotFoundError         = errors.New("Booking not found")
+	bookingAlreadyExistsError    = errors.New("Booking already exists")
+	bookingGroupsNotDefinedError = errors.New("Group members are not defined for the booking service")
+)
+
+// GroupUsersFunc returns the ids of the users belonging to a group.
+type GroupUsersFunc func(groupId string) ([]string, error)
+
+// MemoryBookingServiceOption configures a MemoryBookingService on creation.
+type MemoryBookingServiceOption func(*MemoryBookingService)
+
+// WithGroupUsers sets where the members of a group are read from. Without it, group queries fail.
+func WithGroupUsers(groupUsers GroupUsersFunc) MemoryBookingServiceOption {
+	return func(s *MemoryBookingService) {
+		s.groupUsers = groupUsers
+	}
+}
+
+// WithConflictChecker sets how bookings of the same item are checked for collisions. Defaults to DefaultConflictChecker.
+func WithConflictChecker(checker *ConflictChecker) MemoryBookingServiceOption {
+	return func(s *MemoryBookingService) {
+		s.checker = checker
+	}
+}
+
+// WithClock sets the clock used to fill the CreatedAt of new bookings. Defaults to time.Now.
+func WithClock(now func() time.Time) MemoryBookingServiceOption {
+	return func(s *MemoryBookingService) {
+		s.now = now
+	}
+}
+
+// WithOpeningHours sets when items may be booked. Without it, items may be booked at any time.
+func WithOpeningHours(openingHours OpeningHoursSource) MemoryBookingServiceOption {
+	return func(s *MemoryBookingService) {
+		s.openingHours = openingHours
+	}
+}
+
+// WithApprovals sets which items need their bookings approved, see CreateBooking. Without it, no booking needs approval.
+func WithApprovals(approvals ApprovalSource) MemoryBookingServiceOption {
+	return func(s *MemoryBookingService) {
+		s.approvals = approvals
+	}
+}
+
+// WithRecurrenceHorizon sets how far from now recurring bookings repeating forever are checked for collisions. Defaults to two years.
+func WithRecurrenceHorizon(horizon time.Duration) MemoryBookingServiceOption {
+	return func(s *MemoryBookingService) {
+		s.horizon = horizon
+	}
+}
+
+/*
+MemoryBookingService is an IBookingService keeping bookings in memory.
+
+Bookings of the same item may not collide, as told by its ConflictChecker, nor be outside the
+opening hours of the item, if given with WithOpeningHours. Every booking
+read or written is copied, so callers never share a booking with the service. Bookings of a
+group are the bookings made by the users of the group.
+
+Bookings of items requiring approval, if given with WithApprovals, are pending until their
+owner or an approver of the item confirms them, and are rejected when their hold is over, as
+read by the clock of the service.
+
+Recurring bookings are kept along single bookings, and their occurrences are checked in the
+same way, see CreateRecurringBooking. The time of items may also be held for a while before it
+is booked, see HoldSlot; holds are only kept by this service, and never persisted.
+
+A MemoryBookingService is safe for concurrent use.
+*/
+type MemoryBookingService struct {
+	mu           sync.RWMutex
+	bookings     map[string]*Booking
+	items        map[string]*TimeRangeTree[string] // Time ranges of the bookings of every item
+	groupUsers   GroupUsersFunc
+	checker      *ConflictChecker
+	openingHours OpeningHoursSource
+	approvals    ApprovalSource
+	holds        map[string]time.Time // HoldUntil of the pending bookings which expire
+	slotHolds    map[string]*SlotHold // Holds of the time of items by their token
+	now          func() time.Time
+	recurring    map[string]*RecurringBooking
+	horizon      time.Duration
+}
+
+var _ IBookingService[Booking] = (*MemoryBookingService)(nil)
+
+/*
+NewMemoryBookingService creates an empty MemoryBookingService.
+
+Parameters:
+  - options: Functional options such as WithGroupUsers, WithConflictChecker or WithClock
+
+Returns:
+  - A pointer to a new MemoryBookingService
+*/
+func NewMemoryBookingService(options ...MemoryBookingServiceOption) *MemoryBookingService {
+	s := &MemoryBookingService{
+		bookings:  map[string]*Booking{},
+		items:     map[string]*TimeRangeTree[string]{},
+		holds:     map[string]time.Time{},
+		slotHolds: map[string]*SlotHold{},
+		checker:   DefaultConflictChecker(),
+		now:       time.Now,
+		recurring: map[string]*RecurringBooking{},
+		horizon:   defaultRecurrenceHorizon,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// newRandomId generates a random id for a stored entity
+func newRandomId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func cloneBooking(booking *Booking) *Booking {
+	clone := *booking
+	clone.Transitions = slices.Clone(booking.Transitions)
+	return &clone
+}
+
+// read copies a stored booking as it is by the clock of the service, so holds over are seen rejected before they are expired
+func (s *MemoryBookingService) read(booking *Booking) *Booking {
+	clone := cloneBooking(booking)
+	expireHold(clone, s.now())
+	return clone
+}
+
+// expireHolds rejects the pending bookings whose hold is over. It must be called holding the lock
+func (s *MemoryBookingService) expireHolds() {
+	now := s.now()
+	for id, until := range s.holds {
+		if !until.After(now) {
+			expireHold(s.bookings[id], now)
+			delete(s.holds, id)
+		}
+	}
+}
+
+// compareLastBookings sorts bookings from the newest to the oldest StartsAt
+func compareLastBookings(a, b *Booking) int {
+	return cmp.Or(b.StartsAt.Compare(a.StartsAt), cmp.Compare(a.Id, b.Id))
+}
+
+// compareBookings sorts bookings from the oldest to the newest StartsAt
+func compareBookings(a, b *Booking) int {
+	return cmp.Or(a.StartsAt.Compare(b.StartsAt), cmp.Compare(a.Id, b.Id))
+}
+
+// dayTimeRange returns the calendar day of date in its location as [midnight, next midnight)
+func dayTimeRange(date time.Time) TimeRange {
+	day := truncateTo(date, 24*time.Hour)
+	r, _ := NewTimeRange(day, nextGranule(day, 24*time.Hour), TimeRangeIlEu)
+	return *r
+}
+
+/*
+GetBookingById returns a booking.
+
+Parameters:
+  - bookingId: The id of the booking
+
+Returns:
+  - A copy of the booking
+  - An error if there is no booking with the id
+*/
+func (s *MemoryBookingService) GetBookingById(bookingId string) (*Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	booking, found := s.bookings[bookingId]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", bookingNotFoundError, bookingId)
+	}
+	return s.read(booking), nil
+}
+
+// userBookings copies the bookings of a set of users accepted by the filter, sorted by the comparison
+func (s *MemoryBookingService) userBookings(userIds []string, filter func(*Booking) bool, compare func(a, b *Booking) int) []*Booking {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bookings := []*Booking{}
+	for _, booking := range s.bookings {
+		if slices.Contains(userIds, booking.UserId) && filter(booking) {
+			bookings = append(bookings, s.read(booking))
+		}
+	}
+	slices.SortFunc(bookings, compare)
+	return bookings
+}
+
+// lastBookings returns the latest bookings of a set of users
+func (s *MemoryBookingService) lastBookings(userIds []string, limit int) []*Booking {
+	bookings := s.userBookings(userIds, func(*Booking) bool { return true }, compareLastBookings)
+	if limit > 0 && len(bookings) > limit {
+		bookings = bookings[:limit]
+	}
+	return bookings
+}
+
+// bookingsInTimeRange returns the bookings of a set of users sharing any instant with a time range
+func (s *MemoryBookingService) bookingsInTimeRange(userIds []string, timeRange TimeRange) []*Booking {
+	return s.userBookings(userIds, func(booking *Booking) bool {
+		// Stored bookings were checked to start before they end
+		r, _ := s.checker.BookingTimeRange(booking)
+		return r.Relation(&timeRange).SharesInstants()
+	}, compareBookings)
+}
+
+// groupUserIds returns the ids of the users of a group
+func (s *MemoryBookingService) groupUserIds(groupId string) ([]string, error) {
+	if s.groupUsers == nil {
+		return nil, bookingGroupsNotDefinedError
+	}
+	return s.groupUsers(groupId)
+}
+
+/*
+GetLastBookingsByUserId returns the latest bookings of a user, from the newest to the oldest StartsAt.
+
+Parameters:
+  - userId: The id of the user
+  - limit: The maximum number of bookings returned. Every booking is returned if it is 0 or less
+
+Returns:
+  - Copies of the bookings
+  - An error, never returned by this implementation
+*/
+func (s *MemoryBookingService) GetLastBookingsByUserId(userId string, limit int) ([]*Booking, error) {
+	return s.lastBookings([]string{userId}, limit), nil
+}
+
+/*
+GetLastBookingsByGroupId returns the latest bookings of the users of a group, from the newest to the oldest StartsAt.
+
+Parameters:
+  - groupId: The id of the group
+  - limit: The maximum number of bookings returned. Every booking is returned if it is 0 or less
+
+Returns:
+  - Copies of the bookings
+  - An error if the users of the group cannot be read
+*/
+func (s *MemoryBookingService) GetLastBookingsByGroupId(groupId string, limit int) ([]*Booking, error) {
+	userIds, err := s.groupUserIds(groupId)
+	if err != nil {
+		return nil, err
+	}
+	return s.lastBookings(userIds, limit), nil
+}
+
+/*
+GetBookingsByTimeRangeAndUserId returns the bookings of a user sharing any instant with a time range.
+
+Parameters:
+  - userId: The id of the user
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error, never returned by this implementation
+*/
+func (s *MemoryBookingService) GetBookingsByTimeRangeAndUserId(userId string, timeRange TimeRange) ([]*Booking, error) {
+	return s.bookingsInTimeRange([]string{userId}, timeRange), nil
+}
+
+/*
+GetBookingsByTimeRangeAndGroupId returns the bookings of the users of a group sharing any instant with a time range.
+
+Parameters:
+  - groupId: The id of the group
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error if the users of the group cannot be read
+*/
+func (s *MemoryBookingService) GetBookingsByTimeRangeAndGroupId(groupId string, timeRange TimeRange) ([]*Booking, error) {
+	userIds, err := s.groupUserIds(groupId)
+	if err != nil {
+		return nil, err
+	}
+	return s.bookingsInTimeRange(userIds, timeRange), nil
+}
+
+/*
+GetBookingsByDateAndUserId returns the bookings of a user sharing any instant with a calendar day.
+
+Parameters:
+  - userId: The id of the user
+  - date: Any time of the day, which is taken in the location of date
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error, never returned by this implementation
+*/
+func (s *MemoryBookingService) GetBookingsByDateAndUserId(userId string, date time.Time) ([]*Booking, error) {
+	return s.bookingsInTimeRange([]string{userId}, dayTimeRange(date)), nil
+}
+
+/*
+GetBookingsByDateAndGroupId returns the bookings of the users of a group sharing any instant with a calendar day.
+
+Parameters:
+  - groupId: The id of the group
+  - date: Any time of the day, which is taken in the location of date
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error if the users of the group cannot be read
+*/
+func (s *MemoryBookingService) GetBookingsByDateAndGroupId(groupId string, date time.Time) ([]*Booking, error) {
+	userIds, err := s.groupUserIds(groupId)
+	if err != nil {
+		return nil, err
+	}
+	return s.bookingsInTimeRange(userIds, dayTimeRange(date)), nil
+}
+
+/*
+GetBookingsByTimeRangeAndItemId returns the bookings of an item sharing any instant with a time range.
+
+Occurrences of recurring bookings are returned too, as bookings with the id of their series,
+see RecurringBooking.OccurrenceBooking, so they are taken into account when finding when the
+item is free.
+
+Parameters:
+  - itemId: The id of the item
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error if the time range has no upper bound and a recurring booking of the item repeats forever
+*/
+func (s *MemoryBookingService) GetBookingsByTimeRangeAndItemId(itemId string, timeRange TimeRange) ([]*Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bookings, err := s.seriesBookings(itemId, timeRange, "")
+	if err != nil {
+		return nil, err
+	}
+	if tree, found := s.items[itemId]; found {
+		for _, id := range tree.Overlapping(&timeRange) {
+			bookings = append(bookings, s.read(s.bookings[id]))
+		}
+	}
+	slices.SortFunc(bookings, compareBookings)
+	return bookings, nil
+}
+
+// checkBooking looks for bookings, occurrences and holds of the same item colliding with a booking, but the hold with a token, and checks it is within opening hours. It must be called holding the lock
+func (s *MemoryBookingService) checkBooking(booking *Booking, exceptHold string) (*TimeRange, error) {
+	r, err := s.checker.BookingTimeRange(booking)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOpeningHours(s.openingHours, booking, r); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.seriesBookings(booking.ItemId, *r, "")
+	if err != nil {
+		return nil, err
+	}
+	if tree, found := s.items[booking.ItemId]; found {
+		for _, id := range tree.Overlapping(r) {
+			candidates = append(candidates, s.bookings[id])
+		}
+	}
+	candidates = append(candidates, s.slotHoldBookings(booking.ItemId, r, exceptHold)...)
+	return r, s.checker.Check(booking, candidates)
+}
+
+// store saves a booking and indexes its time range. It must be called holding the lock
+func (s *MemoryBookingService) store(booking *Booking, r *TimeRange) {
+	if previous, found := s.bookings[booking.Id]; found {
+		s.items[previous.ItemId].Delete(previous.Id)
+	}
+	s.bookings[booking.Id] = booking
+	if booking.State == BookingPending && !booking.HoldUntil.IsZero() {
+		s.holds[booking.Id] = booking.HoldUntil
+	} else {
+		delete(s.holds, booking.Id)
+	}
+
+	tree, found := s.items[booking.ItemId]
+	if !found {
+		tree = NewTimeRangeTree[string]()
+		s.items[booking.ItemId] = tree
+	}
+	tree.Insert(booking.Id, r)
+}
+
+/*
+CreateBooking stores a new booking.
+
+An id is generated when the booking has none, and CreatedAt is set by the clock of the service
+when it is zero. Bookings are only created pending or confirmed, and without State they are
+confirmed; other states are reached with TransitionBooking.
+
+Active bookings of items requiring approval are pending instead, and block their time until
+their hold is over, when they are rejected unless they were approved with TransitionBooking.
+
+Parameters:
+  - booking: The booking to create
+
+Returns:
+  - A copy of the created booking
+  - A *ConflictError if the booking collides with other bookings or holds of the item, or
+    another error if the booking ends before it starts, is outside opening hours, its state is
+    not initial or its id is already taken
+*/
+func (s *MemoryBookingService) CreateBooking(booking Booking) (*Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireHolds()
+	return s.create(booking, "")
+}
+
+// create stores a new booking as CreateBooking does, ignoring the hold with a token. It must be called holding the lock
+func (s *MemoryBookingService) create(booking Booking, exceptHold string) (*Booking, error) {
+	if booking.Id == "" {
+		booking.Id = newRandomId()
+	} else if s.idTaken(booking.Id) {
+		return nil, fmt.Errorf("%w: %q", bookingAlreadyExistsError, booking.Id)
+	}
+	if booking.CreatedAt.IsZero() {
+		booking.CreatedAt = s.now()
+	}
+	if err := checkBookingState(&booking); err != nil {
+		return nil, err
+	}
+	booking.State = booking.State.orDefault()
+	if err := holdForApproval(s.approvals, &booking, s.now()); err != nil {
+		return nil, err
+	}
+
+	r, err := s.checkBooking(&booking, exceptHold)
+	if err != nil {
+		return nil, err
+	}
+	s.store(&booking, r)
+	return cloneBooking(&booking), nil
+}
+
+/*
+UpdateBooking replaces a stored booking. The CreatedAt of the stored booking is kept when the
+given one is zero, and its State, Transitions and HoldUntil are always kept, as they only change
+with TransitionBooking.
+
+Parameters:
+  - booking: The booking to update, identified by its id
+
+Returns:
+  - A *ConflictError if the booking collides with other bookings or holds of the item, or
+    another error if the booking does not exist, ends before it starts, is outside opening
+    hours or is active and moves to another item requiring approval
+*/
+func (s *MemoryBookingService) UpdateBooking(booking *Booking) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireHolds()
+
+	previous, found := s.bookings[booking.Id]
+	if !found {
+		return fmt.Errorf("%w: %q", bookingNotFoundError, booking.Id)
+	}
+	updated := cloneBooking(booking)
+	if updated.CreatedAt.IsZero() {
+		updated.CreatedAt = previous.CreatedAt
+	}
+	updated.State, updated.Transitions, updated.HoldUntil = previous.State, slices.Clone(previous.Transitions), previous.HoldUntil
+	if updated.ItemId != previous.ItemId && updated.State.Active() {
+		if err := checkApprovalRequired(s.approvals, updated.ItemId); err != nil {
+			return err
+		}
+	}
+
+	r, err := s.checkBooking(updated, "")
+	if err != nil {
+		return err
+	}
+	s.store(updated, r)
+	return nil
+}
+
+/*
+TransitionBooking moves a booking to another state, recording when, by whom and why it moved.
+
+Only the moves allowed by BookingState.CanTransitionTo are made. Bookings are never checked for
+collisions again, as a booking that stops being active never becomes active again.
+
+With WithApprovals, pending bookings are only confirmed or rejected by an approver of their
+item, while anyone may cancel them. Bookings whose hold is over are rejected already.
+
+Parameters:
+  - bookingId: The id of the booking
+  - state: The state to move to
+  - actorId: The id of the user changing the state, empty if it is changed by the system
+  - reason: Why the state changes, empty if there is none
+
+Returns:
+  - A copy of the booking in its new state
+  - An error if there is no booking with the id, it may not move to the state or the actor may
+    not approve it
+*/
+func (s *MemoryBookingService) TransitionBooking(bookingId string, state BookingState, actorId, reason string) (*Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireHolds()
+
+	previous, found := s.bookings[bookingId]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", bookingNotFoundError, bookingId)
+	}
+	booking := cloneBooking(previous)
+	if s.approvals != nil && booking.State == BookingPending && (state == BookingConfirmed || state == BookingRejected) {
+		if err := checkApprover(s.approvals, booking, actorId); err != nil {
+			return nil, err
+		}
+	}
+	if err := booking.Transition(state, s.now(), actorId, reason); err != nil {
+		return nil, err
+	}
+	// Stored bookings were checked to start before they end
+	r, _ := s.checker.BookingTimeRange(booking)
+	s.store(booking, r)
+	return cloneBooking(booking), nil
+}
+
+/*
+DeleteBooking removes a booking.
+
+Parameters:
+  - bookingId: The id of the booking
+
+Returns:
+  - An error if there is no booking with the id
+*/
+func (s *MemoryBookingService) DeleteBooking(bookingId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	booking, found := s.bookings[bookingId]
+	if !found {
+		return fmt.Errorf("%w: %q", bookingNotFoundError, bookingId)
+	}
+	delete(s.bookings, bookingId)
+	delete(s.holds, bookingId)
+	s.items[booking.ItemId].Delete(bookingId)
+	return nil
+}
+
+// restoreBooking stores a booking as it is, without checking conflicts
+func (s *MemoryBookingService) restoreBooking(booking *Booking) error {
+	r, err := s.checker.BookingTimeRange(booking)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(cloneBooking(booking), r)
+	return nil
+}
+
+// replace takes the single and recurring bookings of another service, which must not be used anymore. Slot holds are kept, as they are not stored
+func (s *MemoryBookingService) replace(other *MemoryBookingService) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bookings, s.items, s.holds, s.recurring = other.bookings, other.items, other.holds, other.recurring
+}
+
+// snapshot copies every booking, sorted by id
+func (s *MemoryBookingService) snapshot() []*Booking {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bookings := []*Booking{}
+	for _, id := range slices.Sorted(maps.Keys(s.bookings)) {
+		bookings = append(bookings, cloneBooking(s.bookings[id]))
+	}
+	return bookings
+}
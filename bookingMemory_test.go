@@ -0,0 +1,222 @@
+package bookk
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func bookingIds(bookings []*Booking) []string {
+	ids := make([]string, len(bookings))
+	for i, booking := range bookings {
+		ids[i] = booking.Id
+	}
+	return ids
+}
+
+// newTestBookingService creates a service with bookings a, b and c of user-1 and d of user-2
+func newTestBookingService(t *testing.T, options ...MemoryBookingServiceOption) *MemoryBookingService {
+	service := NewMemoryBookingService(options...)
+	bookings := []*Booking{
+		hoursBooking("a", "item-1", 0, 1),
+		hoursBooking("b", "item-1", 1, 2),
+		hoursBooking("c", "item-2", 0.5, 1.5),
+		hoursBooking("d", "item-2", 2, 3),
+	}
+	bookings[3].UserId = "user-2"
+	for _, booking := range bookings[:3] {
+		booking.UserId = "user-1"
+	}
+	for _, booking := range bookings {
+		if _, err := service.CreateBooking(*booking); err != nil {
+			t.Fatalf("Booking %q should be created. Instead: %v", booking.Id, err)
+		}
+	}
+	return service
+}
+
+func TestMemoryBookingServiceCreateBooking(t *testing.T) {
+	createdAt := testTime.Add(-time.Hour)
+	service := newTestBookingService(t, WithClock(func() time.Time { return createdAt }))
+
+	t.Run("Generates id", func(t *testing.T) {
+		created, err := service.CreateBooking(*hoursBooking("", "item-1", 2, 3))
+		if err != nil {
+			t.Fatalf("Booking should be created. Instead: %v", err)
+		}
+		if created.Id == "" || !created.CreatedAt.Equal(createdAt) {
+			t.Errorf("Booking should have an id and creation time. Instead: %q %s", created.Id, created.CreatedAt)
+		}
+
+		created.Description = "changed"
+		if stored, _ := service.GetBookingById(created.Id); stored.Description != "" {
+			t.Errorf("Returned booking should be a copy")
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := service.CreateBooking(*hoursBooking("", "item-1", 0.5, 1.5))
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || !slices.Equal(conflict.ConflictingIds, []string{"a", "b"}) {
+			t.Errorf("Booking should conflict with a and b. Instead: %v", err)
+		}
+	})
+
+	t.Run("Existing id", func(t *testing.T) {
+		if _, err := service.CreateBooking(*hoursBooking("a", "item-3", 0, 1)); !errors.Is(err, bookingAlreadyExistsError) {
+			t.Errorf("Booking with an existing id should fail. Instead: %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := service.CreateBooking(*hoursBooking("", "item-3", 1, 0)); !errors.Is(err, bookingTimeRangeError) {
+			t.Errorf("Booking ending before it starts should fail. Instead: %v", err)
+		}
+	})
+}
+
+func TestMemoryBookingServiceUpdateBooking(t *testing.T) {
+	service := newTestBookingService(t)
+
+	t.Run("Overlapping itself", func(t *testing.T) {
+		updated := hoursBooking("a", "item-1", -1, 0.5)
+		if err := service.UpdateBooking(updated); err != nil {
+			t.Fatalf("Booking should be updated. Instead: %v", err)
+		}
+		if stored, _ := service.GetBookingById("a"); !stored.StartsAt.Equal(updated.StartsAt) {
+			t.Errorf("Booking should be stored updated. Instead: %s", stored.StartsAt)
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		if err := service.UpdateBooking(hoursBooking("a", "item-1", 0, 1.5)); !errors.Is(err, bookingConflictError) {
+			t.Errorf("Booking should conflict with b. Instead: %v", err)
+		}
+	})
+
+	t.Run("Moves item", func(t *testing.T) {
+		if err := service.UpdateBooking(hoursBooking("a", "item-3", 0, 1.5)); err != nil {
+			t.Fatalf("Booking should be updated. Instead: %v", err)
+		}
+		window := *hoursRange(-2, 3, TimeRangeIlEu)
+		if bookings, _ := service.GetBookingsByTimeRangeAndItemId("item-1", window); !slices.Equal(bookingIds(bookings), []string{"b"}) {
+			t.Errorf("Booking should not be in its previous item. Instead: %v", bookingIds(bookings))
+		}
+		if bookings, _ := service.GetBookingsByTimeRangeAndItemId("item-3", window); !slices.Equal(bookingIds(bookings), []string{"a"}) {
+			t.Errorf("Booking should be in its new item. Instead: %v", bookingIds(bookings))
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		if err := service.UpdateBooking(hoursBooking("z", "item-1", 5, 6)); !errors.Is(err, bookingNotFoundError) {
+			t.Errorf("Missing booking should fail. Instead: %v", err)
+		}
+	})
+}
+
+func TestMemoryBookingServiceDeleteBooking(t *testing.T) {
+	service := newTestBookingService(t)
+	if err := service.DeleteBooking("a"); err != nil {
+		t.Fatalf("Booking should be deleted. Instead: %v", err)
+	}
+	if _, err := service.GetBookingById("a"); !errors.Is(err, bookingNotFoundError) {
+		t.Errorf("Deleted booking should not be found. Instead: %v", err)
+	}
+	if err := service.DeleteBooking("a"); !errors.Is(err, bookingNotFoundError) {
+		t.Errorf("Deleting a missing booking should fail. Instead: %v", err)
+	}
+	if _, err := service.CreateBooking(*hoursBooking("", "item-1", 0, 1)); err != nil {
+		t.Errorf("Time of the deleted booking should be free. Instead: %v", err)
+	}
+}
+
+func TestMemoryBookingServiceQueries(t *testing.T) {
+	groups := map[string][]string{"group": {"user-1", "user-2"}}
+	service := newTestBookingService(t, WithGroupUsers(func(groupId string) ([]string, error) {
+		return groups[groupId], nil
+	}))
+
+	type TestCase struct {
+		name     string
+		query    func() ([]*Booking, error)
+		expected []string
+	}
+
+	testCases := [7]TestCase{
+		{"Last by user", func() ([]*Booking, error) { return service.GetLastBookingsByUserId("user-1", 0) }, []string{"b", "c", "a"}},
+		{"Last by user limited", func() ([]*Booking, error) { return service.GetLastBookingsByUserId("user-1", 2) }, []string{"b", "c"}},
+		{"Last by group", func() ([]*Booking, error) { return service.GetLastBookingsByGroupId("group", 2) }, []string{"d", "b"}},
+		{"Time range by user", func() ([]*Booking, error) {
+			return service.GetBookingsByTimeRangeAndUserId("user-1", *hoursRange(1, 2, TimeRangeIlEu))
+		}, []string{"c", "b"}},
+		{"Time range by group", func() ([]*Booking, error) {
+			return service.GetBookingsByTimeRangeAndGroupId("group", *hoursRange(1.5, 2, TimeRangeBoundsInclusion))
+		}, []string{"b", "d"}},
+		{"Time range by item", func() ([]*Booking, error) {
+			return service.GetBookingsByTimeRangeAndItemId("item-1", *hoursRange(0.5, 1, TimeRangeBoundsInclusion))
+		}, []string{"a", "b"}},
+		{"Missing user", func() ([]*Booking, error) { return service.GetLastBookingsByUserId("user-3", 0) }, []string{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			bookings, err := testCase.query()
+			if err != nil {
+				t.Fatalf("Query should not fail. Instead: %v", err)
+			}
+			if ids := bookingIds(bookings); !slices.Equal(ids, testCase.expected) {
+				t.Errorf("No expected bookings:\nExpecting\t: %v\nRecieved\t: %v", testCase.expected, ids)
+			}
+		})
+	}
+
+	t.Run("Date", func(t *testing.T) {
+		location := time.FixedZone("UTC+10", 10*60*60)
+		service := NewMemoryBookingService()
+		for i, startsAt := range []time.Time{
+			time.Date(2025, 1, 1, 23, 0, 0, 0, location),
+			time.Date(2025, 1, 2, 13, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 2, 14, 0, 0, 0, time.UTC),
+		} {
+			booking := Booking{BaseBooking: BaseBooking{Id: string(rune('a' + i)), UserId: "user", ItemId: "item", StartsAt: startsAt, EndsAt: startsAt.Add(time.Hour)}}
+			service.CreateBooking(booking)
+		}
+
+		bookings, _ := service.GetBookingsByDateAndUserId("user", time.Date(2025, 1, 2, 12, 0, 0, 0, location))
+		if ids := bookingIds(bookings); !slices.Equal(ids, []string{"b"}) {
+			t.Errorf("Only the bookings of January 2nd in UTC+10 should be found. Instead: %v", ids)
+		}
+	})
+
+	t.Run("Group without members", func(t *testing.T) {
+		if _, err := NewMemoryBookingService().GetBookingsByDateAndGroupId("group", testTime); !errors.Is(err, bookingGroupsNotDefinedError) {
+			t.Errorf("Group query without members should fail. Instead: %v", err)
+		}
+	})
+}
+
+func TestMemoryBookingServiceConcurrency(t *testing.T) {
+	service := NewMemoryBookingService()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.CreateBooking(*hoursBooking("", "item", 0, 1)); err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+			service.GetLastBookingsByUserId("", 0)
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Errorf("Only 1 of the colliding bookings should be created. Instead: %d", created)
+	}
+}
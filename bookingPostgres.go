@@ -0,0 +1,676 @@
+package bookk
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	postgresExclusionViolation = "23P01" // SQLSTATE of a row violating an EXCLUDE constraint
+	postgresUniqueViolation    = "23505" // SQLSTATE of a row violating a UNIQUE or PRIMARY KEY constraint
+)
+
+// bookingPeriodEmptyError is returned for bookings lasting no time, which a tstzrange stores as empty, losing their times
+var bookingPeriodEmptyError = errors.New("Booking must last some time")
+
+const postgresBookingColumns = "id, user_id, item_id, created_at, period, description, state, transitions, hold_until"
+
+// postgresActiveStates is the SQL condition of the bookings whose state is active, as told by BookingState.Active
+const postgresActiveStates = "state IN ('pending', 'confirmed', 'checked-in')"
+
+// postgresError is implemented by the errors of PostgreSQL drivers, such as pgx and lib/pq
+type postgresError interface {
+	error
+	SQLState() string
+}
+
+// isPostgresError tells whether an error comes from PostgreSQL with a SQLSTATE code
+func isPostgresError(err error, code string) bool {
+	var pgErr postgresError
+	return errors.As(err, &pgErr) && pgErr.SQLState() == code
+}
+
+// PostgresBookingServiceOption configures a PostgresBookingService on creation.
+type PostgresBookingServiceOption func(*PostgresBookingService)
+
+// WithPostgresOpeningHours sets when items may be booked. Without it, items may be booked at any time.
+func WithPostgresOpeningHours(openingHours OpeningHoursSource) PostgresBookingServiceOption {
+	return func(s *PostgresBookingService) {
+		s.openingHours = openingHours
+	}
+}
+
+// WithPostgresApprovals sets which items need their bookings approved, see CreateBooking. Without it, no booking needs approval.
+func WithPostgresApprovals(approvals ApprovalSource) PostgresBookingServiceOption {
+	return func(s *PostgresBookingService) {
+		s.approvals = approvals
+	}
+}
+
+// WithPostgresClock sets the clock used to fill the CreatedAt of new bookings and to expire holds. Defaults to time.Now.
+func WithPostgresClock(now func() time.Time) PostgresBookingServiceOption {
+	return func(s *PostgresBookingService) {
+		s.now = now
+	}
+}
+
+/*
+PostgresBookingService is an IBookingService storing bookings in PostgreSQL through database/sql.
+
+Bookings are stored in the bookings table, where the time of every booking is a tstzrange
+[StartsAt, EndsAt). Double bookings are prevented by the database with an exclusion constraint,
+so concurrent services never store colliding bookings:
+
+	CREATE TABLE bookings (
+		id          text PRIMARY KEY,
+		user_id     text NOT NULL,
+		item_id     text NOT NULL,
+		created_at  timestamptz NOT NULL,
+		period      tstzrange NOT NULL,
+		description text NOT NULL DEFAULT '',
+		state       text NOT NULL DEFAULT 'confirmed',
+		transitions jsonb NOT NULL DEFAULT '[]',
+		hold_until  timestamptz,
+		EXCLUDE USING gist (item_id WITH =, period WITH &&) WHERE (state IN ('pending', 'confirmed', 'checked-in'))
+	);
+
+Only active bookings are kept from colliding, as BookingState.Active tells, and the transitions
+of every booking are kept as a JSON array.
+
+The users of a group are read from the group_users table, with group_id and user_id columns,
+and groups from the groups table, with an id column. Every table is created by the migrations
+applied by Migrator.
+
+Active bookings may not be outside the opening hours of their item, if given with
+WithPostgresOpeningHours. Unlike collisions, opening hours are checked by the service rather
+than by the database.
+
+Bookings of items requiring approval, if given with WithPostgresApprovals, are pending until
+their owner or an approver of the item confirms them, until hold_until. Bookings whose hold is
+over are read as rejected, and are stored rejected before every change, so they free their time;
+every service sharing the database should be given the same ApprovalSource.
+
+Slot holds are stored in the slot_holds table, see HoldSlot. As they are not covered by the
+exclusion constraint, the changes of the bookings and holds of an item are made one after
+another, locking the item with a transaction-level advisory lock.
+
+A PostgresBookingService is safe for concurrent use.
+*/
+type PostgresBookingService struct {
+	db           *sql.DB
+	openingHours OpeningHoursSource
+	approvals    ApprovalSource
+	now          func() time.Time
+}
+
+var (
+	_ IBookingService[Booking] = (*PostgresBookingService)(nil)
+	_ SlotHoldsReader          = (*PostgresBookingService)(nil)
+)
+
+/*
+NewPostgresBookingService creates a PostgresBookingService.
+
+Parameters:
+  - db: The connection pool to a PostgreSQL database with the tables of the service
+  - options: Functional options such as WithPostgresOpeningHours or WithPostgresApprovals
+
+Returns:
+  - A pointer to a new PostgresBookingService
+*/
+func NewPostgresBookingService(db *sql.DB, options ...PostgresBookingServiceOption) *PostgresBookingService {
+	s := &PostgresBookingService{db: db, now: time.Now}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// scanPostgresBooking reads a booking from a row with the columns of postgresBookingColumns
+func scanPostgresBooking(row interface{ Scan(...any) error }) (*Booking, error) {
+	var booking Booking
+	var period TimeRange
+	var transitions []byte
+	var holdUntil sql.NullTime
+	err := row.Scan(&booking.Id, &booking.UserId, &booking.ItemId, &booking.CreatedAt, &period, &booking.Description, &booking.State, &transitions, &holdUntil)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(transitions, &booking.Transitions); err != nil {
+		return nil, err
+	}
+	booking.StartsAt, booking.EndsAt, booking.HoldUntil = period.LowerBound(), period.UpperBound(), holdUntil.Time
+	return &booking, nil
+}
+
+// postgresHoldUntil returns the value stored in the hold_until column of a booking, which is NULL if it is not held
+func postgresHoldUntil(booking *Booking) any {
+	if booking.HoldUntil.IsZero() {
+		return nil
+	}
+	return booking.HoldUntil
+}
+
+// expireHolds stores the pending bookings whose hold is over as rejected, as expireHold does, so they free their time
+func (s *PostgresBookingService) expireHolds() error {
+	if s.approvals == nil {
+		return nil
+	}
+	_, err := s.db.Exec(
+		"UPDATE bookings SET state = 'rejected', transitions = transitions || jsonb_build_array(jsonb_build_object("+
+			"'From', 'pending', 'To', 'rejected', 'At', hold_until, 'ActorId', '', 'Reason', $2::text)) "+
+			"WHERE state = 'pending' AND hold_until <= $1",
+		s.now(), approvalExpiredReason,
+	)
+	return err
+}
+
+// postgresTransitions encodes the transitions of a booking as stored in the transitions column
+func postgresTransitions(transitions []BookingTransition) (string, error) {
+	if transitions == nil {
+		transitions = []BookingTransition{}
+	}
+	encoded, err := json.Marshal(transitions)
+	return string(encoded), err
+}
+
+// queryBookings runs a query selecting bookings by a condition
+func (s *PostgresBookingService) queryBookings(condition string, args ...any) ([]*Booking, error) {
+	rows, err := s.db.Query("SELECT "+postgresBookingColumns+" FROM bookings WHERE "+condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []*Booking{}
+	for rows.Next() {
+		booking, err := scanPostgresBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		expireHold(booking, s.now())
+		bookings = append(bookings, booking)
+	}
+	return bookings, rows.Err()
+}
+
+// checkGroup returns an error if a group does not exist
+func (s *PostgresBookingService) checkGroup(groupId string) error {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM groups WHERE id = $1)", groupId).Scan(&exists); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("%w: %q", groupNotFoundError, groupId)
+	}
+	return nil
+}
+
+// postgresLimit returns the LIMIT of a query, where NULL means no limit
+func postgresLimit(limit int) any {
+	if limit <= 0 {
+		return nil
+	}
+	return limit
+}
+
+// bookingPeriod returns the tstzrange stored for a booking, which must not be empty to be read back
+func bookingPeriod(booking *Booking) (*TimeRange, error) {
+	period, err := NewTimeRange(booking.StartsAt, booking.EndsAt, TimeRangeIlEu)
+	if err != nil {
+		return nil, fmt.Errorf("%w: booking %q", bookingTimeRangeError, booking.Id)
+	} else if period.IsEmpty() {
+		return nil, fmt.Errorf("%w: booking %q", bookingPeriodEmptyError, booking.Id)
+	}
+	return period, nil
+}
+
+/*
+GetBookingById returns a booking.
+
+Parameters:
+  - bookingId: The id of the booking
+
+Returns:
+  - The booking
+  - An error if there is no booking with the id or the query fails
+*/
+func (s *PostgresBookingService) GetBookingById(bookingId string) (*Booking, error) {
+	row := s.db.QueryRow("SELECT "+postgresBookingColumns+" FROM bookings WHERE id = $1", bookingId)
+	booking, err := scanPostgresBooking(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %q", bookingNotFoundError, bookingId)
+	} else if err != nil {
+		return nil, err
+	}
+	expireHold(booking, s.now())
+	return booking, nil
+}
+
+/*
+GetLastBookingsByUserId returns the latest bookings of a user, from the newest to the oldest StartsAt.
+
+Parameters:
+  - userId: The id of the user
+  - limit: The maximum number of bookings returned. Every booking is returned if it is 0 or less
+
+Returns:
+  - The bookings
+  - An error if the query fails
+*/
+func (s *PostgresBookingService) GetLastBookingsByUserId(userId string, limit int) ([]*Booking, error) {
+	return s.queryBookings("user_id = $1 ORDER BY lower(period) DESC, id LIMIT $2", userId, postgresLimit(limit))
+}
+
+/*
+GetLastBookingsByGroupId returns the latest bookings of the users of a group, from the newest to the oldest StartsAt.
+
+Parameters:
+  - groupId: The id of the group
+  - limit: The maximum number of bookings returned. Every booking is returned if it is 0 or less
+
+Returns:
+  - The bookings
+  - An error if there is no group with the id or the query fails
+*/
+func (s *PostgresBookingService) GetLastBookingsByGroupId(groupId string, limit int) ([]*Booking, error) {
+	if err := s.checkGroup(groupId); err != nil {
+		return nil, err
+	}
+	return s.queryBookings(
+		"user_id IN (SELECT user_id FROM group_users WHERE group_id = $1) ORDER BY lower(period) DESC, id LIMIT $2",
+		groupId, postgresLimit(limit),
+	)
+}
+
+/*
+GetBookingsByTimeRangeAndUserId returns the bookings of a user sharing any instant with a time range.
+
+Parameters:
+  - userId: The id of the user
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - The bookings, from the oldest to the newest StartsAt
+  - An error if the query fails
+*/
+func (s *PostgresBookingService) GetBookingsByTimeRangeAndUserId(userId string, timeRange TimeRange) ([]*Booking, error) {
+	return s.queryBookings("user_id = $1 AND period && $2::tstzrange ORDER BY lower(period), id", userId, timeRange)
+}
+
+/*
+GetBookingsByTimeRangeAndGroupId returns the bookings of the users of a group sharing any instant with a time range.
+
+Parameters:
+  - groupId: The id of the group
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - The bookings, from the oldest to the newest StartsAt
+  - An error if there is no group with the id or the query fails
+*/
+func (s *PostgresBookingService) GetBookingsByTimeRangeAndGroupId(groupId string, timeRange TimeRange) ([]*Booking, error) {
+	if err := s.checkGroup(groupId); err != nil {
+		return nil, err
+	}
+	return s.queryBookings(
+		"user_id IN (SELECT user_id FROM group_users WHERE group_id = $1) AND period && $2::tstzrange ORDER BY lower(period), id",
+		groupId, timeRange,
+	)
+}
+
+/*
+GetBookingsByDateAndUserId returns the bookings of a user sharing any instant with a calendar day.
+
+Parameters:
+  - userId: The id of the user
+  - date: Any time of the day, which is taken in the location of date
+
+Returns:
+  - The bookings, from the oldest to the newest StartsAt
+  - An error if the query fails
+*/
+func (s *PostgresBookingService) GetBookingsByDateAndUserId(userId string, date time.Time) ([]*Booking, error) {
+	return s.GetBookingsByTimeRangeAndUserId(userId, dayTimeRange(date))
+}
+
+/*
+GetBookingsByDateAndGroupId returns the bookings of the users of a group sharing any instant with a calendar day.
+
+Parameters:
+  - groupId: The id of the group
+  - date: Any time of the day, which is taken in the location of date
+
+Returns:
+  - The bookings, from the oldest to the newest StartsAt
+  - An error if there is no group with the id or the query fails
+*/
+func (s *PostgresBookingService) GetBookingsByDateAndGroupId(groupId string, date time.Time) ([]*Booking, error) {
+	return s.GetBookingsByTimeRangeAndGroupId(groupId, dayTimeRange(date))
+}
+
+/*
+GetBookingsByTimeRangeAndItemId returns the bookings of an item sharing any instant with a time range.
+
+Parameters:
+  - itemId: The id of the item
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - The bookings, from the oldest to the newest StartsAt
+  - An error if the query fails
+*/
+func (s *PostgresBookingService) GetBookingsByTimeRangeAndItemId(itemId string, timeRange TimeRange) ([]*Booking, error) {
+	return s.queryBookings("item_id = $1 AND period && $2::tstzrange ORDER BY lower(period), id", itemId, timeRange)
+}
+
+// postgresConflictRetries is how many times a statement rejected by the exclusion constraint is run again when the bookings it collided with are gone
+const postgresConflictRetries = 3
+
+// conflictError builds the error of a booking rejected by the exclusion constraint, reading and locking the bookings it collides with within a transaction
+func conflictError(tx *sql.Tx, booking *Booking, period *TimeRange) (*ConflictError, error) {
+	rows, err := tx.Query(
+		"SELECT id FROM bookings WHERE item_id = $1 AND period && $2::tstzrange AND "+postgresActiveStates+" AND id <> $3 ORDER BY lower(period), id FOR SHARE",
+		booking.ItemId, period, booking.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conflict := &ConflictError{BookingId: booking.Id, ItemId: booking.ItemId}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		conflict.ConflictingIds = append(conflict.ConflictingIds, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return conflict, nil
+}
+
+/*
+storeBooking runs a statement storing a booking within a transaction.
+
+The item is locked first, as slot holds are not covered by the exclusion constraint, and an
+active booking colliding with holds of the item not expired is not stored. If the exclusion
+constraint rejects the statement, the bookings it collides with are read in the same transaction
+and locked, so they are the ones it collided with. If they are gone already, as they were deleted
+or moved meanwhile, the statement is run again, up to postgresConflictRetries times.
+*/
+func (s *PostgresBookingService) storeBooking(tx *sql.Tx, booking *Booking, period *TimeRange, query string, args ...any) (sql.Result, error) {
+	if err := lockPostgresItem(tx, booking.ItemId); err != nil {
+		return nil, err
+	}
+	holdIds, err := s.collidingSlotHoldIds(tx, booking, period)
+	if err != nil {
+		return nil, err
+	} else if len(holdIds) > 0 {
+		conflict, err := conflictError(tx, booking, period)
+		if err != nil {
+			return nil, err
+		}
+		conflict.ConflictingIds = append(conflict.ConflictingIds, holdIds...)
+		return nil, conflict
+	}
+
+	// The savepoint keeps the transaction usable after the statement fails
+	if _, err := tx.Exec("SAVEPOINT store_booking"); err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		result, err := tx.Exec(query, args...)
+		if !isPostgresError(err, postgresExclusionViolation) {
+			return result, err
+		}
+
+		if _, err := tx.Exec("ROLLBACK TO SAVEPOINT store_booking"); err != nil {
+			return nil, err
+		}
+		conflict, err := conflictError(tx, booking, period)
+		if err != nil {
+			return nil, err
+		} else if len(conflict.ConflictingIds) > 0 || attempt == postgresConflictRetries {
+			return nil, conflict
+		}
+	}
+}
+
+// prepareBooking checks a new booking as CreateBooking does, filling its id, state and creation time, and returns its period and transitions as stored
+func (s *PostgresBookingService) prepareBooking(booking *Booking) (*TimeRange, string, error) {
+	period, err := bookingPeriod(booking)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkBookingState(booking); err != nil {
+		return nil, "", err
+	}
+	booking.State = booking.State.orDefault()
+	if err := holdForApproval(s.approvals, booking, s.now()); err != nil {
+		return nil, "", err
+	}
+	if err := checkOpeningHours(s.openingHours, booking, period); err != nil {
+		return nil, "", err
+	}
+	transitions, err := postgresTransitions(booking.Transitions)
+	if err != nil {
+		return nil, "", err
+	}
+	if booking.Id == "" {
+		booking.Id = newRandomId()
+	}
+	if booking.CreatedAt.IsZero() {
+		booking.CreatedAt = s.now()
+	}
+	return period, transitions, s.expireHolds()
+}
+
+// insertBooking stores a new booking prepared by prepareBooking within a transaction
+func (s *PostgresBookingService) insertBooking(tx *sql.Tx, booking *Booking, period *TimeRange, transitions string) error {
+	_, err := s.storeBooking(
+		tx, booking, period,
+		"INSERT INTO bookings ("+postgresBookingColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		booking.Id, booking.UserId, booking.ItemId, booking.CreatedAt, period, booking.Description, string(booking.State), transitions,
+		postgresHoldUntil(booking),
+	)
+	if isPostgresError(err, postgresUniqueViolation) {
+		return fmt.Errorf("%w: %q", bookingAlreadyExistsError, booking.Id)
+	}
+	return err
+}
+
+/*
+CreateBooking stores a new booking.
+
+An id is generated when the booking has none, and CreatedAt is set by the clock of the service
+when it is zero. Bookings are only created pending or confirmed, and without State they are
+confirmed; other states are reached with TransitionBooking.
+
+Active bookings of items requiring approval are pending instead, and block their time until
+their hold is over, when they are rejected unless they were approved with TransitionBooking.
+
+Parameters:
+  - booking: The booking to create
+
+Returns:
+  - The created booking
+  - A *ConflictError if the booking collides with other bookings or slot holds of the item, or
+    another error if the booking does not end after it starts, is outside opening hours, its
+    state is not initial, its id is already taken or a statement fails
+*/
+func (s *PostgresBookingService) CreateBooking(booking Booking) (*Booking, error) {
+	period, transitions, err := s.prepareBooking(&booking)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := s.insertBooking(tx, &booking, period, transitions); err != nil {
+		return nil, err
+	}
+	return &booking, tx.Commit()
+}
+
+// checkUpdate checks a booking being updated is within opening hours and does not move to an item requiring approval while active, as the stored booking is
+func (s *PostgresBookingService) checkUpdate(booking *Booking, period *TimeRange) error {
+	if s.openingHours == nil && s.approvals == nil {
+		return nil
+	}
+	if err := s.expireHolds(); err != nil {
+		return err
+	}
+
+	// The checks depend on the stored state and item, as the given state is ignored
+	stored := *booking
+	err := s.db.QueryRow("SELECT state, item_id FROM bookings WHERE id = $1", booking.Id).Scan(&stored.State, &stored.ItemId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %q", bookingNotFoundError, booking.Id)
+	} else if err != nil {
+		return err
+	}
+	if stored.ItemId != booking.ItemId && stored.State.Active() {
+		if err := checkApprovalRequired(s.approvals, booking.ItemId); err != nil {
+			return err
+		}
+	}
+	stored.ItemId = booking.ItemId
+	return checkOpeningHours(s.openingHours, &stored, period)
+}
+
+/*
+UpdateBooking replaces a stored booking. The CreatedAt of the stored booking is kept when the
+given one is zero, and its state, transitions and hold are always kept, as they only change
+with TransitionBooking.
+
+Parameters:
+  - booking: The booking to update, identified by its id
+
+Returns:
+  - A *ConflictError if the booking collides with other bookings or slot holds of the item, or
+    another error if the booking does not exist, does not end after it starts, is outside
+    opening hours, is active and moves to another item requiring approval or a statement fails
+*/
+func (s *PostgresBookingService) UpdateBooking(booking *Booking) error {
+	period, err := bookingPeriod(booking)
+	if err != nil {
+		return err
+	}
+	if err := s.checkUpdate(booking, period); err != nil {
+		return err
+	}
+	var createdAt any
+	if !booking.CreatedAt.IsZero() {
+		createdAt = booking.CreatedAt
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := s.storeBooking(
+		tx, booking, period,
+		"UPDATE bookings SET user_id = $2, item_id = $3, created_at = COALESCE($4::timestamptz, created_at), "+
+			"period = $5, description = $6 WHERE id = $1",
+		booking.Id, booking.UserId, booking.ItemId, createdAt, period, booking.Description,
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result, bookingNotFoundError, booking.Id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+/*
+TransitionBooking moves a booking to another state, recording when, by whom and why it moved.
+
+The booking is locked while it moves, so concurrent transitions of the same booking are made
+one after another. Only the moves allowed by BookingState.CanTransitionTo are made.
+
+With WithPostgresApprovals, pending bookings are only confirmed or rejected by an approver of
+their item, while anyone may cancel them. Bookings whose hold is over are rejected already.
+
+Parameters:
+  - bookingId: The id of the booking
+  - state: The state to move to
+  - actorId: The id of the user changing the state, empty if it is changed by the system
+  - reason: Why the state changes, empty if there is none
+
+Returns:
+  - The booking in its new state
+  - An error if there is no booking with the id, it may not move to the state, the actor may
+    not approve it or a statement fails
+*/
+func (s *PostgresBookingService) TransitionBooking(bookingId string, state BookingState, actorId, reason string) (*Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := scanPostgresBooking(tx.QueryRow("SELECT "+postgresBookingColumns+" FROM bookings WHERE id = $1 FOR UPDATE", bookingId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %q", bookingNotFoundError, bookingId)
+	} else if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	expireHold(booking, now)
+	if s.approvals != nil && booking.State == BookingPending && (state == BookingConfirmed || state == BookingRejected) {
+		if err := checkApprover(s.approvals, booking, actorId); err != nil {
+			return nil, err
+		}
+	}
+	if err := booking.Transition(state, now, actorId, reason); err != nil {
+		return nil, err
+	}
+	transitions, err := postgresTransitions(booking.Transitions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE bookings SET state = $2, transitions = $3 WHERE id = $1", booking.Id, string(booking.State), transitions); err != nil {
+		return nil, err
+	}
+	return booking, tx.Commit()
+}
+
+// checkAffected returns a not found error if a statement affected no row
+func checkAffected(result sql.Result, notFound error, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("%w: %q", notFound, id)
+	}
+	return nil
+}
+
+/*
+DeleteBooking removes a booking.
+
+Parameters:
+  - bookingId: The id of the booking
+
+Returns:
+  - An error if there is no booking with the id or the statement fails
+*/
+func (s *PostgresBookingService) DeleteBooking(bookingId string) error {
+	result, err := s.db.Exec("DELETE FROM bookings WHERE id = $1", bookingId)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result, bookingNotFoundError, bookingId)
+}
@@ -0,0 +1,321 @@
+package bookk
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakePostgresError is an error of a PostgreSQL driver with a SQLSTATE code
+type fakePostgresError string
+
+func (e fakePostgresError) Error() string {
+	return "postgres error " + string(e)
+}
+
+func (e fakePostgresError) SQLState() string {
+	return string(e)
+}
+
+// equalTransitions compares transitions by value, whatever the location of their time
+func equalTransitions(a, b BookingTransition) bool {
+	return a.From == b.From && a.To == b.To && a.At.Equal(b.At) && a.ActorId == b.ActorId && a.Reason == b.Reason
+}
+
+var postgresBookingColumnNames = []string{"id", "user_id", "item_id", "created_at", "period", "description", "state", "transitions", "hold_until"}
+
+// expectStoreBookingChecks expects the transaction storing a booking to begin, lock its item and find no slot holds colliding with it
+func expectStoreBookingChecks(fake *fakeDatabase) {
+	fake.expect("BEGIN")
+	fake.expect("pg_advisory_xact_lock")
+	fake.expect("FROM slot_holds").willReturnRows([]string{"id"})
+	fake.expect("SAVEPOINT store_booking")
+}
+
+// expectStoreBooking expects a statement storing a booking in a transaction, as storeBooking runs it when it succeeds
+func expectStoreBooking(fake *fakeDatabase, query string, args ...driver.Value) *fakeExpectation {
+	expectStoreBookingChecks(fake)
+	statement := fake.expect(query, args...)
+	fake.expect("COMMIT")
+	return statement
+}
+
+// expectStoreBookingConflict expects a statement storing a booking rejected by the exclusion constraint, and the bookings read after it
+func expectStoreBookingConflict(fake *fakeDatabase, query string, lookupArgs []driver.Value, conflictingIds ...string) {
+	expectStoreBookingChecks(fake)
+	fake.expect(query).willFail(fakePostgresError(postgresExclusionViolation))
+	fake.expect("ROLLBACK TO SAVEPOINT store_booking")
+	rows := [][]driver.Value{}
+	for _, id := range conflictingIds {
+		rows = append(rows, []driver.Value{id})
+	}
+	fake.expect("FOR SHARE", lookupArgs...).willReturnRows([]string{"id"}, rows...)
+}
+
+func TestPostgresBookingServiceGet(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+	service := NewPostgresBookingService(db)
+	createdAt := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	fake.expect("FROM bookings WHERE id = $1", "a").willReturnRows(postgresBookingColumnNames, []driver.Value{
+		"a", "user-1", "item-1", createdAt, "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")", "Meeting", "confirmed",
+		`[{"From":"pending","To":"confirmed","At":"2025-01-01T09:30:00Z","ActorId":"admin","Reason":"Approved"}]`, nil,
+	})
+	booking, err := service.GetBookingById("a")
+	if err != nil {
+		t.Fatalf("Booking should be found. Instead: %v", err)
+	}
+	expected := Booking{
+		BaseBooking: BaseBooking{
+			Id:        "a",
+			UserId:    "user-1",
+			ItemId:    "item-1",
+			CreatedAt: createdAt,
+			StartsAt:  time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+			EndsAt:    time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC),
+		},
+		Description: "Meeting",
+		State:       BookingConfirmed,
+		Transitions: []BookingTransition{{From: BookingPending, To: BookingConfirmed, At: time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC), ActorId: "admin", Reason: "Approved"}},
+	}
+	if !booking.StartsAt.Equal(expected.StartsAt) || !booking.EndsAt.Equal(expected.EndsAt) || booking.Description != expected.Description || booking.ItemId != expected.ItemId ||
+		booking.State != expected.State || !slices.EqualFunc(booking.Transitions, expected.Transitions, equalTransitions) {
+		t.Errorf("No expected booking:\nExpecting\t: %+v\nRecieved\t: %+v", expected, *booking)
+	}
+
+	fake.expect("FROM bookings WHERE id = $1", "missing").willReturnRows(postgresBookingColumnNames)
+	if _, err := service.GetBookingById("missing"); !errors.Is(err, bookingNotFoundError) {
+		t.Errorf("Missing booking should not be found. Instead: %v", err)
+	}
+}
+
+func TestPostgresBookingServiceQueries(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+	service := NewPostgresBookingService(db)
+	rows := [][]driver.Value{
+		{"b", "user-1", "item-1", testTime, "[\"2025-01-01 11:00:00+00\",\"2025-01-01 12:00:00+00\")", "", "confirmed", "[]", nil},
+		{"a", "user-1", "item-1", testTime, "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")", "", "cancelled", "[]", nil},
+	}
+	day := time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC)
+	window, _ := NewTimeRange(day, day.Add(time.Hour), TimeRangeIlEu)
+
+	type TestCase struct {
+		name  string
+		query func() ([]*Booking, error)
+		setup func()
+	}
+
+	testCases := [6]TestCase{
+		{"Last by user", func() ([]*Booking, error) { return service.GetLastBookingsByUserId("user-1", 2) }, func() {
+			fake.expect("WHERE user_id = $1 ORDER BY lower(period) DESC, id LIMIT $2", "user-1", int64(2)).willReturnRows(postgresBookingColumnNames, rows...)
+		}},
+		{"Last by user unlimited", func() ([]*Booking, error) { return service.GetLastBookingsByUserId("user-1", 0) }, func() {
+			fake.expect("LIMIT $2", "user-1", nil).willReturnRows(postgresBookingColumnNames, rows...)
+		}},
+		{"Last by group", func() ([]*Booking, error) { return service.GetLastBookingsByGroupId("group", 0) }, func() {
+			fake.expect("FROM groups WHERE id = $1", "group").willReturnRows([]string{"exists"}, []driver.Value{true})
+			fake.expect("SELECT user_id FROM group_users WHERE group_id = $1", "group", nil).willReturnRows(postgresBookingColumnNames, rows...)
+		}},
+		{"Time range by user", func() ([]*Booking, error) { return service.GetBookingsByTimeRangeAndUserId("user-1", *window) }, func() {
+			fake.expect("period && $2::tstzrange", "user-1", "[\"2025-01-01 15:00:00+00\",\"2025-01-01 16:00:00+00\")").willReturnRows(postgresBookingColumnNames, rows...)
+		}},
+		{"Date by group", func() ([]*Booking, error) { return service.GetBookingsByDateAndGroupId("group", day) }, func() {
+			fake.expect("FROM groups WHERE id = $1", "group").willReturnRows([]string{"exists"}, []driver.Value{true})
+			fake.expect("group_id = $1) AND period && $2::tstzrange", "group", "[\"2025-01-01 00:00:00+00\",\"2025-01-02 00:00:00+00\")").willReturnRows(postgresBookingColumnNames, rows...)
+		}},
+		{"Time range by item", func() ([]*Booking, error) { return service.GetBookingsByTimeRangeAndItemId("item-1", *window) }, func() {
+			fake.expect("item_id = $1 AND period && $2::tstzrange", "item-1", "[\"2025-01-01 15:00:00+00\",\"2025-01-01 16:00:00+00\")").willReturnRows(postgresBookingColumnNames, rows...)
+		}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.setup()
+			bookings, err := testCase.query()
+			if err != nil {
+				t.Fatalf("Query should not fail. Instead: %v", err)
+			}
+			if ids := bookingIds(bookings); !slices.Equal(ids, []string{"b", "a"}) || bookings[1].State != BookingCancelled {
+				t.Errorf("No expected bookings. Instead: %v", ids)
+			}
+		})
+	}
+
+	t.Run("Missing group", func(t *testing.T) {
+		fake.expect("FROM groups WHERE id = $1", "missing").willReturnRows([]string{"exists"}, []driver.Value{false})
+		if _, err := service.GetLastBookingsByGroupId("missing", 0); !errors.Is(err, groupNotFoundError) {
+			t.Errorf("Bookings of a missing group should not be found. Instead: %v", err)
+		}
+	})
+}
+
+func TestPostgresBookingServiceCreate(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+	service := NewPostgresBookingService(db)
+	booking := Booking{BaseBooking: BaseBooking{
+		UserId:   "user-1",
+		ItemId:   "item-1",
+		StartsAt: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC),
+	}}
+	period := "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")"
+
+	t.Run("Success", func(t *testing.T) {
+		insert := expectStoreBooking(fake, "INSERT INTO bookings").willAffect(1)
+		created, err := service.CreateBooking(booking)
+		if err != nil {
+			t.Fatalf("Booking should be created. Instead: %v", err)
+		}
+		expected := []driver.Value{created.Id, "user-1", "item-1", created.CreatedAt, period, "", "confirmed", "[]", nil}
+		if created.Id == "" || created.CreatedAt.IsZero() || !fakeArgsEqual(expected, insert.received) {
+			t.Errorf("No expected insertion:\nExpecting\t: %v\nRecieved\t: %v", expected, insert.received)
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		expectStoreBookingConflict(fake, "INSERT INTO bookings", []driver.Value{"item-1", period, "new"}, "a", "b")
+		fake.expect("ROLLBACK")
+
+		conflicting := booking
+		conflicting.Id = "new"
+		_, err := service.CreateBooking(conflicting)
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || !slices.Equal(conflict.ConflictingIds, []string{"a", "b"}) || conflict.BookingId != "new" {
+			t.Errorf("Booking should conflict with a and b. Instead: %v", err)
+		}
+	})
+
+	t.Run("Conflict gone", func(t *testing.T) {
+		// The colliding booking is deleted before it is read, so the booking is inserted again
+		expectStoreBookingConflict(fake, "INSERT INTO bookings", []driver.Value{"item-1", period, "new"})
+		fake.expect("INSERT INTO bookings").willAffect(1)
+		fake.expect("COMMIT")
+
+		conflicting := booking
+		conflicting.Id = "new"
+		if created, err := service.CreateBooking(conflicting); err != nil || created.Id != "new" {
+			t.Errorf("Booking should be created once the conflict is gone. Instead: %+v %v", created, err)
+		}
+	})
+
+	t.Run("Existing id", func(t *testing.T) {
+		expectStoreBookingChecks(fake)
+		fake.expect("INSERT INTO bookings").willFail(fakePostgresError(postgresUniqueViolation))
+		fake.expect("ROLLBACK")
+		existing := booking
+		existing.Id = "a"
+		if _, err := service.CreateBooking(existing); !errors.Is(err, bookingAlreadyExistsError) {
+			t.Errorf("Booking with an existing id should fail. Instead: %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		invalid := booking
+		invalid.EndsAt = invalid.StartsAt.Add(-time.Hour)
+		if _, err := service.CreateBooking(invalid); !errors.Is(err, bookingTimeRangeError) {
+			t.Errorf("Booking ending before it starts should fail. Instead: %v", err)
+		}
+	})
+
+	t.Run("Not initial state", func(t *testing.T) {
+		cancelled := booking
+		cancelled.State = BookingCancelled
+		if _, err := service.CreateBooking(cancelled); !errors.Is(err, bookingInitialStateError) {
+			t.Errorf("Booking should not be created cancelled. Instead: %v", err)
+		}
+	})
+
+	t.Run("Zero length", func(t *testing.T) {
+		instant := booking
+		instant.EndsAt = instant.StartsAt
+		if _, err := service.CreateBooking(instant); !errors.Is(err, bookingPeriodEmptyError) {
+			t.Errorf("Booking lasting no time should fail. Instead: %v", err)
+		}
+	})
+}
+
+func TestPostgresBookingServiceUpdateAndDelete(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+	service := NewPostgresBookingService(db)
+	booking := &Booking{BaseBooking: BaseBooking{
+		Id:       "a",
+		UserId:   "user-1",
+		ItemId:   "item-1",
+		StartsAt: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC),
+	}}
+	period := "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")"
+
+	expectStoreBooking(fake, "UPDATE bookings", "a", "user-1", "item-1", nil, period, "").willAffect(1)
+	if err := service.UpdateBooking(booking); err != nil {
+		t.Errorf("Booking should be updated. Instead: %v", err)
+	}
+
+	expectStoreBookingConflict(fake, "UPDATE bookings", []driver.Value{"item-1", period, "a"}, "b")
+	fake.expect("ROLLBACK")
+	if err := service.UpdateBooking(booking); !errors.Is(err, bookingConflictError) {
+		t.Errorf("Booking should conflict with b. Instead: %v", err)
+	}
+
+	expectStoreBookingChecks(fake)
+	fake.expect("UPDATE bookings").willAffect(0)
+	fake.expect("ROLLBACK")
+	if err := service.UpdateBooking(booking); !errors.Is(err, bookingNotFoundError) {
+		t.Errorf("Missing booking should not be updated. Instead: %v", err)
+	}
+
+	fake.expect("DELETE FROM bookings WHERE id = $1", "a").willAffect(1)
+	if err := service.DeleteBooking("a"); err != nil {
+		t.Errorf("Booking should be deleted. Instead: %v", err)
+	}
+	fake.expect("DELETE FROM bookings WHERE id = $1", "a").willAffect(0)
+	if err := service.DeleteBooking("a"); !errors.Is(err, bookingNotFoundError) {
+		t.Errorf("Missing booking should not be deleted. Instead: %v", err)
+	}
+}
+
+func TestPostgresBookingServiceTransition(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+	service := NewPostgresBookingService(db)
+	row := func(state string) []driver.Value {
+		return []driver.Value{"a", "user-1", "item-1", testTime, "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")", "", state, "[]", nil}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		fake.expect("BEGIN")
+		fake.expect("FROM bookings WHERE id = $1 FOR UPDATE", "a").willReturnRows(postgresBookingColumnNames, row("confirmed"))
+		update := fake.expect("UPDATE bookings SET state = $2, transitions = $3 WHERE id = $1").willAffect(1)
+		fake.expect("COMMIT")
+
+		booking, err := service.TransitionBooking("a", BookingCheckedIn, "user-2", "Arrived")
+		if err != nil || booking.State != BookingCheckedIn || len(booking.Transitions) != 1 {
+			t.Fatalf("Booking should be checked in. Instead: %+v %v", booking, err)
+		}
+		var stored []BookingTransition
+		if len(update.received) != 3 || update.received[1] != "checked-in" || json.Unmarshal([]byte(update.received[2].(string)), &stored) != nil ||
+			!slices.EqualFunc(stored, booking.Transitions, equalTransitions) {
+			t.Errorf("No expected update. Instead: %v", update.received)
+		}
+	})
+
+	t.Run("Not allowed", func(t *testing.T) {
+		fake.expect("BEGIN")
+		fake.expect("FOR UPDATE", "a").willReturnRows(postgresBookingColumnNames, row("cancelled"))
+		fake.expect("ROLLBACK")
+		if _, err := service.TransitionBooking("a", BookingConfirmed, "", ""); !errors.Is(err, bookingTransitionError) {
+			t.Errorf("Cancelled booking should not be confirmed. Instead: %v", err)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		fake.expect("BEGIN")
+		fake.expect("FOR UPDATE", "missing").willReturnRows(postgresBookingColumnNames)
+		fake.expect("ROLLBACK")
+		if _, err := service.TransitionBooking("missing", BookingCancelled, "", ""); !errors.Is(err, bookingNotFoundError) {
+			t.Errorf("Missing booking should not move. Instead: %v", err)
+		}
+	})
+}
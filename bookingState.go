@@ -0,0 +1,122 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	bookingStateError        = errors.New("Booking state not recognized")
+	bookingInitialStateError = errors.New("Booking may only be created pending or confirmed")
+	bookingTransitionError   = errors.New("Booking state transition not allowed")
+)
+
+// BookingState is a step of the lifecycle of a booking.
+type BookingState string
+
+const (
+	BookingPending   BookingState = "pending"    // Waiting to be confirmed or rejected
+	BookingConfirmed BookingState = "confirmed"  // Accepted, and the state of new bookings by default
+	BookingCheckedIn BookingState = "checked-in" // The user arrived and is using the item
+	BookingCompleted BookingState = "completed"  // The user left the item
+	BookingNoShow    BookingState = "no-show"    // The user never arrived
+	BookingCancelled BookingState = "cancelled"  // Withdrawn before it took place
+	BookingRejected  BookingState = "rejected"   // Refused while pending
+)
+
+// bookingTransitions lists the states every state may move to. Completed, no-show, cancelled and rejected bookings are final
+var bookingTransitions = map[BookingState][]BookingState{
+	BookingPending:   {BookingConfirmed, BookingRejected, BookingCancelled},
+	BookingConfirmed: {BookingCheckedIn, BookingNoShow, BookingCancelled},
+	BookingCheckedIn: {BookingCompleted},
+	BookingCompleted: {},
+	BookingNoShow:    {},
+	BookingCancelled: {},
+	BookingRejected:  {},
+}
+
+// orDefault returns the state, taking the zero state as confirmed
+func (s BookingState) orDefault() BookingState {
+	if s == "" {
+		return BookingConfirmed
+	}
+	return s
+}
+
+// Valid tells whether the state is known. The zero state is valid and taken as confirmed.
+func (s BookingState) Valid() bool {
+	_, found := bookingTransitions[s.orDefault()]
+	return found
+}
+
+// Initial tells whether a booking may be created in the state. Only pending, confirmed and the zero state are initial.
+func (s BookingState) Initial() bool {
+	return s.orDefault() == BookingPending || s.orDefault() == BookingConfirmed
+}
+
+/*
+Active tells whether a booking in the state takes the time of its item.
+
+Only pending, confirmed and checked-in bookings are active, so they are the only ones checked
+for conflicts and opening hours.
+*/
+func (s BookingState) Active() bool {
+	switch s.orDefault() {
+	case BookingPending, BookingConfirmed, BookingCheckedIn:
+		return true
+	}
+	return false
+}
+
+/*
+CanTransitionTo tells whether a booking may move from the state to another one.
+
+Parameters:
+  - to: The state to move to
+*/
+func (s BookingState) CanTransitionTo(to BookingState) bool {
+	return slices.Contains(bookingTransitions[s.orDefault()], to)
+}
+
+// BookingTransition records a change of the state of a booking.
+type BookingTransition struct {
+	From    BookingState
+	To      BookingState
+	At      time.Time
+	ActorId string // Id of the user who changed the state, empty if it was changed by the system
+	Reason  string
+}
+
+// checkBookingState returns an error if a new booking may not start in its state, as other states are only reached with transitions
+func checkBookingState(booking *Booking) error {
+	if !booking.State.Valid() {
+		return fmt.Errorf("%w: %q of booking %q", bookingStateError, booking.State, booking.Id)
+	} else if !booking.State.Initial() {
+		return fmt.Errorf("%w: %q of booking %q", bookingInitialStateError, booking.State, booking.Id)
+	}
+	return nil
+}
+
+/*
+Transition moves the booking to another state, recording the transition.
+
+Parameters:
+  - to: The state to move to
+  - at: When the state changed
+  - actorId: The id of the user changing the state, empty if it is changed by the system
+  - reason: Why the state changed, empty if there is none
+
+Returns:
+  - An error if the booking may not move from its state to the given one
+*/
+func (b *Booking) Transition(to BookingState, at time.Time, actorId, reason string) error {
+	from := b.State.orDefault()
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: booking %q from %s to %s", bookingTransitionError, b.Id, from, to)
+	}
+	b.State = to
+	b.Transitions = append(b.Transitions, BookingTransition{From: from, To: to, At: at, ActorId: actorId, Reason: reason})
+	return nil
+}
@@ -0,0 +1,130 @@
+package bookk
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBookingState(t *testing.T) {
+	states := [7]BookingState{BookingPending, BookingConfirmed, BookingCheckedIn, BookingCompleted, BookingNoShow, BookingCancelled, BookingRejected}
+	allowed := map[BookingState][]BookingState{
+		BookingPending:   {BookingConfirmed, BookingRejected, BookingCancelled},
+		BookingConfirmed: {BookingCheckedIn, BookingNoShow, BookingCancelled},
+		BookingCheckedIn: {BookingCompleted},
+	}
+
+	for _, from := range states {
+		for _, to := range states {
+			expected := false
+			for _, state := range allowed[from] {
+				expected = expected || state == to
+			}
+			if received := from.CanTransitionTo(to); received != expected {
+				t.Errorf("No expected transition from %s to %s:\nExpecting\t: %v\nRecieved\t: %v", from, to, expected, received)
+			}
+		}
+	}
+
+	t.Run("Active", func(t *testing.T) {
+		for state, expected := range map[BookingState]bool{
+			"":               true,
+			BookingPending:   true,
+			BookingConfirmed: true,
+			BookingCheckedIn: true,
+			BookingCompleted: false,
+			BookingNoShow:    false,
+			BookingCancelled: false,
+			BookingRejected:  false,
+		} {
+			if received := state.Active(); received != expected {
+				t.Errorf("No expected activity of %q:\nExpecting\t: %v\nRecieved\t: %v", state, expected, received)
+			}
+		}
+	})
+
+	t.Run("Initial", func(t *testing.T) {
+		for _, state := range states {
+			expected := state == BookingPending || state == BookingConfirmed
+			if received := state.Initial(); received != expected {
+				t.Errorf("No expected initial state %q:\nExpecting\t: %v\nRecieved\t: %v", state, expected, received)
+			}
+		}
+		if !BookingState("").Initial() {
+			t.Errorf("Zero state should be initial")
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		if !BookingState("").Valid() || !BookingRejected.Valid() || BookingState("lost").Valid() {
+			t.Errorf("Only known states should be valid")
+		}
+		if BookingState("").CanTransitionTo(BookingCheckedIn) != BookingConfirmed.CanTransitionTo(BookingCheckedIn) {
+			t.Errorf("Zero state should move as a confirmed one")
+		}
+	})
+}
+
+func TestBookingTransition(t *testing.T) {
+	at := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	booking := hoursBooking("a", "item", 0, 1)
+
+	if err := booking.Transition(BookingCancelled, at, "user", "Ill"); err != nil {
+		t.Fatalf("Booking should be cancelled. Instead: %v", err)
+	}
+	expected := BookingTransition{From: BookingConfirmed, To: BookingCancelled, At: at, ActorId: "user", Reason: "Ill"}
+	if booking.State != BookingCancelled || len(booking.Transitions) != 1 || booking.Transitions[0] != expected {
+		t.Errorf("No expected transition:\nExpecting\t: %+v\nRecieved\t: %+v", expected, booking.Transitions)
+	}
+
+	if err := booking.Transition(BookingConfirmed, at, "admin", ""); !errors.Is(err, bookingTransitionError) {
+		t.Errorf("Cancelled booking should not be confirmed. Instead: %v", err)
+	}
+	if booking.State != BookingCancelled || len(booking.Transitions) != 1 {
+		t.Errorf("Refused transition should change nothing. Instead: %+v", booking)
+	}
+}
+
+func TestMemoryBookingServiceTransition(t *testing.T) {
+	at := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	service := NewMemoryBookingService(WithClock(func() time.Time { return at }))
+
+	pending := *hoursBooking("pending", "item", 0, 2)
+	pending.State = BookingPending
+	if _, err := service.CreateBooking(pending); err != nil {
+		t.Fatalf("Pending booking should be created. Instead: %v", err)
+	}
+	if _, err := service.CreateBooking(*hoursBooking("colliding", "item", 1, 3)); !errors.Is(err, bookingConflictError) {
+		t.Errorf("Pending booking should take its time. Instead: %v", err)
+	}
+
+	rejected, err := service.TransitionBooking("pending", BookingRejected, "admin", "Full")
+	if err != nil || rejected.State != BookingRejected || !rejected.Transitions[0].At.Equal(at) {
+		t.Fatalf("Pending booking should be rejected. Instead: %+v %v", rejected, err)
+	}
+	rejected.Transitions[0].Reason = "Changed"
+	if stored, _ := service.GetBookingById("pending"); stored.Transitions[0].Reason != "Full" {
+		t.Errorf("Transitions should be copied. Instead: %+v", stored.Transitions)
+	}
+	if _, err := service.CreateBooking(*hoursBooking("colliding", "item", 1, 3)); err != nil {
+		t.Errorf("Rejected booking should free its time. Instead: %v", err)
+	}
+
+	if _, err := service.TransitionBooking("pending", BookingConfirmed, "admin", ""); !errors.Is(err, bookingTransitionError) {
+		t.Errorf("Rejected booking should not be confirmed. Instead: %v", err)
+	}
+	if _, err := service.TransitionBooking("missing", BookingCancelled, "", ""); !errors.Is(err, bookingNotFoundError) {
+		t.Errorf("Missing booking should not move. Instead: %v", err)
+	}
+
+	unknown := *hoursBooking("unknown", "item", 5, 6)
+	unknown.State = "lost"
+	if _, err := service.CreateBooking(unknown); !errors.Is(err, bookingStateError) {
+		t.Errorf("Booking in an unknown state should not be created. Instead: %v", err)
+	}
+	completed := *hoursBooking("completed", "item", 5, 6)
+	completed.State = BookingCompleted
+	if _, err := service.CreateBooking(completed); !errors.Is(err, bookingInitialStateError) {
+		t.Errorf("Booking should not be created completed. Instead: %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package bookktest
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iPy849/bookk"
+)
+
+// BookingServiceFactory builds an empty IBookingService for a test, whose groups have the users told by groupUsers.
+type BookingServiceFactory func(t *testing.T, groupUsers bookk.GroupUsersFunc) bookk.IBookingService[bookk.Booking]
+
+func bookingId(booking *bookk.Booking) string {
+	return booking.Id
+}
+
+// newBooking builds a booking some hours after baseTime
+func newBooking(userId, itemId string, startsAt, endsAt float64) bookk.Booking {
+	return bookk.Booking{BaseBooking: bookk.BaseBooking{UserId: userId, ItemId: itemId, StartsAt: hours(startsAt), EndsAt: hours(endsAt)}}
+}
+
+// createBookings creates bookings and returns their ids
+func createBookings(t *testing.T, service bookk.IBookingService[bookk.Booking], bookings ...bookk.Booking) []string {
+	t.Helper()
+	created := make([]string, len(bookings))
+	for i, booking := range bookings {
+		stored, err := service.CreateBooking(booking)
+		if err != nil {
+			t.Fatalf("Booking %d should be created. Instead: %v", i, err)
+		}
+		created[i] = stored.Id
+	}
+	return created
+}
+
+/*
+RunBookingServiceSuite checks an IBookingService behaves as bookk expects:
+
+  - Created bookings get an id, a creation time and the confirmed state, and bookings are only
+    created pending or confirmed
+  - Operations on missing bookings are an error
+  - Bookings of the same item sharing any instant are a *bookk.ConflictError, while bookings are
+    [StartsAt, EndsAt), so back to back bookings do not collide, and cancelled bookings never collide
+  - Bookings only move to the states allowed by their lifecycle, recording every transition, and
+    bookings no longer active free their time
+  - Last bookings are ordered from the newest to the oldest StartsAt and limited
+  - Bookings are found by time range and date, for a user and for the users of a group
+  - Concurrent creations of colliding bookings create only one of them
+
+Parameters:
+  - t: The test running the suite
+  - factory: Builds an empty service for every subtest
+*/
+func RunBookingServiceSuite(t *testing.T, factory BookingServiceFactory) {
+	groups := map[string][]string{"group": {"user-1", "user-2"}}
+	groupUsers := func(groupId string) ([]string, error) {
+		if users, found := groups[groupId]; found {
+			return users, nil
+		}
+		return nil, errors.New("Group not found")
+	}
+
+	t.Run("Create and get", func(t *testing.T) {
+		service := factory(t, groupUsers)
+		booking := newBooking("user-1", "item-1", 0, 1)
+		booking.Description = "Meeting"
+		created, err := service.CreateBooking(booking)
+		if err != nil || created.Id == "" || created.CreatedAt.IsZero() || created.State != bookk.BookingConfirmed {
+			t.Fatalf("Booking should be created with id, creation time and state. Instead: %+v %v", created, err)
+		}
+
+		stored, err := service.GetBookingById(created.Id)
+		if err != nil {
+			t.Fatalf("Created booking should be found. Instead: %v", err)
+		}
+		if stored.UserId != booking.UserId || stored.ItemId != booking.ItemId || stored.Description != booking.Description ||
+			!stored.StartsAt.Equal(booking.StartsAt) || !stored.EndsAt.Equal(booking.EndsAt) {
+			t.Errorf("Booking should be stored. Instead: %+v", stored)
+		}
+
+		if _, err := service.GetBookingById("missing"); err == nil {
+			t.Errorf("Missing booking should not be found")
+		}
+		if _, err := service.CreateBooking(newBooking("user-1", "item-1", 3, 2)); err == nil {
+			t.Errorf("Booking ending before it starts should not be created")
+		}
+		cancelled := newBooking("user-1", "item-1", 2, 3)
+		cancelled.State = bookk.BookingCancelled
+		if _, err := service.CreateBooking(cancelled); err == nil {
+			t.Errorf("Booking should not be created cancelled")
+		}
+	})
+
+	t.Run("Conflicts", func(t *testing.T) {
+		service := factory(t, groupUsers)
+		created := createBookings(t, service,
+			newBooking("user-1", "item-1", 0, 2),
+			newBooking("user-2", "item-1", 2, 3),
+			newBooking("user-1", "item-2", 0, 3),
+		)
+
+		_, err := service.CreateBooking(newBooking("user-2", "item-1", 1, 2.5))
+		var conflict *bookk.ConflictError
+		if !errors.As(err, &conflict) || !sameIds(conflict.ConflictingIds, created[:2]) || conflict.ItemId != "item-1" {
+			t.Errorf("Booking should conflict with the bookings of the item. Instead: %v", err)
+		}
+
+		for range 2 {
+			cancelled := createBookings(t, service, newBooking("user-1", "item-1", 4, 5))
+			if _, err := service.TransitionBooking(cancelled[0], bookk.BookingCancelled, "user-1", ""); err != nil {
+				t.Fatalf("Booking should be cancelled. Instead: %v", err)
+			}
+		}
+		createBookings(t, service, newBooking("user-2", "item-1", 4, 5))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		service := factory(t, groupUsers)
+		created := createBookings(t, service, newBooking("user-1", "item-1", 0, 1), newBooking("user-1", "item-1", 2, 3))
+
+		booking, _ := service.GetBookingById(created[0])
+		booking.EndsAt = hours(2)
+		booking.Description = "Longer"
+		if err := service.UpdateBooking(booking); err != nil {
+			t.Fatalf("Booking overlapping itself should be updated. Instead: %v", err)
+		}
+		if stored, _ := service.GetBookingById(created[0]); !stored.EndsAt.Equal(booking.EndsAt) || stored.Description != booking.Description {
+			t.Errorf("Booking should be stored updated. Instead: %+v", stored)
+		}
+
+		booking.EndsAt = hours(2.5)
+		var conflict *bookk.ConflictError
+		if err := service.UpdateBooking(booking); !errors.As(err, &conflict) || !slices.Equal(conflict.ConflictingIds, created[1:]) {
+			t.Errorf("Booking should conflict with the other booking. Instead: %v", err)
+		}
+
+		missing := newBooking("user-1", "item-1", 5, 6)
+		missing.Id = "missing"
+		if err := service.UpdateBooking(&missing); err == nil {
+			t.Errorf("Missing booking should not be updated")
+		}
+	})
+
+	t.Run("Transitions", func(t *testing.T) {
+		service := factory(t, groupUsers)
+		id := createBookings(t, service, newBooking("user-1", "item-1", 0, 1))[0]
+
+		checkedIn, err := service.TransitionBooking(id, bookk.BookingCheckedIn, "user-2", "Arrived")
+		if err != nil || checkedIn.State != bookk.BookingCheckedIn || len(checkedIn.Transitions) != 1 {
+			t.Fatalf("Booking should be checked in. Instead: %+v %v", checkedIn, err)
+		}
+		transition := checkedIn.Transitions[0]
+		if transition.From != bookk.BookingConfirmed || transition.To != bookk.BookingCheckedIn ||
+			transition.ActorId != "user-2" || transition.Reason != "Arrived" || transition.At.IsZero() {
+			t.Errorf("Transition should be recorded. Instead: %+v", transition)
+		}
+
+		if _, err := service.TransitionBooking(id, bookk.BookingCancelled, "user-1", ""); err == nil {
+			t.Errorf("Checked in booking should not be cancelled")
+		}
+		if _, err := service.TransitionBooking(id, bookk.BookingCompleted, "", ""); err != nil {
+			t.Fatalf("Checked in booking should be completed. Instead: %v", err)
+		}
+		if _, err := service.TransitionBooking(id, bookk.BookingConfirmed, "", ""); err == nil {
+			t.Errorf("Completed booking should not be confirmed")
+		}
+		if _, err := service.TransitionBooking("missing", bookk.BookingCancelled, "", ""); err == nil {
+			t.Errorf("Missing booking should not move")
+		}
+
+		stored, _ := service.GetBookingById(id)
+		if stored.State != bookk.BookingCompleted || len(stored.Transitions) != 2 {
+			t.Errorf("Transitions should be stored. Instead: %+v", stored)
+		}
+		stored.Description = "Updated"
+		if err := service.UpdateBooking(stored); err != nil {
+			t.Fatalf("Completed booking should be updated. Instead: %v", err)
+		}
+		createBookings(t, service, newBooking("user-2", "item-1", 0, 1))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		service := factory(t, groupUsers)
+		id := createBookings(t, service, newBooking("user-1", "item-1", 0, 1))[0]
+		if err := service.DeleteBooking(id); err != nil {
+			t.Fatalf("Booking should be deleted. Instead: %v", err)
+		}
+		if _, err := service.GetBookingById(id); err == nil {
+			t.Errorf("Deleted booking should not be found")
+		}
+		if err := service.DeleteBooking(id); err == nil {
+			t.Errorf("Deleted booking should not be deleted again")
+		}
+		createBookings(t, service, newBooking("user-1", "item-1", 0, 1))
+	})
+
+	t.Run("Last bookings", func(t *testing.T) {
+		service := factory(t, groupUsers)
+		created := createBookings(t, service,
+			newBooking("user-1", "item-1", 1, 2),
+			newBooking("user-1", "item-1", 3, 4),
+			newBooking("user-2", "item-1", 4, 5),
+			newBooking("user-1", "item-2", 0, 1),
+			newBooking("user-3", "item-2", 5, 6),
+		)
+
+		if last, err := service.GetLastBookingsByUserId("user-1", 0); err != nil || !slices.Equal(ids(last, bookingId), []string{created[1], created[0], created[3]}) {
+			t.Errorf("Bookings should be ordered from the newest. Instead: %v %v", ids(last, bookingId), err)
+		}
+		if last, _ := service.GetLastBookingsByUserId("user-1", 2); !slices.Equal(ids(last, bookingId), []string{created[1], created[0]}) {
+			t.Errorf("Bookings should be limited. Instead: %v", ids(last, bookingId))
+		}
+		if last, err := service.GetLastBookingsByGroupId("group", 2); err != nil || !slices.Equal(ids(last, bookingId), []string{created[2], created[1]}) {
+			t.Errorf("Bookings of the group should be ordered from the newest. Instead: %v %v", ids(last, bookingId), err)
+		}
+		if last, err := service.GetLastBookingsByUserId("missing", 0); err != nil || len(last) != 0 {
+			t.Errorf("User without bookings should have none. Instead: %v %v", ids(last, bookingId), err)
+		}
+		if _, err := service.GetLastBookingsByGroupId("missing", 0); err == nil {
+			t.Errorf("Bookings of a missing group should not be found")
+		}
+	})
+
+	t.Run("Time range and date", func(t *testing.T) {
+		service := factory(t, groupUsers)
+		created := createBookings(t, service,
+			newBooking("user-1", "item-1", 0, 1),
+			newBooking("user-1", "item-1", 1, 2),
+			newBooking("user-2", "item-2", 1.5, 3),
+			newBooking("user-1", "item-1", 24, 25),
+			newBooking("user-3", "item-3", 1, 2),
+		)
+
+		window, _ := bookk.NewTimeRange(hours(1), hours(2), bookk.TimeRangeIlEu)
+		if found, err := service.GetBookingsByTimeRangeAndUserId("user-1", *window); err != nil || !sameIds(ids(found, bookingId), created[1:2]) {
+			t.Errorf("No expected bookings of the user in the time range. Instead: %v %v", ids(found, bookingId), err)
+		}
+		if found, err := service.GetBookingsByTimeRangeAndGroupId("group", *window); err != nil || !sameIds(ids(found, bookingId), created[1:3]) {
+			t.Errorf("No expected bookings of the group in the time range. Instead: %v %v", ids(found, bookingId), err)
+		}
+
+		date := time.Date(baseTime.Year(), baseTime.Month(), baseTime.Day(), 23, 0, 0, 0, time.UTC)
+		if found, err := service.GetBookingsByDateAndUserId("user-1", date); err != nil || !sameIds(ids(found, bookingId), created[:2]) {
+			t.Errorf("No expected bookings of the user in the date. Instead: %v %v", ids(found, bookingId), err)
+		}
+		if found, err := service.GetBookingsByDateAndGroupId("group", date.AddDate(0, 0, 1)); err != nil || !sameIds(ids(found, bookingId), created[3:4]) {
+			t.Errorf("No expected bookings of the group in the date. Instead: %v %v", ids(found, bookingId), err)
+		}
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		service := factory(t, groupUsers)
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		created := 0
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := service.CreateBooking(newBooking("user-1", "item-1", 0, 1)); err == nil {
+					mu.Lock()
+					created++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if created != 1 {
+			t.Errorf("Only 1 of the colliding bookings should be created. Instead: %d", created)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+/*
+Package bookktest provides conformance suites for implementations of the bookk repositories
+and services.
+
+Every suite runs a set of subtests, each one against a fresh implementation built by the
+factory given, so implementations backed by a database should give each call its own clean
+schema or transaction. The suites only rely on the exported contract of bookk: errors are
+checked to be returned, and conflicts to be a *bookk.ConflictError, but no specific error
+value is required.
+*/
+package bookktest
+
+import (
+	"slices"
+	"time"
+)
+
+// baseTime is the origin of every time used by the suites, with microsecond precision as most databases store
+var baseTime = time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+
+// hours returns the time some hours after baseTime
+func hours(h float64) time.Time {
+	return baseTime.Add(time.Duration(h * float64(time.Hour)))
+}
+
+// ids returns the ids of a set of entities
+func ids[T any](entities []*T, id func(*T) string) []string {
+	result := make([]string, len(entities))
+	for i, entity := range entities {
+		result[i] = id(entity)
+	}
+	return result
+}
+
+// sameIds tells whether two sets of ids hold the same ids, in any order
+func sameIds(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,42 @@
+package bookktest
+
+import (
+	"testing"
+
+	"github.com/iPy849/bookk"
+)
+
+// openFileStore opens a store in a temporary directory, closed when the test ends
+func openFileStore(t *testing.T, options ...bookk.FileStoreOption) *bookk.FileStore {
+	store, err := bookk.OpenFileStore(t.TempDir(), options...)
+	if err != nil {
+		t.Fatalf("File store should be opened. Instead: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestFileUserRepository(t *testing.T) {
+	RunUserRepositorySuite(t, func(t *testing.T) bookk.IUserRepository[bookk.User] {
+		return openFileStore(t).Users()
+	})
+}
+
+func TestFileItemRepository(t *testing.T) {
+	RunItemRepositorySuite(t, func(t *testing.T, relatedUserIds bookk.RelatedUserIdsFunc) bookk.IItemRepository[bookk.Item] {
+		return openFileStore(t, bookk.WithItemRepositoryOptions(bookk.WithRelatedUserIds(relatedUserIds))).Items()
+	})
+}
+
+func TestFileGroupService(t *testing.T) {
+	RunGroupServiceSuite(t, func(t *testing.T) GroupFixture {
+		store := openFileStore(t)
+		return GroupFixture{Groups: store.Groups(), Users: store.Users(), Items: store.Items()}
+	})
+}
+
+func TestFileBookingService(t *testing.T) {
+	RunBookingServiceSuite(t, func(t *testing.T, groupUsers bookk.GroupUsersFunc) bookk.IBookingService[bookk.Booking] {
+		return openFileStore(t, bookk.WithBookingServiceOptions(bookk.WithGroupUsers(groupUsers))).Bookings()
+	})
+}
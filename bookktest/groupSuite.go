@@ -0,0 +1,150 @@
+package bookktest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/iPy849/bookk"
+)
+
+// GroupFixture holds a group service along with the repositories its users and items are read from.
+type GroupFixture struct {
+	Groups bookk.IGroupService[bookk.Group, bookk.User, bookk.Item]
+	Users  bookk.IUserRepository[bookk.User]
+	Items  bookk.IItemRepository[bookk.Item]
+}
+
+// GroupServiceFactory builds an empty IGroupService and its repositories for a test.
+type GroupServiceFactory func(t *testing.T) GroupFixture
+
+// createGroup creates a group and returns its id
+func createGroup(t *testing.T, groups bookk.IGroupService[bookk.Group, bookk.User, bookk.Item]) string {
+	t.Helper()
+	group, err := groups.CreateGroup(bookk.Group{BaseGroup: bookk.BaseGroup{Name: "Group"}})
+	if err != nil {
+		t.Fatalf("Group should be created. Instead: %v", err)
+	}
+	return group.Id
+}
+
+/*
+RunGroupServiceSuite checks an IGroupService behaves as bookk expects:
+
+  - Created groups get an id and a creation time
+  - Operations on missing groups are an error
+  - Adding a user twice does nothing and deleted users are not members anymore
+  - The items of a group are the items of its users, except the excluded ones
+  - Concurrent additions do not lose users
+
+Parameters:
+  - t: The test running the suite
+  - factory: Builds an empty service for every subtest
+*/
+func RunGroupServiceSuite(t *testing.T, factory GroupServiceFactory) {
+	t.Run("Create, update and delete", func(t *testing.T) {
+		groups := factory(t).Groups
+		created, err := groups.CreateGroup(bookk.Group{BaseGroup: bookk.BaseGroup{Name: "Group"}, Description: "First"})
+		if err != nil || created.Id == "" || created.CreatedAt.IsZero() {
+			t.Fatalf("Group should be created with id and creation time. Instead: %+v %v", created, err)
+		}
+
+		created.Description = "Updated"
+		if err := groups.UpdateGroup(created); err != nil {
+			t.Fatalf("Group should be updated. Instead: %v", err)
+		}
+		if stored, err := groups.GetGroupById(created.Id); err != nil || stored.Description != created.Description || stored.Name != created.Name {
+			t.Errorf("Group should be stored updated. Instead: %+v %v", stored, err)
+		}
+		if err := groups.UpdateGroup(&bookk.Group{BaseGroup: bookk.BaseGroup{Id: "missing"}}); err == nil {
+			t.Errorf("Missing group should not be updated")
+		}
+
+		if err := groups.DeleteGroup(created.Id); err != nil {
+			t.Fatalf("Group should be deleted. Instead: %v", err)
+		}
+		if _, err := groups.GetGroupById(created.Id); err == nil {
+			t.Errorf("Deleted group should not be found")
+		}
+		if err := groups.DeleteGroup(created.Id); err == nil {
+			t.Errorf("Deleted group should not be deleted again")
+		}
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		fixture := factory(t)
+		groupId := createGroup(t, fixture.Groups)
+		users := createUsers(t, fixture.Users, bookk.ROLE_USER, bookk.ROLE_USER, bookk.ROLE_USER)
+		for _, id := range []string{users[0], users[1], users[0], users[2]} {
+			if err := fixture.Groups.AddUserToGroup(groupId, id); err != nil {
+				t.Fatalf("User should be added. Instead: %v", err)
+			}
+		}
+		if err := fixture.Groups.AddUserToGroup("missing", users[0]); err == nil {
+			t.Errorf("User should not be added to a missing group")
+		}
+		if err := fixture.Groups.AddUserToGroup(groupId, "missing"); err == nil {
+			t.Errorf("Missing user should not be added")
+		}
+
+		if members, err := fixture.Groups.GetGroupUsers(groupId); err != nil || !sameIds(ids(members, userId), users) {
+			t.Errorf("No expected group users. Instead: %v %v", ids(members, userId), err)
+		}
+
+		if err := fixture.Groups.DeleteUserFromGroup(groupId, users[0]); err != nil {
+			t.Fatalf("User should be removed. Instead: %v", err)
+		}
+		if err := fixture.Groups.DeleteUserFromGroup(groupId, users[0]); err == nil {
+			t.Errorf("User not in the group should not be removed")
+		}
+		fixture.Users.DeleteUser(users[1])
+		if members, _ := fixture.Groups.GetGroupUsers(groupId); !sameIds(ids(members, userId), users[2:]) {
+			t.Errorf("Removed and deleted users should not be members. Instead: %v", ids(members, userId))
+		}
+	})
+
+	t.Run("Items", func(t *testing.T) {
+		fixture := factory(t)
+		groupId := createGroup(t, fixture.Groups)
+		users := createUsers(t, fixture.Users, bookk.ROLE_USER, bookk.ROLE_USER, bookk.ROLE_USER)
+		items := createItems(t, fixture.Items, users[0], users[0], users[1], users[2])
+		fixture.Groups.AddUserToGroup(groupId, users[0])
+		fixture.Groups.AddUserToGroup(groupId, users[1])
+
+		if groupItems, err := fixture.Groups.GetGroupItems(groupId); err != nil || !sameIds(ids(groupItems, itemId), items[:3]) {
+			t.Errorf("No expected group items. Instead: %v %v", ids(groupItems, itemId), err)
+		}
+		for range 2 {
+			if err := fixture.Groups.ExcludeGroupItem(groupId, items[1]); err != nil {
+				t.Fatalf("Item should be excluded. Instead: %v", err)
+			}
+		}
+		if err := fixture.Groups.ExcludeGroupItem("missing", items[0]); err == nil {
+			t.Errorf("Item should not be excluded from a missing group")
+		}
+		if groupItems, _ := fixture.Groups.GetGroupItems(groupId); !sameIds(ids(groupItems, itemId), []string{items[0], items[2]}) {
+			t.Errorf("Excluded items should not be group items. Instead: %v", ids(groupItems, itemId))
+		}
+		if _, err := fixture.Groups.GetGroupItems("missing"); err == nil {
+			t.Errorf("Items of a missing group should not be found")
+		}
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		fixture := factory(t)
+		groupId := createGroup(t, fixture.Groups)
+		users := createUsers(t, fixture.Users, make([]int, 20)...)
+		var wg sync.WaitGroup
+		for _, id := range users {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fixture.Groups.AddUserToGroup(groupId, id)
+			}()
+		}
+		wg.Wait()
+
+		if members, _ := fixture.Groups.GetGroupUsers(groupId); !sameIds(ids(members, userId), users) {
+			t.Errorf("Every user should be added. Instead: %d", len(members))
+		}
+	})
+}
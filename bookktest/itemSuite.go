@@ -0,0 +1,141 @@
+package bookktest
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/iPy849/bookk"
+)
+
+// ItemRepositoryFactory builds an empty IItemRepository for a test, whose related items are the items of the users told by relatedUserIds.
+type ItemRepositoryFactory func(t *testing.T, relatedUserIds bookk.RelatedUserIdsFunc) bookk.IItemRepository[bookk.Item]
+
+func itemId(item *bookk.Item) string {
+	return item.Id
+}
+
+// createItems creates an item for every owner and returns their ids
+func createItems(t *testing.T, repository bookk.IItemRepository[bookk.Item], userIds ...string) []string {
+	t.Helper()
+	items := make([]*bookk.Item, len(userIds))
+	for i, userId := range userIds {
+		items[i] = &bookk.Item{BaseItem: bookk.BaseItem{UserId: userId, Name: "Item"}}
+	}
+	created, err := repository.CreateItemBatch(items)
+	if err != nil {
+		t.Fatalf("Items should be created. Instead: %v", err)
+	}
+	return ids(created, itemId)
+}
+
+/*
+RunItemRepositorySuite checks an IItemRepository behaves as bookk expects:
+
+  - Created items get an id, and batches create every item or none
+  - Missing items are an error for single operations but are skipped by batch operations
+  - Items are found by their owner and by the related users
+  - Concurrent creations do not lose items
+
+Parameters:
+  - t: The test running the suite
+  - factory: Builds an empty repository for every subtest
+*/
+func RunItemRepositorySuite(t *testing.T, factory ItemRepositoryFactory) {
+	noRelatedUsers := func() ([]string, error) { return nil, nil }
+
+	t.Run("Create and get", func(t *testing.T) {
+		repository := factory(t, noRelatedUsers)
+		created, err := repository.CreateItem(&bookk.Item{BaseItem: bookk.BaseItem{UserId: "owner", Name: "Room", Description: "Big"}})
+		if err != nil || created.Id == "" {
+			t.Fatalf("Item should be created with an id. Instead: %+v %v", created, err)
+		}
+
+		stored, err := repository.GetItem(created.Id)
+		if err != nil || stored.BaseItem != created.BaseItem {
+			t.Errorf("Created item should be found. Instead: %+v %v", stored, err)
+		}
+		if _, err := repository.GetItem("missing"); err == nil {
+			t.Errorf("Missing item should not be found")
+		}
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		repository := factory(t, noRelatedUsers)
+		created := createItems(t, repository, "owner", "owner", "owner")
+		if len(created) != 3 || created[0] == created[1] || created[1] == created[2] || created[0] == created[2] {
+			t.Fatalf("Every item should get a different id. Instead: %v", created)
+		}
+
+		items, err := repository.GetItemBatch(append(slices.Clone(created), "missing"))
+		if err != nil || !sameIds(ids(items, itemId), created) {
+			t.Errorf("Batch should skip missing items. Instead: %v %v", ids(items, itemId), err)
+		}
+
+		if err := repository.DeleteItemBatch([]string{created[0], "missing"}); err != nil {
+			t.Errorf("Batch deletion should skip missing items. Instead: %v", err)
+		}
+		items, _ = repository.GetItemBatch(created)
+		if !sameIds(ids(items, itemId), created[1:]) {
+			t.Errorf("Deleted items should not be found. Instead: %v", ids(items, itemId))
+		}
+	})
+
+	t.Run("Update and delete", func(t *testing.T) {
+		repository := factory(t, noRelatedUsers)
+		id := createItems(t, repository, "owner")[0]
+		item, _ := repository.GetItem(id)
+		item.Name = "Updated"
+		if updated, err := repository.UpdateItem(item); err != nil || updated.Name != item.Name {
+			t.Fatalf("Item should be updated. Instead: %+v %v", updated, err)
+		}
+		if stored, _ := repository.GetItem(id); stored.Name != item.Name {
+			t.Errorf("Item should be stored updated. Instead: %+v", stored)
+		}
+		if _, err := repository.UpdateItem(&bookk.Item{BaseItem: bookk.BaseItem{Id: "missing"}}); err == nil {
+			t.Errorf("Missing item should not be updated")
+		}
+
+		if err := repository.DeleteItem(id); err != nil {
+			t.Fatalf("Item should be deleted. Instead: %v", err)
+		}
+		if _, err := repository.GetItem(id); err == nil {
+			t.Errorf("Deleted item should not be found")
+		}
+		if err := repository.DeleteItem(id); err == nil {
+			t.Errorf("Deleted item should not be deleted again")
+		}
+	})
+
+	t.Run("By user", func(t *testing.T) {
+		repository := factory(t, func() ([]string, error) { return []string{"b", "c"}, nil })
+		created := createItems(t, repository, "a", "b", "a", "c")
+
+		if items, err := repository.GetItemsByUserId("a"); err != nil || !sameIds(ids(items, itemId), []string{created[0], created[2]}) {
+			t.Errorf("No expected items of the user. Instead: %v %v", ids(items, itemId), err)
+		}
+		if items, err := repository.GetItemsByUserId("missing"); err != nil || len(items) != 0 {
+			t.Errorf("User without items should have none. Instead: %v %v", ids(items, itemId), err)
+		}
+		if items, err := repository.GetRelatedUserItems(); err != nil || !sameIds(ids(items, itemId), []string{created[1], created[3]}) {
+			t.Errorf("No expected related items. Instead: %v %v", ids(items, itemId), err)
+		}
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		repository := factory(t, noRelatedUsers)
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				repository.CreateItem(&bookk.Item{BaseItem: bookk.BaseItem{UserId: "owner"}})
+			}()
+		}
+		wg.Wait()
+
+		if items, _ := repository.GetItemsByUserId("owner"); len(items) != 20 {
+			t.Errorf("Every item should be created. Instead: %d", len(items))
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package bookktest
+
+import (
+	"testing"
+
+	"github.com/iPy849/bookk"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	RunUserRepositorySuite(t, func(t *testing.T) bookk.IUserRepository[bookk.User] {
+		return bookk.NewMemoryUserRepository()
+	})
+}
+
+func TestMemoryItemRepository(t *testing.T) {
+	RunItemRepositorySuite(t, func(t *testing.T, relatedUserIds bookk.RelatedUserIdsFunc) bookk.IItemRepository[bookk.Item] {
+		return bookk.NewMemoryItemRepository(bookk.WithRelatedUserIds(relatedUserIds))
+	})
+}
+
+func TestMemoryGroupService(t *testing.T) {
+	RunGroupServiceSuite(t, func(t *testing.T) GroupFixture {
+		users, items := bookk.NewMemoryUserRepository(), bookk.NewMemoryItemRepository()
+		return GroupFixture{Groups: bookk.NewMemoryGroupService(users, items), Users: users, Items: items}
+	})
+}
+
+func TestMemoryBookingService(t *testing.T) {
+	RunBookingServiceSuite(t, func(t *testing.T, groupUsers bookk.GroupUsersFunc) bookk.IBookingService[bookk.Booking] {
+		return bookk.NewMemoryBookingService(bookk.WithGroupUsers(groupUsers))
+	})
+}
@@ -0,0 +1,177 @@
+package bookktest
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/iPy849/bookk"
+)
+
+// UserRepositoryFactory builds an empty IUserRepository for a test.
+type UserRepositoryFactory func(t *testing.T) bookk.IUserRepository[bookk.User]
+
+func userId(user *bookk.User) string {
+	return user.Id
+}
+
+// createUsers creates users with the given roles and returns their ids
+func createUsers(t *testing.T, repository bookk.IUserRepository[bookk.User], roles ...int) []string {
+	t.Helper()
+	users := make([]*bookk.User, len(roles))
+	for i, role := range roles {
+		users[i] = &bookk.User{BaseUser: bookk.BaseUser{Role: role}}
+	}
+	created, err := repository.CreateUserBatch(users)
+	if err != nil {
+		t.Fatalf("Users should be created. Instead: %v", err)
+	}
+	return created
+}
+
+/*
+RunUserRepositorySuite checks an IUserRepository behaves as bookk expects:
+
+  - Created users get an id and a creation time, and batches create every user or none
+  - Missing users are an error for single operations but are skipped by batch operations
+  - Deleted users are not returned nor accepted anymore
+  - Relations are directed, idempotent and skip deleted users
+  - Concurrent creations do not lose users
+
+Parameters:
+  - t: The test running the suite
+  - factory: Builds an empty repository for every subtest
+*/
+func RunUserRepositorySuite(t *testing.T, factory UserRepositoryFactory) {
+	t.Run("Create and get", func(t *testing.T) {
+		repository := factory(t)
+		user := &bookk.User{BaseUser: bookk.BaseUser{Email: "user@bookk.dev", Role: bookk.ROLE_ADVANCE_USER}}
+		id, err := repository.CreateUser(user)
+		if err != nil || id == "" {
+			t.Fatalf("User should be created with an id. Instead: %q %v", id, err)
+		}
+
+		stored, err := repository.GetUser(id)
+		if err != nil {
+			t.Fatalf("Created user should be found. Instead: %v", err)
+		}
+		if stored.Id != id || stored.Email != user.Email || stored.Role != user.Role || stored.CreatedAt.IsZero() {
+			t.Errorf("User should be stored with a creation time. Instead: %+v", stored)
+		}
+		if _, err := repository.GetUser("missing"); err == nil {
+			t.Errorf("Missing user should not be found")
+		}
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		repository := factory(t)
+		created := createUsers(t, repository, bookk.ROLE_USER, bookk.ROLE_USER, bookk.ROLE_USER)
+		if len(created) != 3 || created[0] == created[1] || created[1] == created[2] || created[0] == created[2] {
+			t.Fatalf("Every user should get a different id. Instead: %v", created)
+		}
+
+		users, err := repository.GetUserBatch(append(slices.Clone(created), "missing"))
+		if err != nil || !sameIds(ids(users, userId), created) {
+			t.Errorf("Batch should skip missing users. Instead: %v %v", ids(users, userId), err)
+		}
+
+		if err := repository.DeleteUserBatch([]string{created[0], "missing"}); err != nil {
+			t.Errorf("Batch deletion should skip missing users. Instead: %v", err)
+		}
+		users, _ = repository.GetUserBatch(created)
+		if !sameIds(ids(users, userId), created[1:]) {
+			t.Errorf("Deleted users should not be found. Instead: %v", ids(users, userId))
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repository := factory(t)
+		id := createUsers(t, repository, bookk.ROLE_USER)[0]
+		user, _ := repository.GetUser(id)
+		user.Email = "updated@bookk.dev"
+		if err := repository.UpdateUser(user); err != nil {
+			t.Fatalf("User should be updated. Instead: %v", err)
+		}
+		if err := repository.SetBan(id, hours(24)); err != nil {
+			t.Fatalf("User should be banned. Instead: %v", err)
+		}
+
+		stored, _ := repository.GetUser(id)
+		if stored.Email != user.Email || !stored.BannedUntil.Equal(hours(24)) {
+			t.Errorf("User should be updated and banned. Instead: %+v", stored)
+		}
+		if err := repository.UpdateUser(&bookk.User{BaseUser: bookk.BaseUser{Id: "missing"}}); err == nil {
+			t.Errorf("Missing user should not be updated")
+		}
+		if err := repository.SetBan("missing", hours(24)); err == nil {
+			t.Errorf("Missing user should not be banned")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repository := factory(t)
+		id := createUsers(t, repository, bookk.ROLE_USER)[0]
+		if err := repository.DeleteUser(id); err != nil {
+			t.Fatalf("User should be deleted. Instead: %v", err)
+		}
+		if _, err := repository.GetUser(id); err == nil {
+			t.Errorf("Deleted user should not be found")
+		}
+		if err := repository.DeleteUser(id); err == nil {
+			t.Errorf("Deleted user should not be deleted again")
+		}
+		if err := repository.DeleteUser("missing"); err == nil {
+			t.Errorf("Missing user should not be deleted")
+		}
+	})
+
+	t.Run("Relations", func(t *testing.T) {
+		repository := factory(t)
+		created := createUsers(t, repository, bookk.ROLE_USER, bookk.ROLE_ENTERPRISE, bookk.ROLE_USER)
+		for _, related := range []string{created[1], created[2], created[1]} {
+			if err := repository.RelateUsers(created[0], related); err != nil {
+				t.Fatalf("Users should be related. Instead: %v", err)
+			}
+		}
+		if err := repository.RelateUsers(created[0], "missing"); err == nil {
+			t.Errorf("User should not be related to a missing user")
+		}
+
+		if users, err := repository.GetRelatedUsers(created[0]); err != nil || !sameIds(ids(users, userId), created[1:]) {
+			t.Errorf("No expected related users. Instead: %v %v", ids(users, userId), err)
+		}
+		if users, _ := repository.GetRelatedUsers(created[1]); len(users) != 0 {
+			t.Errorf("Relations should be directed. Instead: %v", ids(users, userId))
+		}
+		if related, err := repository.GetRelatedUsersByRole(created[0], bookk.ROLE_ENTERPRISE); err != nil || !sameIds(related, created[1:2]) {
+			t.Errorf("No expected related users by role. Instead: %v %v", related, err)
+		}
+
+		if err := repository.RemoveRelation(created[0], created[1]); err != nil {
+			t.Fatalf("Relation should be removed. Instead: %v", err)
+		}
+		repository.DeleteUser(created[2])
+		if users, _ := repository.GetRelatedUsers(created[0]); len(users) != 0 {
+			t.Errorf("Removed relations and deleted users should not be related. Instead: %v", ids(users, userId))
+		}
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		repository := factory(t)
+		created := make([]string, 20)
+		var wg sync.WaitGroup
+		for i := range created {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				created[i], _ = repository.CreateUser(&bookk.User{})
+			}()
+		}
+		wg.Wait()
+
+		users, _ := repository.GetUserBatch(created)
+		if !sameIds(ids(users, userId), created) || slices.Contains(created, "") {
+			t.Errorf("Every user should be created. Instead: %v", ids(users, userId))
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package bookk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+fakeDatabase is a database/sql driver replying to the statements it expects in order.
+
+It allows testing code that uses database/sql without a database server. Transactions are
+expected as the statements BEGIN, COMMIT and ROLLBACK.
+*/
+type fakeDatabase struct {
+	t            *testing.T
+	mu           sync.Mutex
+	expectations []*fakeExpectation
+}
+
+type fakeExpectation struct {
+	query        string         // Substring the statement must contain
+	args         []driver.Value // Expected arguments, nil to accept any
+	columns      []string
+	rows         [][]driver.Value
+	rowsAffected int64
+	err          error
+	received     []driver.Value
+}
+
+func newFakeDatabase(t *testing.T) (*sql.DB, *fakeDatabase) {
+	fake := &fakeDatabase{t: t}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() {
+		db.Close()
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		for _, expectation := range fake.expectations {
+			t.Errorf("Expected statement was not executed: %s", expectation.query)
+		}
+	})
+	return db, fake
+}
+
+func (f *fakeDatabase) expect(query string, args ...driver.Value) *fakeExpectation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	expectation := &fakeExpectation{query: query, args: args}
+	f.expectations = append(f.expectations, expectation)
+	return expectation
+}
+
+func (e *fakeExpectation) willReturnRows(columns []string, rows ...[]driver.Value) *fakeExpectation {
+	e.columns = columns
+	e.rows = rows
+	return e
+}
+
+func (e *fakeExpectation) willAffect(rows int64) *fakeExpectation {
+	e.rowsAffected = rows
+	return e
+}
+
+func (e *fakeExpectation) willFail(err error) *fakeExpectation {
+	e.err = err
+	return e
+}
+
+func (f *fakeDatabase) next(query string, args []driver.NamedValue) (*fakeExpectation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.expectations) == 0 {
+		f.t.Errorf("Unexpected statement: %s", query)
+		return nil, fmt.Errorf("unexpected statement: %s", query)
+	}
+	expectation := f.expectations[0]
+	f.expectations = f.expectations[1:]
+
+	if !strings.Contains(query, expectation.query) {
+		f.t.Errorf("Unexpected statement:\nExpecting\t: %s\nRecieved\t: %s", expectation.query, query)
+		return nil, fmt.Errorf("unexpected statement: %s", query)
+	}
+
+	for _, arg := range args {
+		expectation.received = append(expectation.received, arg.Value)
+	}
+	if expectation.args != nil && !fakeArgsEqual(expectation.args, expectation.received) {
+		f.t.Errorf("Unexpected arguments for %s:\nExpecting\t: %v\nRecieved\t: %v", query, expectation.args, expectation.received)
+	}
+	return expectation, expectation.err
+}
+
+func fakeArgsEqual(expected, received []driver.Value) bool {
+	if len(expected) != len(received) {
+		return false
+	}
+	for i := range expected {
+		expectedTime, isTime := expected[i].(time.Time)
+		receivedTime, receivedIsTime := received[i].(time.Time)
+		if isTime && receivedIsTime {
+			if !expectedTime.Equal(receivedTime) {
+				return false
+			}
+		} else if !reflect.DeepEqual(expected[i], received[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *fakeDatabase) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{f}, nil
+}
+
+func (f *fakeDatabase) Driver() driver.Driver {
+	return fakeDriver{f}
+}
+
+type fakeDriver struct {
+	database *fakeDatabase
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{d.database}, nil
+}
+
+type fakeConn struct {
+	database *fakeDatabase
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c, query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	if _, err := c.database.next("BEGIN", nil); err != nil {
+		return nil, err
+	}
+	return &fakeTx{c.database}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	expectation, err := c.database.next(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: expectation.columns, rows: expectation.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	expectation, err := c.database.next(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(expectation.rowsAffected), nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, fakeNamedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, fakeNamedValues(args))
+}
+
+func fakeNamedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+type fakeTx struct {
+	database *fakeDatabase
+}
+
+func (tx *fakeTx) Commit() error {
+	_, err := tx.database.next("COMMIT", nil)
+	return err
+}
+
+func (tx *fakeTx) Rollback() error {
+	_, err := tx.database.next("ROLLBACK", nil)
+	return err
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,531 @@
+package bookk
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	fileStoreSnapshotName = "snapshot.json"
+	fileStoreLogName      = "wal.log"
+)
+
+var (
+	fileStoreClosedError  = errors.New("File store is closed")
+	fileStoreCorruptError = errors.New("File store is corrupt")
+)
+
+// fileStoreRecordKind tells which change of the state a record of the log holds
+type fileStoreRecordKind string
+
+const (
+	fileStoreUser           fileStoreRecordKind = "user"           // A user was created or changed
+	fileStoreUserRelation   fileStoreRecordKind = "userRelation"   // A user was related to another or the relation was removed
+	fileStoreItem           fileStoreRecordKind = "item"           // An item was created or changed
+	fileStoreItemDeleted    fileStoreRecordKind = "itemDeleted"    // An item was deleted
+	fileStoreGroup          fileStoreRecordKind = "group"          // A group was created or changed
+	fileStoreGroupDeleted   fileStoreRecordKind = "groupDeleted"   // A group was deleted
+	fileStoreGroupUser      fileStoreRecordKind = "groupUser"      // A user was added to or removed from a group
+	fileStoreGroupExclusion fileStoreRecordKind = "groupExclusion" // An item was excluded from a group
+	fileStoreGroupAdmin     fileStoreRecordKind = "groupAdmin"     // A user of a group was made one of its admins or stopped being one
+	fileStoreBooking        fileStoreRecordKind = "booking"        // A booking was created or changed
+	fileStoreBookingDeleted fileStoreRecordKind = "bookingDeleted" // A booking was deleted
+
+	fileStoreRecurringBooking        fileStoreRecordKind = "recurringBooking"        // A recurring booking was created or changed
+	fileStoreRecurringBookingDeleted fileStoreRecordKind = "recurringBookingDeleted" // A recurring booking was deleted
+)
+
+/*
+fileStoreEntry is a line of the write-ahead log, holding every record of a change.
+
+A change is written at once, so a crash never leaves only part of it, such as some users of a batch.
+*/
+type fileStoreEntry struct {
+	At      time.Time          `json:"at"`
+	Records []*fileStoreRecord `json:"records"`
+}
+
+/*
+fileStoreRecord holds the state of an entity after a change rather than the operation done, so
+replaying a record twice leaves the same state. It makes recovery safe when the store crashes
+after writing a snapshot but before emptying the log.
+*/
+type fileStoreRecord struct {
+	Kind    fileStoreRecordKind `json:"kind"`
+	Id      string              `json:"id,omitempty"`
+	User    *User               `json:"user,omitempty"`
+	Item    *fileStoreItemState `json:"item,omitempty"`
+	Group   *Group              `json:"group,omitempty"`
+	Booking *Booking            `json:"booking,omitempty"`
+	Link    *fileStoreLink      `json:"link,omitempty"`
+
+	RecurringBooking *fileStoreRecurringBookingState `json:"recurringBooking,omitempty"`
+}
+
+// fileStoreItemState is an Item along with its unexported fields
+type fileStoreItemState struct {
+	BaseItem
+	Price float32 `json:"price"`
+}
+
+/*
+fileStoreRecurringBookingState is a RecurringBooking along with the time zone of its StartsAt.
+
+Times are written with their offset only, but the occurrences of a series are computed in the
+time zone of its StartsAt, so they keep their time of day across daylight saving changes.
+*/
+type fileStoreRecurringBookingState struct {
+	RecurringBooking
+	Location string `json:"location,omitempty"`
+}
+
+// fileStoreLink relates two entities, such as a user to another or a group to a user
+type fileStoreLink struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Removed bool   `json:"removed,omitempty"`
+}
+
+// fileStoreSnapshot is the whole state of a FileStore
+type fileStoreSnapshot struct {
+	At              time.Time             `json:"at"`
+	Users           []*User               `json:"users"`
+	UserRelations   map[string][]string   `json:"userRelations"`
+	Items           []*fileStoreItemState `json:"items"`
+	Groups          []*Group              `json:"groups"`
+	GroupUsers      map[string][]string   `json:"groupUsers"`
+	GroupExclusions map[string][]string   `json:"groupExclusions"`
+	GroupAdmins     map[string][]string   `json:"groupAdmins"`
+	Bookings        []*Booking            `json:"bookings"`
+
+	RecurringBookings []*fileStoreRecurringBookingState `json:"recurringBookings"`
+}
+
+func newFileStoreItemState(item *Item) *fileStoreItemState {
+	return &fileStoreItemState{BaseItem: item.BaseItem, Price: item.price}
+}
+
+func (i *fileStoreItemState) item() *Item {
+	return &Item{BaseItem: i.BaseItem, price: i.Price}
+}
+
+func newFileStoreRecurringBookingState(booking *RecurringBooking) *fileStoreRecurringBookingState {
+	return &fileStoreRecurringBookingState{RecurringBooking: *cloneRecurringBooking(booking), Location: booking.StartsAt.Location().String()}
+}
+
+// recurringBooking returns the recurring booking with its StartsAt in its time zone, if it is known
+func (r *fileStoreRecurringBookingState) recurringBooking() *RecurringBooking {
+	booking := cloneRecurringBooking(&r.RecurringBooking)
+	if location, err := time.LoadLocation(r.Location); r.Location != "" && err == nil {
+		booking.StartsAt = booking.StartsAt.In(location)
+	}
+	return booking
+}
+
+// fileStoreLogFile is the file the log is appended to
+type fileStoreLogFile interface {
+	io.WriteSeeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// FileStoreOption configures a FileStore on opening.
+type FileStoreOption func(*FileStore)
+
+// WithSnapshotEvery sets after how many changes in the log a snapshot is written. Defaults to 1000; 0 or less disables automatic snapshots.
+func WithSnapshotEvery(changes int) FileStoreOption {
+	return func(s *FileStore) {
+		s.snapshotEvery = changes
+	}
+}
+
+// WithItemRepositoryOptions sets the options of the MemoryItemRepository keeping the items, such as WithRelatedUserIds.
+func WithItemRepositoryOptions(options ...MemoryItemRepositoryOption) FileStoreOption {
+	return func(s *FileStore) {
+		s.itemOptions = append(s.itemOptions, options...)
+	}
+}
+
+// WithBookingServiceOptions sets the options of the MemoryBookingService keeping the bookings. Bookings of a group are the bookings of its users unless WithGroupUsers is given.
+func WithBookingServiceOptions(options ...MemoryBookingServiceOption) FileStoreOption {
+	return func(s *FileStore) {
+		s.bookingOptions = append(s.bookingOptions, options...)
+	}
+}
+
+/*
+FileStore persists users, items, groups and bookings, recurring ones included, to a local
+directory, with no database. Slot holds are kept in memory only, as they only last a while, so
+they are lost on restart.
+
+The state is kept in memory by the memory implementations, and every change is appended to a
+write-ahead log and synced to disk before it is acknowledged. Every some changes the whole state
+is written to a snapshot and the log is emptied. Opening a directory restores the snapshot and
+replays the log, so the state survives restarts and crashes; a change torn by a crash while
+being written is discarded.
+
+Changes must go through the repositories and services returned by Users, Items, Groups and
+Bookings. If writing the log fails, the change is undone in memory by reading the state of the
+directory again; if that fails too, the store is closed so it no longer differs from the disk.
+
+A FileStore is safe for concurrent use, but a directory must be opened by a single FileStore at a time.
+*/
+type FileStore struct {
+	mu            sync.Mutex // Serializes changes, so the log keeps the order they were applied in
+	dir           string
+	log           fileStoreLogFile
+	logEntries    int
+	snapshotEvery int
+	closed        bool
+
+	itemOptions    []MemoryItemRepositoryOption
+	bookingOptions []MemoryBookingServiceOption
+
+	users    *MemoryUserRepository
+	items    *MemoryItemRepository
+	groups   *MemoryGroupService
+	bookings *MemoryBookingService
+}
+
+/*
+OpenFileStore opens the store kept in a directory, creating it if missing.
+
+Parameters:
+  - dir: The directory of the store
+  - options: Functional options such as WithSnapshotEvery or WithBookingServiceOptions
+
+Returns:
+  - A pointer to the FileStore, with the state of the directory restored
+  - An error if the directory cannot be read or written, or its files are corrupt
+*/
+func OpenFileStore(dir string, options ...FileStoreOption) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{dir: dir, snapshotEvery: 1000}
+	for _, option := range options {
+		option(s)
+	}
+	s.newState()
+
+	if err := s.restoreSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := s.replayLog(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newState creates the empty memory implementations keeping the state
+func (s *FileStore) newState() {
+	s.users = NewMemoryUserRepository()
+	s.items = NewMemoryItemRepository(s.itemOptions...)
+	s.groups = NewMemoryGroupService(s.users, s.items)
+	s.bookings = NewMemoryBookingService(append([]MemoryBookingServiceOption{WithGroupUsers(s.groups.GroupUserIds)}, s.bookingOptions...)...)
+}
+
+// reload replaces the state in memory with the one of the directory, undoing the changes not in the log. It must be called holding the lock
+func (s *FileStore) reload() error {
+	stored := &FileStore{dir: s.dir, itemOptions: s.itemOptions, bookingOptions: s.bookingOptions}
+	stored.newState()
+	if err := stored.restoreSnapshot(); err != nil {
+		return err
+	}
+	if err := stored.replayLog(); err != nil {
+		return err
+	}
+	if err := stored.log.Close(); err != nil {
+		return err
+	}
+
+	s.users.replace(stored.users)
+	s.items.replace(stored.items)
+	s.groups.replace(stored.groups)
+	s.bookings.replace(stored.bookings)
+	return nil
+}
+
+// restoreSnapshot loads the snapshot of the directory, if any
+func (s *FileStore) restoreSnapshot() error {
+	content, err := os.ReadFile(filepath.Join(s.dir, fileStoreSnapshotName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var snapshot fileStoreSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return fmt.Errorf("%w: snapshot: %w", fileStoreCorruptError, err)
+	}
+
+	for _, user := range snapshot.Users {
+		s.users.restoreUser(user)
+	}
+	for from, related := range snapshot.UserRelations {
+		for _, to := range related {
+			s.users.restoreRelation(&fileStoreLink{From: from, To: to})
+		}
+	}
+	for _, item := range snapshot.Items {
+		s.items.restoreItem(item.item())
+	}
+	for _, group := range snapshot.Groups {
+		s.groups.restoreGroup(group)
+	}
+	for groupId, userIds := range snapshot.GroupUsers {
+		for _, userId := range userIds {
+			s.groups.restoreGroupUser(&fileStoreLink{From: groupId, To: userId})
+		}
+	}
+	for groupId, itemIds := range snapshot.GroupExclusions {
+		for _, itemId := range itemIds {
+			s.groups.restoreExclusion(&fileStoreLink{From: groupId, To: itemId})
+		}
+	}
+	for groupId, userIds := range snapshot.GroupAdmins {
+		for _, userId := range userIds {
+			s.groups.restoreGroupAdmin(&fileStoreLink{From: groupId, To: userId})
+		}
+	}
+	for _, booking := range snapshot.Bookings {
+		if err := s.bookings.restoreBooking(booking); err != nil {
+			return fmt.Errorf("%w: snapshot: %w", fileStoreCorruptError, err)
+		}
+	}
+	for _, booking := range snapshot.RecurringBookings {
+		s.bookings.restoreRecurringBooking(booking.recurringBooking())
+	}
+	return nil
+}
+
+// replayLog applies the records of the log and opens it to append new ones
+func (s *FileStore) replayLog() error {
+	log, err := os.OpenFile(filepath.Join(s.dir, fileStoreLogName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(log)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				// The last change was torn by a crash while being written
+				if err := log.Truncate(offset); err != nil {
+					log.Close()
+					return err
+				}
+			}
+			break
+		} else if err != nil {
+			log.Close()
+			return err
+		}
+
+		var entry fileStoreEntry
+		err = json.Unmarshal(line, &entry)
+		for _, record := range entry.Records {
+			if err == nil {
+				err = s.apply(record)
+			}
+		}
+		if err != nil {
+			log.Close()
+			return fmt.Errorf("%w: log entry %d: %w", fileStoreCorruptError, s.logEntries+1, err)
+		}
+		offset += int64(len(line))
+		s.logEntries++
+	}
+
+	if _, err := log.Seek(offset, io.SeekStart); err != nil {
+		log.Close()
+		return err
+	}
+	s.log = log
+	return nil
+}
+
+// apply changes the state as told by a record of the log
+func (s *FileStore) apply(record *fileStoreRecord) error {
+	switch {
+	case record.Kind == fileStoreUser && record.User != nil:
+		s.users.restoreUser(record.User)
+	case record.Kind == fileStoreUserRelation && record.Link != nil:
+		s.users.restoreRelation(record.Link)
+	case record.Kind == fileStoreItem && record.Item != nil:
+		s.items.restoreItem(record.Item.item())
+	case record.Kind == fileStoreItemDeleted:
+		s.items.DeleteItemBatch([]string{record.Id})
+	case record.Kind == fileStoreGroup && record.Group != nil:
+		s.groups.restoreGroup(record.Group)
+	case record.Kind == fileStoreGroupDeleted:
+		s.groups.DeleteGroup(record.Id)
+	case record.Kind == fileStoreGroupUser && record.Link != nil:
+		s.groups.restoreGroupUser(record.Link)
+	case record.Kind == fileStoreGroupExclusion && record.Link != nil:
+		s.groups.restoreExclusion(record.Link)
+	case record.Kind == fileStoreGroupAdmin && record.Link != nil:
+		s.groups.restoreGroupAdmin(record.Link)
+	case record.Kind == fileStoreBooking && record.Booking != nil:
+		return s.bookings.restoreBooking(record.Booking)
+	case record.Kind == fileStoreBookingDeleted:
+		s.bookings.DeleteBooking(record.Id)
+	case record.Kind == fileStoreRecurringBooking && record.RecurringBooking != nil:
+		s.bookings.restoreRecurringBooking(record.RecurringBooking.recurringBooking())
+	case record.Kind == fileStoreRecurringBookingDeleted:
+		s.bookings.DeleteRecurringBooking(record.Id)
+	default:
+		return fmt.Errorf("record %q not recognized", record.Kind)
+	}
+	return nil
+}
+
+// write applies a change and appends the records it returns to the log as a single entry. If the entry cannot be written, the change is undone
+func (s *FileStore) write(change func() ([]*fileStoreRecord, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fileStoreClosedError
+	}
+	records, err := change()
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+	line, err := json.Marshal(fileStoreEntry{At: time.Now(), Records: records})
+	if err == nil {
+		err = s.append(line)
+	}
+	if err != nil {
+		// The change is not on disk, so it is undone in memory
+		if reloadErr := s.reload(); reloadErr != nil {
+			s.closed = true
+			return errors.Join(err, reloadErr, s.log.Close())
+		}
+		return err
+	}
+
+	s.logEntries++
+	if s.snapshotEvery > 0 && s.logEntries >= s.snapshotEvery {
+		return s.snapshot()
+	}
+	return nil
+}
+
+// append writes a line to the log and syncs it. If it fails, the log is truncated back to its size before the line, so no torn entry is left before later ones
+func (s *FileStore) append(line []byte) error {
+	offset, err := s.log.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = s.log.Write(append(line, '\n')); err == nil {
+		err = s.log.Sync()
+	}
+	if err == nil {
+		return nil
+	}
+
+	if truncateErr := s.log.Truncate(offset); truncateErr != nil {
+		return errors.Join(err, truncateErr)
+	}
+	if _, seekErr := s.log.Seek(offset, io.SeekStart); seekErr != nil {
+		return errors.Join(err, seekErr)
+	}
+	return err
+}
+
+/*
+Snapshot writes the whole state to the snapshot of the directory and empties the log.
+
+Returns:
+  - An error if the store is closed or the files cannot be written
+*/
+func (s *FileStore) Snapshot() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fileStoreClosedError
+	}
+	return s.snapshot()
+}
+
+// snapshot writes the snapshot and empties the log. It must be called holding the lock
+func (s *FileStore) snapshot() error {
+	snapshot := fileStoreSnapshot{At: time.Now()}
+	snapshot.Users, snapshot.UserRelations = s.users.snapshot()
+	for _, item := range s.items.snapshot() {
+		snapshot.Items = append(snapshot.Items, newFileStoreItemState(item))
+	}
+	snapshot.Groups, snapshot.GroupUsers, snapshot.GroupExclusions, snapshot.GroupAdmins = s.groups.snapshot()
+	snapshot.Bookings = s.bookings.snapshot()
+	for _, booking := range s.bookings.recurringSnapshot() {
+		snapshot.RecurringBookings = append(snapshot.RecurringBookings, newFileStoreRecurringBookingState(booking))
+	}
+
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	// The snapshot is replaced at once, so a crash leaves either the previous or the new one
+	path := filepath.Join(s.dir, fileStoreSnapshotName)
+	temporary, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = temporary.Write(content); err == nil {
+		err = temporary.Sync()
+	}
+	if closeErr := temporary.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.log.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.logEntries = 0
+	return s.log.Sync()
+}
+
+/*
+Close closes the log of the store. The state may still be read, but not changed.
+
+Returns:
+  - An error if the log cannot be closed
+*/
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.log.Close()
+}
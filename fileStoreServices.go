@@ -0,0 +1,1164 @@
+package bookk
+
+import (
+	"context"
+	"time"
+)
+
+// FileUserRepository is the IUserRepository of a FileStore.
+type FileUserRepository struct {
+	store *FileStore
+}
+
+// FileItemRepository is the IItemRepository of a FileStore.
+type FileItemRepository struct {
+	store *FileStore
+}
+
+// FileGroupService is the IGroupService of a FileStore.
+type FileGroupService struct {
+	store *FileStore
+}
+
+// FileBookingService is the IBookingService of a FileStore, which also keeps recurring bookings.
+type FileBookingService struct {
+	store *FileStore
+}
+
+var (
+	_ IUserRepository[User]            = (*FileUserRepository)(nil)
+	_ IItemRepository[Item]            = (*FileItemRepository)(nil)
+	_ IGroupService[Group, User, Item] = (*FileGroupService)(nil)
+	_ IBookingService[Booking]         = (*FileBookingService)(nil)
+	_ recurringBookingCreator          = (*FileBookingService)(nil)
+	_ SlotHoldsReader                  = (*FileBookingService)(nil)
+)
+
+/*
+Users returns the repository of the users of the store, which behaves as a MemoryUserRepository.
+
+Returns:
+  - A pointer to the FileUserRepository of the store
+*/
+func (s *FileStore) Users() *FileUserRepository {
+	return &FileUserRepository{s}
+}
+
+/*
+Items returns the repository of the items of the store, which behaves as a MemoryItemRepository.
+
+Returns:
+  - A pointer to the FileItemRepository of the store
+*/
+func (s *FileStore) Items() *FileItemRepository {
+	return &FileItemRepository{s}
+}
+
+/*
+Groups returns the service of the groups of the store, which behaves as a MemoryGroupService.
+
+Returns:
+  - A pointer to the FileGroupService of the store
+*/
+func (s *FileStore) Groups() *FileGroupService {
+	return &FileGroupService{s}
+}
+
+/*
+Bookings returns the service of the bookings of the store, which behaves as a MemoryBookingService.
+
+Returns:
+  - A pointer to the FileBookingService of the store
+*/
+func (s *FileStore) Bookings() *FileBookingService {
+	return &FileBookingService{s}
+}
+
+// userRecords returns the records of the stored state of a set of users
+func (r *FileUserRepository) userRecords(ids ...string) []*fileStoreRecord {
+	records := []*fileStoreRecord{}
+	for _, id := range ids {
+		if user, found := r.store.users.storedUser(id); found {
+			records = append(records, &fileStoreRecord{Kind: fileStoreUser, User: user})
+		}
+	}
+	return records
+}
+
+/*
+GetUser returns a user, as MemoryUserRepository does.
+
+Parameters:
+  - id: The id of the user
+
+Returns:
+  - A copy of the user
+  - An error if there is no user with the id or it was deleted
+*/
+func (r *FileUserRepository) GetUser(id string) (*User, error) {
+	return r.store.users.GetUser(id)
+}
+
+/*
+GetUserBatch returns a set of users, as MemoryUserRepository does.
+
+Parameters:
+  - ids: The ids of the users
+
+Returns:
+  - Copies of the users found
+  - An error, never returned by this implementation
+*/
+func (r *FileUserRepository) GetUserBatch(ids []string) ([]*User, error) {
+	return r.store.users.GetUserBatch(ids)
+}
+
+/*
+CreateUser stores a new user and writes it to the log.
+
+Parameters:
+  - user: The user to create. Its id is generated if empty
+
+Returns:
+  - The id of the created user
+  - An error if the id is already taken or the change cannot be written
+*/
+func (r *FileUserRepository) CreateUser(user *User) (string, error) {
+	var id string
+	err := r.store.write(func() (records []*fileStoreRecord, err error) {
+		if id, err = r.store.users.CreateUser(user); err != nil {
+			return nil, err
+		}
+		return r.userRecords(id), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+/*
+CreateUserBatch stores a set of new users and writes them to the log as a single change.
+
+Parameters:
+  - users: The users to create. Their ids are generated if empty
+
+Returns:
+  - The ids of the created users
+  - An error if any id is already taken or repeated, or the change cannot be written
+*/
+func (r *FileUserRepository) CreateUserBatch(users []*User) ([]string, error) {
+	var ids []string
+	err := r.store.write(func() (records []*fileStoreRecord, err error) {
+		if ids, err = r.store.users.CreateUserBatch(users); err != nil {
+			return nil, err
+		}
+		return r.userRecords(ids...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+/*
+UpdateUser replaces a stored user and writes it to the log.
+
+Parameters:
+  - user: The user to update, identified by its id
+
+Returns:
+  - An error if there is no user with the id or the change cannot be written
+*/
+func (r *FileUserRepository) UpdateUser(user *User) error {
+	return r.store.write(func() ([]*fileStoreRecord, error) {
+		if err := r.store.users.UpdateUser(user); err != nil {
+			return nil, err
+		}
+		return r.userRecords(user.Id), nil
+	})
+}
+
+/*
+DeleteUser soft deletes a user, as MemoryUserRepository does, and writes it to the log.
+
+Parameters:
+  - id: The id of the user
+
+Returns:
+  - An error if there is no user with the id, it was already deleted or the change cannot be written
+*/
+func (r *FileUserRepository) DeleteUser(id string) error {
+	return r.store.write(func() ([]*fileStoreRecord, error) {
+		if err := r.store.users.DeleteUser(id); err != nil {
+			return nil, err
+		}
+		return r.userRecords(id), nil
+	})
+}
+
+/*
+DeleteUserBatch soft deletes a set of users, skipping missing and deleted ones, and writes them to
+the log as a single change.
+
+Parameters:
+  - ids: The ids of the users
+
+Returns:
+  - An error if the change cannot be written
+*/
+func (r *FileUserRepository) DeleteUserBatch(ids []string) error {
+	return r.store.write(func() ([]*fileStoreRecord, error) {
+		if err := r.store.users.DeleteUserBatch(ids); err != nil {
+			return nil, err
+		}
+		return r.userRecords(ids...), nil
+	})
+}
+
+/*
+SetBan bans a user until a time and writes it to the log.
+
+Parameters:
+  - id: The id of the user
+  - banUntil: The end of the ban. A zero time lifts the ban
+
+Returns:
+  - An error if there is no user with the id or the change cannot be written
+*/
+func (r *FileUserRepository) SetBan(id string, banUntil time.Time) error {
+	return r.store.write(func() ([]*fileStoreRecord, error) {
+		if err := r.store.users.SetBan(id, banUntil); err != nil {
+			return nil, err
+		}
+		return r.userRecords(id), nil
+	})
+}
+
+/*
+GetRelatedUsers returns the users a user is related to, as MemoryUserRepository does.
+
+Parameters:
+  - id: The id of the user
+
+Returns:
+  - Copies of the related users, in the order they were related
+  - An error if there is no user with the id or it was deleted
+*/
+func (r *FileUserRepository) GetRelatedUsers(id string) ([]*User, error) {
+	return r.store.users.GetRelatedUsers(id)
+}
+
+/*
+GetRelatedUsersByRole returns the ids of the users with a role a user is related to, as
+MemoryUserRepository does.
+
+Parameters:
+  - id: The id of the user
+  - role: The role of the related users, such as ROLE_USER
+
+Returns:
+  - The ids of the related users, in the order they were related
+  - An error if there is no user with the id or it was deleted
+*/
+func (r *FileUserRepository) GetRelatedUsersByRole(id string, role int) ([]string, error) {
+	return r.store.users.GetRelatedUsersByRole(id, role)
+}
+
+/*
+RelateUsers relates a user to another and writes it to the log.
+
+Parameters:
+  - userId: The id of the user
+  - relatedUserId: The id of the related user
+
+Returns:
+  - An error if any of the users does not exist or was deleted, both are the same user or the
+    change cannot be written
+*/
+func (r *FileUserRepository) RelateUsers(userId, relatedUserId string) error {
+	return r.store.write(func() ([]*fileStoreRecord, error) {
+		if err := r.store.users.RelateUsers(userId, relatedUserId); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreUserRelation, Link: &fileStoreLink{From: userId, To: relatedUserId}}}, nil
+	})
+}
+
+/*
+RemoveRelation removes the relation of a user to another and writes it to the log.
+
+Parameters:
+  - userId: The id of the user
+  - relatedUserId: The id of the related user
+
+Returns:
+  - An error if the user is not related to the other one or the change cannot be written
+*/
+func (r *FileUserRepository) RemoveRelation(userId, relatedUserId string) error {
+	return r.store.write(func() ([]*fileStoreRecord, error) {
+		if err := r.store.users.RemoveRelation(userId, relatedUserId); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreUserRelation, Link: &fileStoreLink{From: userId, To: relatedUserId, Removed: true}}}, nil
+	})
+}
+
+// itemRecords returns the records of a set of items
+func itemRecords(items ...*Item) []*fileStoreRecord {
+	records := make([]*fileStoreRecord, len(items))
+	for i, item := range items {
+		records[i] = &fileStoreRecord{Kind: fileStoreItem, Item: newFileStoreItemState(item)}
+	}
+	return records
+}
+
+// deletedRecords returns the records of the deletion of a set of entities
+func deletedRecords(kind fileStoreRecordKind, ids ...string) []*fileStoreRecord {
+	records := make([]*fileStoreRecord, len(ids))
+	for i, id := range ids {
+		records[i] = &fileStoreRecord{Kind: kind, Id: id}
+	}
+	return records
+}
+
+/*
+GetItem returns an item, as MemoryItemRepository does.
+
+Parameters:
+  - id: The id of the item
+
+Returns:
+  - A copy of the item
+  - An error if there is no item with the id
+*/
+func (r *FileItemRepository) GetItem(id string) (*Item, error) {
+	return r.store.items.GetItem(id)
+}
+
+/*
+GetItemBatch returns a set of items, as MemoryItemRepository does.
+
+Parameters:
+  - ids: The ids of the items
+
+Returns:
+  - Copies of the items found
+  - An error, never returned by this implementation
+*/
+func (r *FileItemRepository) GetItemBatch(ids []string) ([]*Item, error) {
+	return r.store.items.GetItemBatch(ids)
+}
+
+/*
+CreateItem stores a new item and writes it to the log.
+
+Parameters:
+  - item: The item to create. Its id is generated if empty
+
+Returns:
+  - A copy of the created item
+  - An error if the id is already taken or the change cannot be written
+*/
+func (r *FileItemRepository) CreateItem(item *Item) (*Item, error) {
+	var created *Item
+	err := r.store.write(func() (records []*fileStoreRecord, err error) {
+		if created, err = r.store.items.CreateItem(item); err != nil {
+			return nil, err
+		}
+		return itemRecords(created), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+/*
+CreateItemBatch stores a set of new items and writes them to the log as a single change.
+
+Parameters:
+  - items: The items to create. Their ids are generated if empty
+
+Returns:
+  - Copies of the created items
+  - An error if any id is already taken or repeated, or the change cannot be written
+*/
+func (r *FileItemRepository) CreateItemBatch(items []*Item) ([]*Item, error) {
+	var created []*Item
+	err := r.store.write(func() (records []*fileStoreRecord, err error) {
+		if created, err = r.store.items.CreateItemBatch(items); err != nil {
+			return nil, err
+		}
+		return itemRecords(created...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+/*
+UpdateItem replaces a stored item and writes it to the log.
+
+Parameters:
+  - item: The item to update, identified by its id
+
+Returns:
+  - A copy of the updated item
+  - An error if there is no item with the id or the change cannot be written
+*/
+func (r *FileItemRepository) UpdateItem(item *Item) (*Item, error) {
+	var updated *Item
+	err := r.store.write(func() (records []*fileStoreRecord, err error) {
+		if updated, err = r.store.items.UpdateItem(item); err != nil {
+			return nil, err
+		}
+		return itemRecords(updated), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+/*
+DeleteItem removes an item and writes it to the log.
+
+Parameters:
+  - id: The id of the item
+
+Returns:
+  - An error if there is no item with the id or the change cannot be written
+*/
+func (r *FileItemRepository) DeleteItem(id string) error {
+	return r.store.write(func() ([]*fileStoreRecord, error) {
+		if err := r.store.items.DeleteItem(id); err != nil {
+			return nil, err
+		}
+		return deletedRecords(fileStoreItemDeleted, id), nil
+	})
+}
+
+/*
+DeleteItemBatch removes a set of items, skipping missing ones, and writes them to the log as a
+single change.
+
+Parameters:
+  - ids: The ids of the items
+
+Returns:
+  - An error if the change cannot be written
+*/
+func (r *FileItemRepository) DeleteItemBatch(ids []string) error {
+	return r.store.write(func() ([]*fileStoreRecord, error) {
+		if err := r.store.items.DeleteItemBatch(ids); err != nil {
+			return nil, err
+		}
+		return deletedRecords(fileStoreItemDeleted, ids...), nil
+	})
+}
+
+/*
+GetItemsByUserId returns the items owned by a user, as MemoryItemRepository does.
+
+Parameters:
+  - userId: The id of the user
+
+Returns:
+  - Copies of the items, in the order they were created
+  - An error, never returned by this implementation
+*/
+func (r *FileItemRepository) GetItemsByUserId(userId string) ([]*Item, error) {
+	return r.store.items.GetItemsByUserId(userId)
+}
+
+/*
+GetRelatedUserItems returns the items owned by the related users, which must be given with
+WithItemRepositoryOptions.
+
+Returns:
+  - Copies of the items, in the order they were created
+  - An error if the related users are not defined or cannot be read
+*/
+func (r *FileItemRepository) GetRelatedUserItems() ([]*Item, error) {
+	return r.store.items.GetRelatedUserItems()
+}
+
+/*
+GetGroupById returns a group, as MemoryGroupService does.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - A copy of the group
+  - An error if there is no group with the id
+*/
+func (s *FileGroupService) GetGroupById(groupId string) (*Group, error) {
+	return s.store.groups.GetGroupById(groupId)
+}
+
+/*
+CreateGroup stores a new group and writes it to the log.
+
+Parameters:
+  - group: The group to create. Its id is generated if empty
+
+Returns:
+  - A copy of the created group
+  - An error if the id is already taken or the change cannot be written
+*/
+func (s *FileGroupService) CreateGroup(group Group) (*Group, error) {
+	var created *Group
+	err := s.store.write(func() (records []*fileStoreRecord, err error) {
+		if created, err = s.store.groups.CreateGroup(group); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreGroup, Group: created}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+/*
+UpdateGroup replaces a stored group and writes it to the log.
+
+Parameters:
+  - group: The group to update, identified by its id
+
+Returns:
+  - An error if there is no group with the id or the change cannot be written
+*/
+func (s *FileGroupService) UpdateGroup(group *Group) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.groups.UpdateGroup(group); err != nil {
+			return nil, err
+		}
+		updated, err := s.store.groups.GetGroupById(group.Id)
+		if err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreGroup, Group: updated}}, nil
+	})
+}
+
+/*
+DeleteGroup removes a group along with its users, admins and excluded items, and writes it to the
+log.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - An error if there is no group with the id or the change cannot be written
+*/
+func (s *FileGroupService) DeleteGroup(groupId string) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.groups.DeleteGroup(groupId); err != nil {
+			return nil, err
+		}
+		return deletedRecords(fileStoreGroupDeleted, groupId), nil
+	})
+}
+
+/*
+GroupUserIds returns the ids of the users of a group, as MemoryGroupService does.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - The ids of the users, in the order they were added
+  - An error if there is no group with the id
+*/
+func (s *FileGroupService) GroupUserIds(groupId string) ([]string, error) {
+	return s.store.groups.GroupUserIds(groupId)
+}
+
+/*
+GetGroupUsers returns the users of a group, as MemoryGroupService does.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - Copies of the users of the group
+  - An error if there is no group with the id or the users cannot be read
+*/
+func (s *FileGroupService) GetGroupUsers(groupId string) ([]*User, error) {
+	return s.store.groups.GetGroupUsers(groupId)
+}
+
+/*
+AddUserToGroup adds a user to a group and writes it to the log.
+
+Parameters:
+  - groupId: The id of the group
+  - userId: The id of the user
+
+Returns:
+  - An error if there is no group with the id, the user is not in the user repository or the
+    change cannot be written
+*/
+func (s *FileGroupService) AddUserToGroup(groupId, userId string) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.groups.AddUserToGroup(groupId, userId); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreGroupUser, Link: &fileStoreLink{From: groupId, To: userId}}}, nil
+	})
+}
+
+/*
+DeleteUserFromGroup removes a user from a group, along with being one of its admins, and writes it
+to the log.
+
+Parameters:
+  - groupId: The id of the group
+  - userId: The id of the user
+
+Returns:
+  - An error if the group is not found or the user is not in it or the change cannot be written
+*/
+func (s *FileGroupService) DeleteUserFromGroup(groupId, userId string) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.groups.DeleteUserFromGroup(groupId, userId); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreGroupUser, Link: &fileStoreLink{From: groupId, To: userId, Removed: true}}}, nil
+	})
+}
+
+/*
+GetGroupItems returns the items of a group, as MemoryGroupService does.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - The items, grouped by user in the order the users were added
+  - An error if there is no group with the id or the items cannot be read
+*/
+func (s *FileGroupService) GetGroupItems(groupId string) ([]*Item, error) {
+	return s.store.groups.GetGroupItems(groupId)
+}
+
+/*
+ExcludeGroupItem excludes an item from a group and writes it to the log.
+
+Parameters:
+  - groupId: The id of the group
+  - itemId: The id of the item
+
+Returns:
+  - An error if there is no group with the id, the item is not in the item repository or the
+    change cannot be written
+*/
+func (s *FileGroupService) ExcludeGroupItem(groupId, itemId string) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.groups.ExcludeGroupItem(groupId, itemId); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreGroupExclusion, Link: &fileStoreLink{From: groupId, To: itemId}}}, nil
+	})
+}
+
+/*
+SetGroupAdmin makes a user of a group one of its admins, or stops it being one, as
+MemoryGroupService does.
+
+Parameters:
+  - groupId: The id of the group
+  - userId: The id of the user
+  - admin: Whether the user is an admin of the group
+
+Returns:
+  - An error if there is no group with the id, the user is not in the group or the change cannot be
+    written
+*/
+func (s *FileGroupService) SetGroupAdmin(groupId, userId string, admin bool) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.groups.SetGroupAdmin(groupId, userId, admin); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreGroupAdmin, Link: &fileStoreLink{From: groupId, To: userId, Removed: !admin}}}, nil
+	})
+}
+
+/*
+GroupAdminIds returns the ids of the admins of a group, as MemoryGroupService does.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - The ids of the admins, in the order they were made admins
+  - An error if there is no group with the id
+*/
+func (s *FileGroupService) GroupAdminIds(groupId string) ([]string, error) {
+	return s.store.groups.GroupAdminIds(groupId)
+}
+
+/*
+ItemAdminIds returns the ids of the admins of every group the item belongs to, as MemoryGroupService
+does. It may be given to NewApprovalRegistry.
+
+Parameters:
+  - itemId: The id of the item
+
+Returns:
+  - The ids of the admins, without repetitions, sorted
+  - An error if the items of a group cannot be read
+*/
+func (s *FileGroupService) ItemAdminIds(itemId string) ([]string, error) {
+	return s.store.groups.ItemAdminIds(itemId)
+}
+
+/*
+GetBookingById returns a booking, as MemoryBookingService does.
+
+Parameters:
+  - bookingId: The id of the booking
+
+Returns:
+  - A copy of the booking
+  - An error if there is no booking with the id
+*/
+func (s *FileBookingService) GetBookingById(bookingId string) (*Booking, error) {
+	return s.store.bookings.GetBookingById(bookingId)
+}
+
+/*
+GetLastBookingsByUserId returns the latest bookings of a user, as MemoryBookingService does.
+
+Parameters:
+  - userId: The id of the user
+  - limit: The maximum number of bookings returned. Every booking is returned if it is 0 or less
+
+Returns:
+  - Copies of the bookings, from the newest to the oldest StartsAt
+  - An error, never returned by this implementation
+*/
+func (s *FileBookingService) GetLastBookingsByUserId(userId string, limit int) ([]*Booking, error) {
+	return s.store.bookings.GetLastBookingsByUserId(userId, limit)
+}
+
+/*
+GetLastBookingsByGroupId returns the latest bookings of the users of a group, as
+MemoryBookingService does.
+
+Parameters:
+  - groupId: The id of the group
+  - limit: The maximum number of bookings returned. Every booking is returned if it is 0 or less
+
+Returns:
+  - Copies of the bookings, from the newest to the oldest StartsAt
+  - An error if the users of the group cannot be read
+*/
+func (s *FileBookingService) GetLastBookingsByGroupId(groupId string, limit int) ([]*Booking, error) {
+	return s.store.bookings.GetLastBookingsByGroupId(groupId, limit)
+}
+
+/*
+GetBookingsByTimeRangeAndUserId returns the bookings of a user sharing any instant with a time
+range, as MemoryBookingService does.
+
+Parameters:
+  - userId: The id of the user
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error, never returned by this implementation
+*/
+func (s *FileBookingService) GetBookingsByTimeRangeAndUserId(userId string, timeRange TimeRange) ([]*Booking, error) {
+	return s.store.bookings.GetBookingsByTimeRangeAndUserId(userId, timeRange)
+}
+
+/*
+GetBookingsByTimeRangeAndGroupId returns the bookings of the users of a group sharing any instant
+with a time range, as MemoryBookingService does.
+
+Parameters:
+  - groupId: The id of the group
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error if the users of the group cannot be read
+*/
+func (s *FileBookingService) GetBookingsByTimeRangeAndGroupId(groupId string, timeRange TimeRange) ([]*Booking, error) {
+	return s.store.bookings.GetBookingsByTimeRangeAndGroupId(groupId, timeRange)
+}
+
+/*
+GetBookingsByDateAndUserId returns the bookings of a user sharing any instant with a calendar day,
+as MemoryBookingService does.
+
+Parameters:
+  - userId: The id of the user
+  - date: Any time of the day, which is taken in the location of date
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error, never returned by this implementation
+*/
+func (s *FileBookingService) GetBookingsByDateAndUserId(userId string, date time.Time) ([]*Booking, error) {
+	return s.store.bookings.GetBookingsByDateAndUserId(userId, date)
+}
+
+/*
+GetBookingsByDateAndGroupId returns the bookings of the users of a group sharing any instant with a
+calendar day, as MemoryBookingService does.
+
+Parameters:
+  - groupId: The id of the group
+  - date: Any time of the day, which is taken in the location of date
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error if the users of the group cannot be read
+*/
+func (s *FileBookingService) GetBookingsByDateAndGroupId(groupId string, date time.Time) ([]*Booking, error) {
+	return s.store.bookings.GetBookingsByDateAndGroupId(groupId, date)
+}
+
+/*
+GetBookingsByTimeRangeAndItemId returns the bookings of an item sharing any instant with a time
+range, as MemoryBookingService does.
+
+Parameters:
+  - itemId: The id of the item
+  - timeRange: The time range to look for bookings in
+
+Returns:
+  - Copies of the bookings, from the oldest to the newest StartsAt
+  - An error, never returned by this implementation
+*/
+func (s *FileBookingService) GetBookingsByTimeRangeAndItemId(itemId string, timeRange TimeRange) ([]*Booking, error) {
+	return s.store.bookings.GetBookingsByTimeRangeAndItemId(itemId, timeRange)
+}
+
+/*
+CreateBooking checks and stores a new booking as MemoryBookingService does, and writes it to the
+log.
+
+Parameters:
+  - booking: The booking to create
+
+Returns:
+  - A copy of the created booking
+  - A *ConflictError if the booking collides with others, or another error if the booking is not
+    valid or the change cannot be written
+*/
+func (s *FileBookingService) CreateBooking(booking Booking) (*Booking, error) {
+	var created *Booking
+	err := s.store.write(func() (records []*fileStoreRecord, err error) {
+		if created, err = s.store.bookings.CreateBooking(booking); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreBooking, Booking: created}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+/*
+UpdateBooking checks and replaces a stored booking as MemoryBookingService does, and writes it to
+the log.
+
+Parameters:
+  - booking: The booking to update, identified by its id
+
+Returns:
+  - A *ConflictError if the booking collides with others, or another error if there is no booking
+    with the id, it is not valid or the change cannot be written
+*/
+func (s *FileBookingService) UpdateBooking(booking *Booking) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.bookings.UpdateBooking(booking); err != nil {
+			return nil, err
+		}
+		updated, err := s.store.bookings.GetBookingById(booking.Id)
+		if err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreBooking, Booking: updated}}, nil
+	})
+}
+
+/*
+TransitionBooking moves a booking to another state as MemoryBookingService does, and writes it to
+the log.
+
+Parameters:
+  - bookingId: The id of the booking
+  - state: The state to move to
+  - actorId: The id of the user changing the state, empty if it is changed by the system
+  - reason: Why the state changes, empty if there is none
+
+Returns:
+  - A copy of the booking in its new state
+  - An error if there is no booking with the id, it may not move to the state, the actor may not
+    approve it or the change cannot be written
+*/
+func (s *FileBookingService) TransitionBooking(bookingId string, state BookingState, actorId, reason string) (*Booking, error) {
+	var transitioned *Booking
+	err := s.store.write(func() (records []*fileStoreRecord, err error) {
+		if transitioned, err = s.store.bookings.TransitionBooking(bookingId, state, actorId, reason); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreBooking, Booking: transitioned}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transitioned, nil
+}
+
+/*
+DeleteBooking removes a booking and writes it to the log.
+
+Parameters:
+  - bookingId: The id of the booking
+
+Returns:
+  - An error if there is no booking with the id or the change cannot be written
+*/
+func (s *FileBookingService) DeleteBooking(bookingId string) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.bookings.DeleteBooking(bookingId); err != nil {
+			return nil, err
+		}
+		return deletedRecords(fileStoreBookingDeleted, bookingId), nil
+	})
+}
+
+/*
+GetRecurringBookingById returns a recurring booking, as MemoryBookingService does.
+
+Parameters:
+  - bookingId: The id of the recurring booking
+
+Returns:
+  - A copy of the recurring booking
+  - An error if there is no recurring booking with the id
+*/
+func (s *FileBookingService) GetRecurringBookingById(bookingId string) (*RecurringBooking, error) {
+	return s.store.bookings.GetRecurringBookingById(bookingId)
+}
+
+// recurringBookingRecords returns the records of the stored state of a recurring booking
+func (s *FileBookingService) recurringBookingRecords(bookingId string) ([]*fileStoreRecord, error) {
+	stored, err := s.store.bookings.GetRecurringBookingById(bookingId)
+	if err != nil {
+		return nil, err
+	}
+	return []*fileStoreRecord{{Kind: fileStoreRecurringBooking, RecurringBooking: newFileStoreRecurringBookingState(stored)}}, nil
+}
+
+/*
+CreateRecurringBooking checks and stores a new recurring booking as MemoryBookingService does, and
+writes it to the log.
+
+Parameters:
+  - booking: The recurring booking to create
+
+Returns:
+  - A copy of the created recurring booking
+  - A *ConflictError if any occurrence collides with other bookings of the item, or another error if
+    the recurring booking is not valid or the change cannot be written
+*/
+func (s *FileBookingService) CreateRecurringBooking(booking RecurringBooking) (*RecurringBooking, error) {
+	var created *RecurringBooking
+	err := s.store.write(func() (records []*fileStoreRecord, err error) {
+		if created, err = s.store.bookings.CreateRecurringBooking(booking); err != nil {
+			return nil, err
+		}
+		return s.recurringBookingRecords(created.Id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+/*
+UpdateRecurringBooking checks and replaces a stored recurring booking as MemoryBookingService does,
+and writes it to the log.
+
+Parameters:
+  - booking: The recurring booking to update, identified by its id
+
+Returns:
+  - A *ConflictError if any occurrence collides with other bookings of the item, or another error if
+    the recurring booking does not exist, is not valid or the change cannot be written
+*/
+func (s *FileBookingService) UpdateRecurringBooking(booking *RecurringBooking) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.bookings.UpdateRecurringBooking(booking); err != nil {
+			return nil, err
+		}
+		return s.recurringBookingRecords(booking.Id)
+	})
+}
+
+/*
+UpdateOccurrence changes a single occurrence of a recurring booking as MemoryBookingService does,
+and writes the recurring booking to the log.
+
+Parameters:
+  - bookingId: The id of the recurring booking
+  - occurrence: The occurrence as it must be, identified by its RecurrenceId
+
+Returns:
+  - A *ConflictError if the occurrence collides with other bookings of the item, or another error if
+    the recurring booking does not exist, the occurrence is not valid or the change cannot be
+    written
+*/
+func (s *FileBookingService) UpdateOccurrence(bookingId string, occurrence Occurrence) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.bookings.UpdateOccurrence(bookingId, occurrence); err != nil {
+			return nil, err
+		}
+		return s.recurringBookingRecords(bookingId)
+	})
+}
+
+/*
+DeleteRecurringBooking removes a recurring booking with all its occurrences and writes it to the
+log.
+
+Parameters:
+  - bookingId: The id of the recurring booking
+
+Returns:
+  - An error if there is no recurring booking with the id or the change cannot be written
+*/
+func (s *FileBookingService) DeleteRecurringBooking(bookingId string) error {
+	return s.store.write(func() ([]*fileStoreRecord, error) {
+		if err := s.store.bookings.DeleteRecurringBooking(bookingId); err != nil {
+			return nil, err
+		}
+		return deletedRecords(fileStoreRecurringBookingDeleted, bookingId), nil
+	})
+}
+
+/*
+GetSlotHoldsByTimeRangeAndItemId returns the holds of an item not expired sharing any instant
+with a time range, as MemoryBookingService does.
+
+Parameters:
+  - itemId: The id of the item
+  - timeRange: The time range to look for holds in
+
+Returns:
+  - Copies of the holds, without their token, from the oldest to the newest StartsAt
+  - An error, never returned by this implementation
+*/
+func (s *FileBookingService) GetSlotHoldsByTimeRangeAndItemId(itemId string, timeRange TimeRange) ([]*SlotHold, error) {
+	return s.store.bookings.GetSlotHoldsByTimeRangeAndItemId(itemId, timeRange)
+}
+
+/*
+HoldSlot reserves the time of an item for a while as MemoryBookingService does. The hold is kept
+in memory only, so it is not written to the log and is lost on restart.
+
+Parameters:
+  - userId: The id of the user holding the time
+  - itemId: The id of the item
+  - timeRange: The time held. Its bounds must be finite
+  - ttl: How long the hold lasts
+
+Returns:
+  - A copy of the hold, with its token
+  - A *ConflictError if the time collides with bookings or holds of the item, or another error
+    if the time range is not finite, the hold lasts no time or the time is outside opening hours
+*/
+func (s *FileBookingService) HoldSlot(userId, itemId string, timeRange TimeRange, ttl time.Duration) (*SlotHold, error) {
+	return s.store.bookings.HoldSlot(userId, itemId, timeRange, ttl)
+}
+
+/*
+GetSlotHold returns a hold, as MemoryBookingService does.
+
+Parameters:
+  - token: The token of the hold
+
+Returns:
+  - A copy of the hold
+  - An error if there is no hold with the token or it expired
+*/
+func (s *FileBookingService) GetSlotHold(token string) (*SlotHold, error) {
+	return s.store.bookings.GetSlotHold(token)
+}
+
+/*
+ReleaseSlotHold removes a hold before it expires, as MemoryBookingService does.
+
+Parameters:
+  - token: The token of the hold
+
+Returns:
+  - An error if there is no hold with the token or it expired
+*/
+func (s *FileBookingService) ReleaseSlotHold(token string) error {
+	return s.store.bookings.ReleaseSlotHold(token)
+}
+
+/*
+BookSlotHold turns a hold into a booking at once as MemoryBookingService does, and writes the
+booking to the log. If the booking cannot be written, it is undone and the hold is released.
+
+Parameters:
+  - token: The token of the hold
+  - booking: The booking to create
+
+Returns:
+  - A copy of the created booking
+  - An error if there is no hold with the token or it expired, the booking is not for the item
+    and time of the hold, the booking cannot be created or the change cannot be written
+*/
+func (s *FileBookingService) BookSlotHold(token string, booking Booking) (*Booking, error) {
+	var created *Booking
+	err := s.store.write(func() (records []*fileStoreRecord, err error) {
+		if created, err = s.store.bookings.BookSlotHold(token, booking); err != nil {
+			return nil, err
+		}
+		return []*fileStoreRecord{{Kind: fileStoreBooking, Booking: created}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+/*
+ExpireSlotHolds removes the holds that expired, as MemoryBookingService does.
+
+Returns:
+  - The number of holds removed
+*/
+func (s *FileBookingService) ExpireSlotHolds() int {
+	return s.store.bookings.ExpireSlotHolds()
+}
+
+/*
+ReapSlotHolds removes the holds that expired every interval until the context is done, as
+MemoryBookingService does. It blocks, so it is meant to run in its own goroutine.
+
+Parameters:
+  - ctx: The context stopping the reaper when done
+  - interval: How often expired holds are removed. It must be greater than 0
+
+Returns:
+  - An error at once if the interval is not greater than 0, or the error of the context once it is done
+*/
+func (s *FileBookingService) ReapSlotHolds(ctx context.Context, interval time.Duration) error {
+	return s.store.bookings.ReapSlotHolds(ctx, interval)
+}
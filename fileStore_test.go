@@ -0,0 +1,285 @@
+package bookk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func openTestFileStore(t *testing.T, dir string, options ...FileStoreOption) *FileStore {
+	t.Helper()
+	store, err := OpenFileStore(dir, options...)
+	if err != nil {
+		t.Fatalf("File store should be opened. Instead: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// fillTestFileStore makes a change of every kind in a store
+func fillTestFileStore(t *testing.T, store *FileStore) {
+	t.Helper()
+	users := store.Users()
+	if _, err := users.CreateUserBatch([]*User{{BaseUser{Id: "a"}}, {BaseUser{Id: "b"}}, {BaseUser{Id: "c"}}}); err != nil {
+		t.Fatalf("Users should be created. Instead: %v", err)
+	}
+	users.RelateUsers("a", "b")
+	users.RelateUsers("a", "c")
+	users.RemoveRelation("a", "c")
+	users.SetBan("b", testTime)
+	users.DeleteUser("c")
+
+	items := store.Items()
+	items.CreateItemBatch([]*Item{{BaseItem: BaseItem{Id: "x", UserId: "a"}, price: 5}, {BaseItem: BaseItem{Id: "y", UserId: "a"}}, {BaseItem: BaseItem{Id: "z", UserId: "b"}}})
+	items.UpdateItem(&Item{BaseItem: BaseItem{Id: "x", UserId: "a", Name: "Room"}, price: 10})
+	items.DeleteItem("z")
+
+	groups := store.Groups()
+	groups.CreateGroup(Group{BaseGroup: BaseGroup{Id: "g"}})
+	groups.CreateGroup(Group{BaseGroup: BaseGroup{Id: "h"}})
+	groups.AddUserToGroup("g", "a")
+	groups.AddUserToGroup("g", "b")
+	groups.SetGroupAdmin("g", "b", true)
+	groups.SetGroupAdmin("g", "a", true)
+	groups.DeleteUserFromGroup("g", "b")
+	groups.ExcludeGroupItem("g", "y")
+	groups.DeleteGroup("h")
+
+	bookings := store.Bookings()
+	for _, booking := range [3]*Booking{hoursBooking("1", "x", 0, 1), hoursBooking("2", "x", 1, 2), hoursBooking("3", "y", 0, 1)} {
+		booking.UserId = "a"
+		if _, err := bookings.CreateBooking(*booking); err != nil {
+			t.Fatalf("Booking should be created. Instead: %v", err)
+		}
+	}
+	updated := hoursBooking("2", "x", 1, 3)
+	updated.UserId = "a"
+	bookings.UpdateBooking(updated)
+	bookings.DeleteBooking("3")
+
+	for _, id := range [2]string{"weekly", "deleted"} {
+		if _, err := bookings.CreateRecurringBooking(newTestRecurringBooking(t, id, id, "FREQ=WEEKLY;COUNT=20")); err != nil {
+			t.Fatalf("Recurring booking should be created. Instead: %v", err)
+		}
+	}
+	moved := recurrenceTime(t, "2025-01-14 10:00")
+	bookings.UpdateOccurrence("weekly", Occurrence{RecurrenceId: moved, StartsAt: moved.Add(time.Hour), EndsAt: moved.Add(2 * time.Hour)})
+	bookings.DeleteRecurringBooking("deleted")
+}
+
+// checkTestFileStore checks a store holds the state left by fillTestFileStore
+func checkTestFileStore(t *testing.T, store *FileStore) {
+	t.Helper()
+	if users, _ := store.Users().GetUserBatch([]string{"a", "b", "c"}); !slices.Equal(userIds(users), []string{"a", "b"}) || !users[1].BannedUntil.Equal(testTime) {
+		t.Errorf("No expected users. Instead: %v", userIds(users))
+	}
+	if related, _ := store.Users().GetRelatedUsers("a"); !slices.Equal(userIds(related), []string{"b"}) {
+		t.Errorf("No expected related users. Instead: %v", userIds(related))
+	}
+	if _, err := store.Users().CreateUser(&User{BaseUser{Id: "c"}}); !errors.Is(err, userAlreadyExistsError) {
+		t.Errorf("Deleted user should be kept. Instead: %v", err)
+	}
+
+	if items, _ := store.Items().GetItemsByUserId("a"); !slices.Equal(itemIds(items), []string{"x", "y"}) || items[0].Name != "Room" || items[0].price != 10 {
+		t.Errorf("No expected items. Instead: %v", itemIds(items))
+	}
+	if items, _ := store.Groups().GetGroupItems("g"); !slices.Equal(itemIds(items), []string{"x"}) {
+		t.Errorf("No expected group items. Instead: %v", itemIds(items))
+	}
+	if admins, _ := store.Groups().GroupAdminIds("g"); !slices.Equal(admins, []string{"a"}) {
+		t.Errorf("No expected group admins. Instead: %v", admins)
+	}
+	if _, err := store.Groups().GetGroupById("h"); !errors.Is(err, groupNotFoundError) {
+		t.Errorf("Deleted group should not be found. Instead: %v", err)
+	}
+
+	if bookings, _ := store.Bookings().GetLastBookingsByGroupId("g", 0); !slices.Equal(bookingIds(bookings), []string{"2", "1"}) {
+		t.Errorf("No expected bookings. Instead: %v", bookingIds(bookings))
+	}
+	if _, err := store.Bookings().CreateBooking(*hoursBooking("", "x", 2, 2.5)); !errors.Is(err, bookingConflictError) {
+		t.Errorf("Restored bookings should be checked for conflicts. Instead: %v", err)
+	}
+
+	series, err := store.Bookings().GetRecurringBookingById("weekly")
+	if err != nil || len(series.Changes) != 1 {
+		t.Fatalf("No expected recurring booking. Instead: %+v %v", series, err)
+	}
+	// Occurrences after the daylight saving change keep their time of day
+	expected := recurrenceTime(t, "2025-05-20 10:00")
+	window, _ := NewTimeRange(expected, expected.Add(time.Hour), TimeRangeIlEu)
+	if occurrences, _ := series.Occurrences(*window); len(occurrences) != 1 || !occurrences[0].StartsAt.Equal(expected) {
+		t.Errorf("No expected occurrences:\nExpecting\t: %v\nRecieved\t: %v", expected, occurrences)
+	}
+	if _, err := store.Bookings().GetRecurringBookingById("deleted"); !errors.Is(err, recurringBookingNotFoundError) {
+		t.Errorf("Deleted recurring booking should not be found. Instead: %v", err)
+	}
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	t.Run("From log", func(t *testing.T) {
+		dir := t.TempDir()
+		store := openTestFileStore(t, dir, WithSnapshotEvery(0))
+		fillTestFileStore(t, store)
+		store.Close()
+
+		if _, err := os.Stat(filepath.Join(dir, fileStoreSnapshotName)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("No snapshot should be written. Instead: %v", err)
+		}
+		checkTestFileStore(t, openTestFileStore(t, dir))
+	})
+
+	t.Run("From snapshot and log", func(t *testing.T) {
+		dir := t.TempDir()
+		store := openTestFileStore(t, dir, WithSnapshotEvery(10))
+		fillTestFileStore(t, store)
+		store.Close()
+
+		log, _ := os.ReadFile(filepath.Join(dir, fileStoreLogName))
+		if store.logEntries == 0 || len(log) == 0 {
+			t.Errorf("Changes after the last snapshot should be in the log")
+		}
+		checkTestFileStore(t, openTestFileStore(t, dir))
+	})
+
+	t.Run("Snapshot without emptying the log", func(t *testing.T) {
+		dir := t.TempDir()
+		store := openTestFileStore(t, dir, WithSnapshotEvery(0))
+		fillTestFileStore(t, store)
+		log, _ := os.ReadFile(filepath.Join(dir, fileStoreLogName))
+		if err := store.Snapshot(); err != nil {
+			t.Fatalf("Snapshot should be written. Instead: %v", err)
+		}
+		store.Close()
+
+		// A crash right after writing the snapshot leaves the log replayed on top of it
+		os.WriteFile(filepath.Join(dir, fileStoreLogName), log, 0o644)
+		checkTestFileStore(t, openTestFileStore(t, dir))
+	})
+}
+
+// failingLogFile is a log file writing half of every line before failing, as a full disk would
+type failingLogFile struct {
+	fileStoreLogFile
+}
+
+func (f failingLogFile) Write(p []byte) (int, error) {
+	n, _ := f.fileStoreLogFile.Write(p[:len(p)/2])
+	return n, errors.New("Disk full")
+}
+
+func TestFileStoreRecovery(t *testing.T) {
+	t.Run("Failed write", func(t *testing.T) {
+		dir := t.TempDir()
+		store := openTestFileStore(t, dir)
+		store.Users().CreateUser(&User{BaseUser{Id: "a"}})
+
+		log := store.log
+		store.log = failingLogFile{log}
+		if id, err := store.Users().CreateUser(&User{BaseUser{Id: "b"}}); err == nil || id != "" {
+			t.Errorf("Change should fail if the log cannot be written. Instead: %q %v", id, err)
+		}
+		if created, err := store.Bookings().CreateBooking(*hoursBooking("1", "x", 0, 1)); err == nil || created != nil {
+			t.Errorf("Change should fail if the log cannot be written. Instead: %+v %v", created, err)
+		}
+		if _, err := store.Users().GetUser("b"); !errors.Is(err, userNotFoundError) {
+			t.Errorf("Failed change should be undone. Instead: %v", err)
+		}
+		if _, err := store.Bookings().GetBookingById("1"); !errors.Is(err, bookingNotFoundError) {
+			t.Errorf("Failed change should be undone. Instead: %v", err)
+		}
+		store.log = log
+		store.Users().CreateUser(&User{BaseUser{Id: "c"}})
+		store.Close()
+
+		store = openTestFileStore(t, dir)
+		if users, _ := store.Users().GetUserBatch([]string{"a", "b", "c"}); !slices.Equal(userIds(users), []string{"a", "c"}) {
+			t.Errorf("Changes around the failed one should be kept. Instead: %v", userIds(users))
+		}
+	})
+
+	t.Run("Torn change", func(t *testing.T) {
+		dir := t.TempDir()
+		store := openTestFileStore(t, dir)
+		store.Users().CreateUser(&User{BaseUser{Id: "a"}})
+		store.Close()
+
+		path := filepath.Join(dir, fileStoreLogName)
+		log, _ := os.ReadFile(path)
+		os.WriteFile(path, append(log, []byte(`{"at":"2025-01-01T00:00:00Z","records":[{"kind":"user","us`)...), 0o644)
+
+		store = openTestFileStore(t, dir)
+		if _, err := store.Users().GetUser("a"); err != nil {
+			t.Errorf("Changes before the torn one should be kept. Instead: %v", err)
+		}
+		store.Users().CreateUser(&User{BaseUser{Id: "b"}})
+		store.Close()
+
+		store = openTestFileStore(t, dir)
+		if users, _ := store.Users().GetUserBatch([]string{"a", "b"}); len(users) != 2 {
+			t.Errorf("Changes after the torn one should be kept. Instead: %v", userIds(users))
+		}
+	})
+
+	t.Run("Corrupt log", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, fileStoreLogName), []byte("{\"records\":[{\"kind\":\"unknown\"}]}\n"), 0o644)
+		if _, err := OpenFileStore(dir); !errors.Is(err, fileStoreCorruptError) {
+			t.Errorf("Corrupt log should fail. Instead: %v", err)
+		}
+	})
+
+	t.Run("Corrupt snapshot", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, fileStoreSnapshotName), []byte("{"), 0o644)
+		if _, err := OpenFileStore(dir); !errors.Is(err, fileStoreCorruptError) {
+			t.Errorf("Corrupt snapshot should fail. Instead: %v", err)
+		}
+	})
+}
+
+func TestFileStoreSlotHolds(t *testing.T) {
+	dir := t.TempDir()
+	store := openTestFileStore(t, dir)
+	hold, err := store.Bookings().HoldSlot("user", "item", *hoursRange(0, 1, TimeRangeIlEu), time.Hour)
+	if err != nil {
+		t.Fatalf("Slot should be held. Instead: %v", err)
+	}
+	if _, err := store.Bookings().CreateBooking(*hoursBooking("", "item", 0, 1)); !errors.Is(err, bookingConflictError) {
+		t.Errorf("Booking should conflict with the hold. Instead: %v", err)
+	}
+	booking, err := store.Bookings().BookSlotHold(hold.Token, Booking{})
+	if err != nil {
+		t.Fatalf("Hold should be booked. Instead: %v", err)
+	}
+	store.Bookings().HoldSlot("user", "item", *hoursRange(1, 2, TimeRangeIlEu), time.Hour)
+	store.Close()
+
+	store = openTestFileStore(t, dir)
+	if _, err := store.Bookings().GetBookingById(booking.Id); err != nil {
+		t.Errorf("Booked hold should be kept. Instead: %v", err)
+	}
+	if holds, _ := store.Bookings().GetSlotHoldsByTimeRangeAndItemId("item", *hoursRange(0, 2, TimeRangeIlEu)); len(holds) != 0 {
+		t.Errorf("Holds should not be kept on restart. Instead: %+v", holds)
+	}
+}
+
+func TestFileStoreClose(t *testing.T) {
+	store := openTestFileStore(t, t.TempDir())
+	store.Users().CreateUser(&User{BaseUser{Id: "a"}})
+	if err := store.Close(); err != nil {
+		t.Fatalf("Store should be closed. Instead: %v", err)
+	}
+
+	if _, err := store.Users().GetUser("a"); err != nil {
+		t.Errorf("Closed store should be read. Instead: %v", err)
+	}
+	if _, err := store.Users().CreateUser(&User{}); !errors.Is(err, fileStoreClosedError) {
+		t.Errorf("Closed store should not be changed. Instead: %v", err)
+	}
+	if err := store.Snapshot(); !errors.Is(err, fileStoreClosedError) {
+		t.Errorf("Closed store should not be snapshot. Instead: %v", err)
+	}
+}
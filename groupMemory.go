@@ -0,0 +1,484 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	groupNotFoundError      = errors.New("Group not found")
+	groupAlreadyExistsError = errors.New("Group already exists")
+	groupUserNotFoundError  = errors.New("User is not in group")
+)
+
+/*
+MemoryGroupService is an IGroupService keeping groups in memory.
+
+Users and items are read from their repositories. The items of a group are the items owned by
+its users, except the ones excluded from the group. Some users of a group may be its admins, who
+approve the bookings of its items, see ItemAdminIds. Every group read or written is copied, so
+callers never share a group with the service.
+
+A MemoryGroupService is safe for concurrent use.
+*/
+type MemoryGroupService struct {
+	mu       sync.RWMutex
+	groups   map[string]*Group
+	users    map[string][]string // Ids of the users of every group, in the order they were added
+	excluded map[string][]string // Ids of the items excluded from every group
+	admins   map[string][]string // Ids of the users of every group who are its admins
+	userRepo IUserRepository[User]
+	itemRepo IItemRepository[Item]
+}
+
+var _ IGroupService[Group, User, Item] = (*MemoryGroupService)(nil)
+
+/*
+NewMemoryGroupService creates an empty MemoryGroupService.
+
+Parameters:
+  - users: The repository the users of the groups are read from
+  - items: The repository the items of the groups are read from
+
+Returns:
+  - A pointer to a new MemoryGroupService
+*/
+func NewMemoryGroupService(users IUserRepository[User], items IItemRepository[Item]) *MemoryGroupService {
+	return &MemoryGroupService{
+		groups:   map[string]*Group{},
+		users:    map[string][]string{},
+		excluded: map[string][]string{},
+		admins:   map[string][]string{},
+		userRepo: users,
+		itemRepo: items,
+	}
+}
+
+func cloneGroup(group *Group) *Group {
+	clone := *group
+	return &clone
+}
+
+// group returns a stored group. It must be called holding the lock
+func (s *MemoryGroupService) group(groupId string) (*Group, error) {
+	group, found := s.groups[groupId]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", groupNotFoundError, groupId)
+	}
+	return group, nil
+}
+
+/*
+GetGroupById returns a group.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - A copy of the group
+  - An error if there is no group with the id
+*/
+func (s *MemoryGroupService) GetGroupById(groupId string) (*Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, err := s.group(groupId)
+	if err != nil {
+		return nil, err
+	}
+	return cloneGroup(group), nil
+}
+
+/*
+CreateGroup stores a new group.
+
+An id is generated when the group has none, and CreatedAt is set to the current time when it is zero.
+
+Parameters:
+  - group: The group to create
+
+Returns:
+  - A copy of the created group
+  - An error if the id is already taken
+*/
+func (s *MemoryGroupService) CreateGroup(group Group) (*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if group.Id == "" {
+		group.Id = newRandomId()
+	} else if _, found := s.groups[group.Id]; found {
+		return nil, fmt.Errorf("%w: %q", groupAlreadyExistsError, group.Id)
+	}
+	if group.CreatedAt.IsZero() {
+		group.CreatedAt = time.Now()
+	}
+	s.groups[group.Id] = &group
+	return cloneGroup(&group), nil
+}
+
+/*
+UpdateGroup replaces a stored group. The CreatedAt of the stored group is kept when the given one is zero.
+
+Parameters:
+  - group: The group to update, identified by its id
+
+Returns:
+  - An error if there is no group with the id
+*/
+func (s *MemoryGroupService) UpdateGroup(group *Group) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.group(group.Id)
+	if err != nil {
+		return err
+	}
+	updated := cloneGroup(group)
+	if updated.CreatedAt.IsZero() {
+		updated.CreatedAt = previous.CreatedAt
+	}
+	s.groups[group.Id] = updated
+	return nil
+}
+
+/*
+DeleteGroup removes a group along with its users, admins and excluded items.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - An error if there is no group with the id
+*/
+func (s *MemoryGroupService) DeleteGroup(groupId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.group(groupId); err != nil {
+		return err
+	}
+	delete(s.groups, groupId)
+	delete(s.users, groupId)
+	delete(s.excluded, groupId)
+	delete(s.admins, groupId)
+	return nil
+}
+
+/*
+GroupUserIds returns the ids of the users of a group. It may be given to WithGroupUsers, so a
+MemoryBookingService finds the bookings of the groups of this service.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - The ids of the users, in the order they were added
+  - An error if there is no group with the id
+*/
+func (s *MemoryGroupService) GroupUserIds(groupId string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.group(groupId); err != nil {
+		return nil, err
+	}
+	return slices.Clone(s.users[groupId]), nil
+}
+
+/*
+GetGroupUsers returns the users of a group. Users deleted from the user repository are skipped.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - The users, in the order they were added
+  - An error if there is no group with the id or the users cannot be read
+*/
+func (s *MemoryGroupService) GetGroupUsers(groupId string) ([]*User, error) {
+	userIds, err := s.GroupUserIds(groupId)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetUserBatch(userIds)
+}
+
+/*
+AddUserToGroup adds a user to a group. Adding a user already in the group does nothing.
+
+Parameters:
+  - groupId: The id of the group
+  - userId: The id of the user
+
+Returns:
+  - An error if there is no group with the id or the user is not in the user repository
+*/
+func (s *MemoryGroupService) AddUserToGroup(groupId, userId string) error {
+	if _, err := s.userRepo.GetUser(userId); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.group(groupId); err != nil {
+		return err
+	}
+	if !slices.Contains(s.users[groupId], userId) {
+		s.users[groupId] = append(s.users[groupId], userId)
+	}
+	return nil
+}
+
+/*
+DeleteUserFromGroup removes a user from a group, who stops being one of its admins.
+
+Parameters:
+  - groupId: The id of the group
+  - userId: The id of the user
+
+Returns:
+  - An error if there is no group with the id or the user is not in the group
+*/
+func (s *MemoryGroupService) DeleteUserFromGroup(groupId, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.group(groupId); err != nil {
+		return err
+	}
+	index := slices.Index(s.users[groupId], userId)
+	if index < 0 {
+		return fmt.Errorf("%w: %q in %q", groupUserNotFoundError, userId, groupId)
+	}
+	s.users[groupId] = slices.Delete(s.users[groupId], index, index+1)
+	if index := slices.Index(s.admins[groupId], userId); index >= 0 {
+		s.admins[groupId] = slices.Delete(s.admins[groupId], index, index+1)
+	}
+	return nil
+}
+
+/*
+SetGroupAdmin makes a user of a group one of its admins, or stops it being one.
+
+Parameters:
+  - groupId: The id of the group
+  - userId: The id of the user
+  - admin: Whether the user is an admin of the group
+
+Returns:
+  - An error if there is no group with the id or the user is not in the group
+*/
+func (s *MemoryGroupService) SetGroupAdmin(groupId, userId string, admin bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.group(groupId); err != nil {
+		return err
+	}
+	if !slices.Contains(s.users[groupId], userId) {
+		return fmt.Errorf("%w: %q in %q", groupUserNotFoundError, userId, groupId)
+	}
+	index := slices.Index(s.admins[groupId], userId)
+	if admin && index < 0 {
+		s.admins[groupId] = append(s.admins[groupId], userId)
+	} else if !admin && index >= 0 {
+		s.admins[groupId] = slices.Delete(s.admins[groupId], index, index+1)
+	}
+	return nil
+}
+
+/*
+GroupAdminIds returns the ids of the admins of a group.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - The ids of the admins, in the order they were made admins
+  - An error if there is no group with the id
+*/
+func (s *MemoryGroupService) GroupAdminIds(groupId string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.group(groupId); err != nil {
+		return nil, err
+	}
+	return slices.Clone(s.admins[groupId]), nil
+}
+
+/*
+ItemAdminIds returns the ids of the admins of every group the item belongs to. It may be given
+to NewApprovalRegistry, so group admins approve the bookings of the items of their groups.
+
+Parameters:
+  - itemId: The id of the item
+
+Returns:
+  - The ids of the admins, without repetitions, sorted
+  - An error if the items of a group cannot be read
+*/
+func (s *MemoryGroupService) ItemAdminIds(itemId string) ([]string, error) {
+	s.mu.RLock()
+	admins := map[string][]string{}
+	for groupId, userIds := range s.admins {
+		if len(userIds) > 0 {
+			admins[groupId] = slices.Clone(userIds)
+		}
+	}
+	s.mu.RUnlock()
+
+	adminIds := []string{}
+	for groupId, userIds := range admins {
+		items, err := s.GetGroupItems(groupId)
+		if errors.Is(err, groupNotFoundError) {
+			// The group was deleted meanwhile
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(items, func(item *Item) bool { return item.Id == itemId }) {
+			adminIds = append(adminIds, userIds...)
+		}
+	}
+	slices.Sort(adminIds)
+	return slices.Compact(adminIds), nil
+}
+
+/*
+GetGroupItems returns the items owned by the users of a group, except the ones excluded from it.
+Items of users deleted from the user repository are skipped.
+
+Parameters:
+  - groupId: The id of the group
+
+Returns:
+  - The items, grouped by user in the order the users were added
+  - An error if there is no group with the id or the items cannot be read
+*/
+func (s *MemoryGroupService) GetGroupItems(groupId string) ([]*Item, error) {
+	users, err := s.GetGroupUsers(groupId)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	excluded := slices.Clone(s.excluded[groupId])
+	s.mu.RUnlock()
+
+	items := []*Item{}
+	for _, user := range users {
+		userItems, err := s.itemRepo.GetItemsByUserId(user.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range userItems {
+			if !slices.Contains(excluded, item.Id) {
+				items = append(items, item)
+			}
+		}
+	}
+	return items, nil
+}
+
+/*
+ExcludeGroupItem excludes an item from the items of a group. Excluding an item already excluded does nothing.
+
+Parameters:
+  - groupId: The id of the group
+  - itemId: The id of the item
+
+Returns:
+  - An error if there is no group with the id or the item is not in the item repository
+*/
+func (s *MemoryGroupService) ExcludeGroupItem(groupId, itemId string) error {
+	if _, err := s.itemRepo.GetItem(itemId); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.group(groupId); err != nil {
+		return err
+	}
+	if !slices.Contains(s.excluded[groupId], itemId) {
+		s.excluded[groupId] = append(s.excluded[groupId], itemId)
+	}
+	return nil
+}
+
+// restoreGroup stores a group as it is
+func (s *MemoryGroupService) restoreGroup(group *Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[group.Id] = cloneGroup(group)
+}
+
+// restoreGroupUser adds a user to a group, or removes it along with being an admin, without checking the user
+func (s *MemoryGroupService) restoreGroupUser(link *fileStoreLink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := slices.DeleteFunc(s.users[link.From], func(id string) bool { return id == link.To })
+	if !link.Removed {
+		users = append(users, link.To)
+	} else {
+		s.admins[link.From] = slices.DeleteFunc(s.admins[link.From], func(id string) bool { return id == link.To })
+	}
+	s.users[link.From] = users
+}
+
+// restoreGroupAdmin makes a user an admin of a group, or stops it being one, without checking the user
+func (s *MemoryGroupService) restoreGroupAdmin(link *fileStoreLink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	admins := slices.DeleteFunc(s.admins[link.From], func(id string) bool { return id == link.To })
+	if !link.Removed {
+		admins = append(admins, link.To)
+	}
+	s.admins[link.From] = admins
+}
+
+// restoreExclusion excludes an item from a group without checking the item
+func (s *MemoryGroupService) restoreExclusion(link *fileStoreLink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.excluded[link.From], link.To) {
+		s.excluded[link.From] = append(s.excluded[link.From], link.To)
+	}
+}
+
+// replace takes the groups of another service, with their users, excluded items and admins. The other service must not be used anymore
+func (s *MemoryGroupService) replace(other *MemoryGroupService) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups, s.users, s.excluded, s.admins = other.groups, other.users, other.excluded, other.admins
+}
+
+// snapshot copies every group, sorted by id, with their users, excluded items and admins
+func (s *MemoryGroupService) snapshot() ([]*Group, map[string][]string, map[string][]string, map[string][]string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	groups := []*Group{}
+	for _, id := range slices.Sorted(maps.Keys(s.groups)) {
+		groups = append(groups, cloneGroup(s.groups[id]))
+	}
+	users, excluded, admins := map[string][]string{}, map[string][]string{}, map[string][]string{}
+	for id := range s.groups {
+		if len(s.users[id]) > 0 {
+			users[id] = slices.Clone(s.users[id])
+		}
+		if len(s.excluded[id]) > 0 {
+			excluded[id] = slices.Clone(s.excluded[id])
+		}
+		if len(s.admins[id]) > 0 {
+			admins[id] = slices.Clone(s.admins[id])
+		}
+	}
+	return groups, users, excluded, admins
+}
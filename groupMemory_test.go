@@ -0,0 +1,127 @@
+package bookk
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// newTestGroupService creates a service with the test users and items and an empty group g
+func newTestGroupService(t *testing.T) *MemoryGroupService {
+	service := NewMemoryGroupService(newTestUserRepository(t), newTestItemRepository(t))
+	if _, err := service.CreateGroup(Group{BaseGroup: BaseGroup{Id: "g"}}); err != nil {
+		t.Fatalf("Group should be created. Instead: %v", err)
+	}
+	return service
+}
+
+func TestMemoryGroupServiceGroups(t *testing.T) {
+	service := newTestGroupService(t)
+
+	created, err := service.CreateGroup(Group{BaseGroup: BaseGroup{Name: "Team"}})
+	if err != nil || created.Id == "" || created.CreatedAt.IsZero() {
+		t.Fatalf("Group should be created with id and creation time. Instead: %v %v", created, err)
+	}
+	if _, err := service.CreateGroup(Group{BaseGroup: BaseGroup{Id: "g"}}); !errors.Is(err, groupAlreadyExistsError) {
+		t.Errorf("Group with an existing id should fail. Instead: %v", err)
+	}
+
+	if err := service.UpdateGroup(&Group{BaseGroup: BaseGroup{Id: created.Id, Name: "Crew"}}); err != nil {
+		t.Fatalf("Group should be updated. Instead: %v", err)
+	}
+	if group, _ := service.GetGroupById(created.Id); group.Name != "Crew" || !group.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Group should be updated keeping its creation time. Instead: %v", group)
+	}
+
+	if err := service.DeleteGroup(created.Id); err != nil {
+		t.Fatalf("Group should be deleted. Instead: %v", err)
+	}
+	if _, err := service.GetGroupById(created.Id); !errors.Is(err, groupNotFoundError) {
+		t.Errorf("Deleted group should not be found. Instead: %v", err)
+	}
+}
+
+func TestMemoryGroupServiceMembership(t *testing.T) {
+	service := newTestGroupService(t)
+	for _, userId := range [3]string{"b", "a", "b"} {
+		if err := service.AddUserToGroup("g", userId); err != nil {
+			t.Fatalf("User %q should be added. Instead: %v", userId, err)
+		}
+	}
+	if err := service.AddUserToGroup("g", "missing"); !errors.Is(err, userNotFoundError) {
+		t.Errorf("Missing user should not be added. Instead: %v", err)
+	}
+	if err := service.AddUserToGroup("missing", "a"); !errors.Is(err, groupNotFoundError) {
+		t.Errorf("User should not be added to a missing group. Instead: %v", err)
+	}
+
+	if users, _ := service.GetGroupUsers("g"); !slices.Equal(userIds(users), []string{"b", "a"}) {
+		t.Errorf("No expected group users. Instead: %v", userIds(users))
+	}
+	if items, _ := service.GetGroupItems("g"); !slices.Equal(itemIds(items), []string{"z", "x", "y"}) {
+		t.Errorf("No expected group items. Instead: %v", itemIds(items))
+	}
+
+	if err := service.ExcludeGroupItem("g", "x"); err != nil {
+		t.Fatalf("Item should be excluded. Instead: %v", err)
+	}
+	if err := service.ExcludeGroupItem("g", "missing"); !errors.Is(err, itemNotFoundError) {
+		t.Errorf("Missing item should not be excluded. Instead: %v", err)
+	}
+	if err := service.DeleteUserFromGroup("g", "b"); err != nil {
+		t.Fatalf("User should be removed. Instead: %v", err)
+	}
+	if err := service.DeleteUserFromGroup("g", "b"); !errors.Is(err, groupUserNotFoundError) {
+		t.Errorf("Removing a user not in the group should fail. Instead: %v", err)
+	}
+	if items, _ := service.GetGroupItems("g"); !slices.Equal(itemIds(items), []string{"y"}) {
+		t.Errorf("No expected group items. Instead: %v", itemIds(items))
+	}
+}
+
+func TestMemoryGroupServiceBookings(t *testing.T) {
+	groups := newTestGroupService(t)
+	groups.AddUserToGroup("g", "a")
+	bookings := NewMemoryBookingService(WithGroupUsers(groups.GroupUserIds))
+
+	for _, booking := range [2]*Booking{hoursBooking("1", "x", 0, 1), hoursBooking("2", "z", 0, 1)} {
+		booking.UserId = map[string]string{"x": "a", "z": "b"}[booking.ItemId]
+		bookings.CreateBooking(*booking)
+	}
+	if found, _ := bookings.GetLastBookingsByGroupId("g", 0); !slices.Equal(bookingIds(found), []string{"1"}) {
+		t.Errorf("Only the bookings of group users should be found. Instead: %v", bookingIds(found))
+	}
+	if _, err := bookings.GetLastBookingsByGroupId("missing", 0); !errors.Is(err, groupNotFoundError) {
+		t.Errorf("Bookings of a missing group should fail. Instead: %v", err)
+	}
+}
+
+func TestMemoryGroupServiceAdmins(t *testing.T) {
+	service := newTestGroupService(t)
+	service.AddUserToGroup("g", "a")
+	service.AddUserToGroup("g", "b")
+
+	if err := service.SetGroupAdmin("g", "c", true); !errors.Is(err, groupUserNotFoundError) {
+		t.Errorf("User not in the group should not be an admin. Instead: %v", err)
+	}
+	for _, userId := range [3]string{"b", "a", "b"} {
+		if err := service.SetGroupAdmin("g", userId, true); err != nil {
+			t.Fatalf("User %q should be an admin. Instead: %v", userId, err)
+		}
+	}
+	if admins, _ := service.GroupAdminIds("g"); !slices.Equal(admins, []string{"b", "a"}) {
+		t.Errorf("No expected admins. Instead: %v", admins)
+	}
+	if admins, err := service.ItemAdminIds("z"); err != nil || !slices.Equal(admins, []string{"a", "b"}) {
+		t.Errorf("No expected admins of the item. Instead: %v %v", admins, err)
+	}
+
+	service.SetGroupAdmin("g", "b", false)
+	service.DeleteUserFromGroup("g", "a")
+	if admins, _ := service.GroupAdminIds("g"); len(admins) != 0 {
+		t.Errorf("Users removed from the group should not be admins. Instead: %v", admins)
+	}
+	if admins, _ := service.ItemAdminIds("z"); len(admins) != 0 {
+		t.Errorf("Item should have no admins. Instead: %v", admins)
+	}
+}
@@ -0,0 +1,326 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	itemNotFoundError               = errors.New("Item not found")
+	itemAlreadyExistsError          = errors.New("Item already exists")
+	itemRelatedUsersNotDefinedError = errors.New("Related users are not defined for the item repository")
+)
+
+// RelatedUserIdsFunc returns the ids of the users whose items are related items.
+type RelatedUserIdsFunc func() ([]string, error)
+
+// MemoryItemRepositoryOption configures a MemoryItemRepository on creation.
+type MemoryItemRepositoryOption func(*MemoryItemRepository)
+
+// WithRelatedUserIds sets where the users of related items are read from. Without it, GetRelatedUserItems fails.
+func WithRelatedUserIds(relatedUserIds RelatedUserIdsFunc) MemoryItemRepositoryOption {
+	return func(r *MemoryItemRepository) {
+		r.relatedUserIds = relatedUserIds
+	}
+}
+
+/*
+MemoryItemRepository is an IItemRepository keeping items in memory.
+
+Every item read or written is copied, so callers never share an item with the repository.
+
+A MemoryItemRepository is safe for concurrent use.
+*/
+type MemoryItemRepository struct {
+	mu             sync.RWMutex
+	items          map[string]*Item
+	order          []string // Ids of the items in the order they were created
+	relatedUserIds RelatedUserIdsFunc
+}
+
+var _ IItemRepository[Item] = (*MemoryItemRepository)(nil)
+
+/*
+NewMemoryItemRepository creates an empty MemoryItemRepository.
+
+Parameters:
+  - options: Functional options such as WithRelatedUserIds
+
+Returns:
+  - A pointer to a new MemoryItemRepository
+*/
+func NewMemoryItemRepository(options ...MemoryItemRepositoryOption) *MemoryItemRepository {
+	r := &MemoryItemRepository{items: map[string]*Item{}}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+func cloneItem(item *Item) *Item {
+	clone := *item
+	return &clone
+}
+
+/*
+GetItem returns an item.
+
+Parameters:
+  - id: The id of the item
+
+Returns:
+  - A copy of the item
+  - An error if there is no item with the id
+*/
+func (r *MemoryItemRepository) GetItem(id string) (*Item, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	item, found := r.items[id]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", itemNotFoundError, id)
+	}
+	return cloneItem(item), nil
+}
+
+/*
+GetItemBatch returns a set of items. Missing items are skipped.
+
+Parameters:
+  - ids: The ids of the items
+
+Returns:
+  - Copies of the items found, in the order of ids
+  - An error, never returned by this implementation
+*/
+func (r *MemoryItemRepository) GetItemBatch(ids []string) ([]*Item, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := []*Item{}
+	for _, id := range ids {
+		if item, found := r.items[id]; found {
+			items = append(items, cloneItem(item))
+		}
+	}
+	return items, nil
+}
+
+// prepareItem copies a new item and fills its id. It must be called holding the lock
+func (r *MemoryItemRepository) prepareItem(item *Item) (*Item, error) {
+	prepared := cloneItem(item)
+	if prepared.Id == "" {
+		prepared.Id = newRandomId()
+	} else if _, found := r.items[prepared.Id]; found {
+		return nil, fmt.Errorf("%w: %q", itemAlreadyExistsError, prepared.Id)
+	}
+	return prepared, nil
+}
+
+// store saves a new item. It must be called holding the lock
+func (r *MemoryItemRepository) store(item *Item) {
+	r.items[item.Id] = item
+	r.order = append(r.order, item.Id)
+}
+
+/*
+CreateItem stores a new item. An id is generated when the item has none.
+
+Parameters:
+  - item: The item to create
+
+Returns:
+  - A copy of the created item
+  - An error if the id is already taken
+*/
+func (r *MemoryItemRepository) CreateItem(item *Item) (*Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prepared, err := r.prepareItem(item)
+	if err != nil {
+		return nil, err
+	}
+	r.store(prepared)
+	return cloneItem(prepared), nil
+}
+
+/*
+CreateItemBatch stores a set of new items as CreateItem does. Either every item is created or none is.
+
+Parameters:
+  - items: The items to create
+
+Returns:
+  - Copies of the created items, in the order given
+  - An error if any id is already taken or repeated
+*/
+func (r *MemoryItemRepository) CreateItemBatch(items []*Item) ([]*Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prepared := make([]*Item, len(items))
+	ids := make([]string, len(items))
+	for i, item := range items {
+		var err error
+		if prepared[i], err = r.prepareItem(item); err != nil {
+			return nil, err
+		} else if slices.Contains(ids[:i], prepared[i].Id) {
+			return nil, fmt.Errorf("%w: %q", itemAlreadyExistsError, prepared[i].Id)
+		}
+		ids[i] = prepared[i].Id
+	}
+
+	created := make([]*Item, len(prepared))
+	for i, item := range prepared {
+		r.store(item)
+		created[i] = cloneItem(item)
+	}
+	return created, nil
+}
+
+/*
+UpdateItem replaces a stored item.
+
+Parameters:
+  - item: The item to update, identified by its id
+
+Returns:
+  - A copy of the updated item
+  - An error if there is no item with the id
+*/
+func (r *MemoryItemRepository) UpdateItem(item *Item) (*Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, found := r.items[item.Id]; !found {
+		return nil, fmt.Errorf("%w: %q", itemNotFoundError, item.Id)
+	}
+	r.items[item.Id] = cloneItem(item)
+	return cloneItem(item), nil
+}
+
+// delete removes an item. It must be called holding the lock
+func (r *MemoryItemRepository) delete(id string) bool {
+	if _, found := r.items[id]; !found {
+		return false
+	}
+	delete(r.items, id)
+	r.order = slices.DeleteFunc(r.order, func(current string) bool { return current == id })
+	return true
+}
+
+/*
+DeleteItem removes an item.
+
+Parameters:
+  - id: The id of the item
+
+Returns:
+  - An error if there is no item with the id
+*/
+func (r *MemoryItemRepository) DeleteItem(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.delete(id) {
+		return fmt.Errorf("%w: %q", itemNotFoundError, id)
+	}
+	return nil
+}
+
+/*
+DeleteItemBatch removes a set of items. Missing items are skipped.
+
+Parameters:
+  - ids: The ids of the items
+
+Returns:
+  - An error, never returned by this implementation
+*/
+func (r *MemoryItemRepository) DeleteItemBatch(ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		r.delete(id)
+	}
+	return nil
+}
+
+// itemsByUserIds copies the items owned by a set of users, in the order they were created
+func (r *MemoryItemRepository) itemsByUserIds(userIds []string) []*Item {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := []*Item{}
+	for _, id := range r.order {
+		if item := r.items[id]; slices.Contains(userIds, item.UserId) {
+			items = append(items, cloneItem(item))
+		}
+	}
+	return items
+}
+
+/*
+GetItemsByUserId returns the items owned by a user.
+
+Parameters:
+  - userId: The id of the user
+
+Returns:
+  - Copies of the items, in the order they were created
+  - An error, never returned by this implementation
+*/
+func (r *MemoryItemRepository) GetItemsByUserId(userId string) ([]*Item, error) {
+	return r.itemsByUserIds([]string{userId}), nil
+}
+
+/*
+GetRelatedUserItems returns the items owned by the related users, as told by WithRelatedUserIds.
+
+Returns:
+  - Copies of the items, in the order they were created
+  - An error if the related users are not defined or cannot be read
+*/
+func (r *MemoryItemRepository) GetRelatedUserItems() ([]*Item, error) {
+	if r.relatedUserIds == nil {
+		return nil, itemRelatedUsersNotDefinedError
+	}
+	userIds, err := r.relatedUserIds()
+	if err != nil {
+		return nil, err
+	}
+	return r.itemsByUserIds(userIds), nil
+}
+
+// restoreItem stores an item as it is, keeping its position if it already exists
+func (r *MemoryItemRepository) restoreItem(item *Item) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, found := r.items[item.Id]; found {
+		r.items[item.Id] = cloneItem(item)
+	} else {
+		r.store(cloneItem(item))
+	}
+}
+
+// replace takes the items of another repository, which must not be used anymore
+func (r *MemoryItemRepository) replace(other *MemoryItemRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items, r.order = other.items, other.order
+}
+
+// snapshot copies every item in the order they were created
+func (r *MemoryItemRepository) snapshot() []*Item {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	items := []*Item{}
+	for _, id := range r.order {
+		items = append(items, cloneItem(r.items[id]))
+	}
+	return items
+}
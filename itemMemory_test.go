@@ -0,0 +1,91 @@
+package bookk
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func itemIds(items []*Item) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Id
+	}
+	return ids
+}
+
+// newTestItemRepository creates a repository with items x and y of user a and z of user b
+func newTestItemRepository(t *testing.T, options ...MemoryItemRepositoryOption) *MemoryItemRepository {
+	repository := NewMemoryItemRepository(options...)
+	_, err := repository.CreateItemBatch([]*Item{
+		{BaseItem: BaseItem{Id: "x", UserId: "a"}},
+		{BaseItem: BaseItem{Id: "y", UserId: "a"}},
+		{BaseItem: BaseItem{Id: "z", UserId: "b"}},
+	})
+	if err != nil {
+		t.Fatalf("Items should be created. Instead: %v", err)
+	}
+	return repository
+}
+
+func TestMemoryItemRepositoryCreate(t *testing.T) {
+	repository := newTestItemRepository(t)
+
+	created, err := repository.CreateItem(&Item{BaseItem: BaseItem{Name: "Room"}, price: 10})
+	if err != nil || created.Id == "" {
+		t.Fatalf("Item should be created with an id. Instead: %v %v", created, err)
+	}
+	if stored, _ := repository.GetItem(created.Id); stored.Name != "Room" || stored.price != 10 {
+		t.Errorf("Item should be stored. Instead: %v", stored)
+	}
+
+	if _, err := repository.CreateItem(&Item{BaseItem: BaseItem{Id: "x"}}); !errors.Is(err, itemAlreadyExistsError) {
+		t.Errorf("Item with an existing id should fail. Instead: %v", err)
+	}
+	if _, err := repository.CreateItemBatch([]*Item{{BaseItem: BaseItem{Id: "w"}}, {BaseItem: BaseItem{Id: "x"}}}); !errors.Is(err, itemAlreadyExistsError) {
+		t.Errorf("Batch with an existing id should fail. Instead: %v", err)
+	}
+	if _, err := repository.GetItem("w"); !errors.Is(err, itemNotFoundError) {
+		t.Errorf("No item of a failed batch should be created. Instead: %v", err)
+	}
+}
+
+func TestMemoryItemRepositoryUpdateAndDelete(t *testing.T) {
+	repository := newTestItemRepository(t)
+
+	if updated, err := repository.UpdateItem(&Item{BaseItem: BaseItem{Id: "x", UserId: "b"}}); err != nil || updated.UserId != "b" {
+		t.Fatalf("Item should be updated. Instead: %v %v", updated, err)
+	}
+	if _, err := repository.UpdateItem(&Item{BaseItem: BaseItem{Id: "missing"}}); !errors.Is(err, itemNotFoundError) {
+		t.Errorf("Missing item should not be updated. Instead: %v", err)
+	}
+	if items, _ := repository.GetItemsByUserId("b"); !slices.Equal(itemIds(items), []string{"x", "z"}) {
+		t.Errorf("No expected items of user b. Instead: %v", itemIds(items))
+	}
+
+	if err := repository.DeleteItem("x"); err != nil {
+		t.Fatalf("Item should be deleted. Instead: %v", err)
+	}
+	if err := repository.DeleteItem("x"); !errors.Is(err, itemNotFoundError) {
+		t.Errorf("Deleting a missing item should fail. Instead: %v", err)
+	}
+	if err := repository.DeleteItemBatch([]string{"y", "missing"}); err != nil {
+		t.Errorf("Batch deletion should skip missing items. Instead: %v", err)
+	}
+	if items, _ := repository.GetItemBatch([]string{"x", "y", "z"}); !slices.Equal(itemIds(items), []string{"z"}) {
+		t.Errorf("Only item z should remain. Instead: %v", itemIds(items))
+	}
+}
+
+func TestMemoryItemRepositoryRelatedUserItems(t *testing.T) {
+	if _, err := newTestItemRepository(t).GetRelatedUserItems(); !errors.Is(err, itemRelatedUsersNotDefinedError) {
+		t.Errorf("Related items without related users should fail. Instead: %v", err)
+	}
+
+	repository := newTestItemRepository(t, WithRelatedUserIds(func() ([]string, error) {
+		return []string{"b"}, nil
+	}))
+	if items, _ := repository.GetRelatedUserItems(); !slices.Equal(itemIds(items), []string{"z"}) {
+		t.Errorf("No expected related items. Instead: %v", itemIds(items))
+	}
+}
@@ -0,0 +1,222 @@
+package bookk
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var (
+	migrationFileError    = errors.New("Migration file not recognized")
+	migrationMissingError = errors.New("Migration is missing")
+	migrationVersionError = errors.New("Migration version not found")
+	migrationFailedError  = errors.New("Migration failed")
+)
+
+// migrationFileName matches the files of the migrations: <version>_<name>.<up|down>.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned change of the PostgreSQL schema of bookk.
+type Migration struct {
+	Version int    // Sequential number of the migration, starting at 1
+	Name    string // Short description of the migration
+	Up      string // Statements applying the migration
+	Down    string // Statements reverting the migration
+}
+
+/*
+Migrations returns the migrations of the PostgreSQL schema of bookk, embedded in the package.
+
+The schema holds the users, items, groups and bookings tables, along with user relations,
+group members and group item exclusions, as PostgresBookingService expects them.
+
+Returns:
+  - The migrations, sorted by version
+  - An error if a migration file is not recognized or misses its up or down statements
+*/
+func Migrations() ([]Migration, error) {
+	return readMigrations(migrationFiles, "migrations")
+}
+
+// readMigrations reads the migrations in a directory of a file system
+func readMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %q", migrationFileError, entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		migration, found := byVersion[version]
+		if !found {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version := 1; version <= len(byVersion); version++ {
+		migration, found := byVersion[version]
+		if !found {
+			return nil, fmt.Errorf("%w: version %d", migrationMissingError, version)
+		} else if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("%w: up or down statements of version %d", migrationMissingError, version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	return migrations, nil
+}
+
+/*
+Migrator applies and reverts migrations on a PostgreSQL database.
+
+The version of the database is kept in the schema_migrations table. Every migration runs in its
+own transaction along with the update of its version, so a failed migration leaves the database
+at the previous version.
+
+A Migrator is not safe for concurrent use. Run a single Migrator per database at a time.
+*/
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+/*
+NewMigrator creates a Migrator with the migrations of bookk.
+
+Parameters:
+  - db: The connection pool to the PostgreSQL database to migrate
+
+Returns:
+  - A pointer to a new Migrator
+  - An error if the embedded migrations cannot be read
+*/
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+/*
+Version returns the version of the database, creating the schema_migrations table if missing.
+
+Returns:
+  - The version of the last migration applied, or 0 if there is none
+  - An error if the version cannot be read
+*/
+func (m *Migrator) Version() (int, error) {
+	_, err := m.db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version integer PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())")
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	err = m.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// Latest returns the version of the last migration known by the Migrator.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// run runs a statement of a migration and records the new version in a transaction
+func (m *Migrator) run(migration Migration, statement, record string) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(statement); err == nil {
+		_, err = tx.Exec(record, migration.Version)
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%w: %d_%s: %w", migrationFailedError, migration.Version, migration.Name, err)
+	}
+	return tx.Commit()
+}
+
+/*
+Up applies every pending migration.
+
+Returns:
+  - An error if any migration fails, leaving the database at the version of the last migration applied
+*/
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+/*
+Down reverts the last migration applied. It does nothing if there is none.
+
+Returns:
+  - An error if the migration fails
+*/
+func (m *Migrator) Down() error {
+	version, err := m.Version()
+	if err != nil || version == 0 {
+		return err
+	}
+	return m.To(version - 1)
+}
+
+/*
+To applies or reverts migrations until the database is at a version.
+
+Parameters:
+  - version: The version to migrate to. 0 reverts every migration
+
+Returns:
+  - An error if the version is unknown or any migration fails, leaving the database at the
+    version of the last migration applied or reverted
+*/
+func (m *Migrator) To(version int) error {
+	if version < 0 || version > m.Latest() {
+		return fmt.Errorf("%w: %d", migrationVersionError, version)
+	}
+	current, err := m.Version()
+	if err != nil {
+		return err
+	} else if current > m.Latest() {
+		return fmt.Errorf("%w: database is at %d", migrationVersionError, current)
+	}
+
+	if version > current {
+		for _, migration := range m.migrations[current:version] {
+			if err := m.run(migration, migration.Up, "INSERT INTO schema_migrations (version) VALUES ($1)"); err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, migration := range slices.Backward(m.migrations[version:current]) {
+			if err := m.run(migration, migration.Down, "DELETE FROM schema_migrations WHERE version = $1"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+-- btree_gist allows mixing equality on text columns with range operators in GiST indexes and exclusion constraints
+CREATE EXTENSION IF NOT EXISTS btree_gist;
@@ -0,0 +1,22 @@
+CREATE TABLE users (
+    id           text PRIMARY KEY,
+    email        text NOT NULL DEFAULT '',
+    role         integer NOT NULL DEFAULT 0,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    deleted_at   timestamptz,
+    banned_until timestamptz,
+    last_action  timestamptz
+);
+
+CREATE INDEX users_email_idx ON users (email) WHERE deleted_at IS NULL;
+
+-- Relations are directed: user_id is related to related_user_id
+CREATE TABLE user_relations (
+    user_id         text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    related_user_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, related_user_id),
+    CHECK (user_id <> related_user_id)
+);
+
+CREATE INDEX user_relations_related_user_id_idx ON user_relations (related_user_id);
@@ -0,0 +1,9 @@
+CREATE TABLE items (
+    id          text PRIMARY KEY,
+    user_id     text NOT NULL REFERENCES users (id),
+    name        text NOT NULL DEFAULT '',
+    description text NOT NULL DEFAULT '',
+    price       real NOT NULL DEFAULT 0
+);
+
+CREATE INDEX items_user_id_idx ON items (user_id);
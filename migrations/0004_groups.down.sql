@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS group_item_exclusions;
+DROP TABLE IF EXISTS group_users;
+DROP TABLE IF EXISTS groups;
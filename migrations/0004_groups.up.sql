@@ -0,0 +1,22 @@
+CREATE TABLE groups (
+    id          text PRIMARY KEY,
+    name        text NOT NULL DEFAULT '',
+    description text NOT NULL DEFAULT '',
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE group_users (
+    group_id text NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id  text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    added_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX group_users_user_id_idx ON group_users (user_id);
+
+-- Items owned by group users that are not items of the group
+CREATE TABLE group_item_exclusions (
+    group_id text NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    item_id  text NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, item_id)
+);
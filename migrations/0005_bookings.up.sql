@@ -0,0 +1,14 @@
+-- The period of a booking is [starts_at, ends_at). Bookings of the same item may not share any instant unless cancelled
+CREATE TABLE bookings (
+    id          text PRIMARY KEY,
+    user_id     text NOT NULL REFERENCES users (id),
+    item_id     text NOT NULL REFERENCES items (id),
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    period      tstzrange NOT NULL,
+    description text NOT NULL DEFAULT '',
+    cancelled   boolean NOT NULL DEFAULT false,
+    CONSTRAINT bookings_item_id_period_excl EXCLUDE USING gist (item_id WITH =, period WITH &&) WHERE (NOT cancelled)
+);
+
+CREATE INDEX bookings_item_id_period_idx ON bookings USING gist (item_id, period);
+CREATE INDEX bookings_user_id_period_idx ON bookings USING gist (user_id, period);
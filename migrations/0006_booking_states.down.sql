@@ -0,0 +1,9 @@
+ALTER TABLE bookings ADD COLUMN cancelled boolean NOT NULL DEFAULT false;
+
+UPDATE bookings SET cancelled = true WHERE state NOT IN ('pending', 'confirmed', 'checked-in');
+
+ALTER TABLE bookings DROP CONSTRAINT bookings_item_id_period_excl;
+ALTER TABLE bookings DROP COLUMN state;
+ALTER TABLE bookings DROP COLUMN transitions;
+ALTER TABLE bookings ADD CONSTRAINT bookings_item_id_period_excl
+    EXCLUDE USING gist (item_id WITH =, period WITH &&) WHERE (NOT cancelled);
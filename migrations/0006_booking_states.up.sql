@@ -0,0 +1,12 @@
+-- Bookings move through a lifecycle of states. Only pending, confirmed and checked-in bookings take the time of their item
+ALTER TABLE bookings
+    ADD COLUMN state       text NOT NULL DEFAULT 'confirmed'
+        CHECK (state IN ('pending', 'confirmed', 'checked-in', 'completed', 'no-show', 'cancelled', 'rejected')),
+    ADD COLUMN transitions jsonb NOT NULL DEFAULT '[]';
+
+UPDATE bookings SET state = 'cancelled' WHERE cancelled;
+
+ALTER TABLE bookings DROP CONSTRAINT bookings_item_id_period_excl;
+ALTER TABLE bookings DROP COLUMN cancelled;
+ALTER TABLE bookings ADD CONSTRAINT bookings_item_id_period_excl
+    EXCLUDE USING gist (item_id WITH =, period WITH &&) WHERE (state IN ('pending', 'confirmed', 'checked-in'));
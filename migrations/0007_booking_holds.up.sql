@@ -0,0 +1,2 @@
+-- Pending bookings of items requiring approval are held until a time, when they are rejected unless approved
+ALTER TABLE bookings ADD COLUMN hold_until timestamptz;
@@ -0,0 +1,12 @@
+-- A slot hold takes the time of an item for a while, until expires_at. Its token is a secret of the one holding the time
+CREATE TABLE slot_holds (
+    id         text PRIMARY KEY,
+    token      text NOT NULL UNIQUE,
+    user_id    text NOT NULL REFERENCES users (id),
+    item_id    text NOT NULL REFERENCES items (id),
+    period     tstzrange NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+
+CREATE INDEX slot_holds_item_id_period_idx ON slot_holds USING gist (item_id, period);
+CREATE INDEX slot_holds_expires_at_idx ON slot_holds (expires_at);
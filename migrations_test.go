@@ -0,0 +1,149 @@
+package bookk
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Embedded migrations should be read. Instead: %v", err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 || migration.Up == "" || migration.Down == "" {
+			t.Errorf("Migration %d should have version %d and statements. Instead: %+v", i, i+1, migration)
+		}
+	}
+
+	schema := ""
+	for _, migration := range migrations {
+		schema += migration.Up
+	}
+	for _, table := range [8]string{"users", "user_relations", "items", "groups", "group_users", "group_item_exclusions", "bookings", "slot_holds"} {
+		if !strings.Contains(schema, "CREATE TABLE "+table+" (") {
+			t.Errorf("Schema should create the table %s", table)
+		}
+	}
+	if !strings.Contains(schema, "EXCLUDE USING gist (item_id WITH =, period WITH &&)") {
+		t.Errorf("Schema should prevent double bookings")
+	}
+}
+
+func TestReadMigrations(t *testing.T) {
+	testCases := map[string]struct {
+		files    fstest.MapFS
+		expected error
+	}{
+		"Unrecognized file": {fstest.MapFS{
+			"migrations/0001_first.sql": {Data: []byte("SELECT 1;")},
+		}, migrationFileError},
+		"Missing version": {fstest.MapFS{
+			"migrations/0001_first.up.sql":   {Data: []byte("SELECT 1;")},
+			"migrations/0001_first.down.sql": {Data: []byte("SELECT 1;")},
+			"migrations/0003_third.up.sql":   {Data: []byte("SELECT 3;")},
+			"migrations/0003_third.down.sql": {Data: []byte("SELECT 3;")},
+		}, migrationMissingError},
+		"Missing down": {fstest.MapFS{
+			"migrations/0001_first.up.sql": {Data: []byte("SELECT 1;")},
+		}, migrationMissingError},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := readMigrations(testCase.files, "migrations"); !errors.Is(err, testCase.expected) {
+				t.Errorf("Migrations should not be read due to: %v. Instead: %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+// newTestMigrator creates a Migrator with two migrations on a fake database
+func newTestMigrator(t *testing.T) (*Migrator, *fakeDatabase) {
+	db, fake := newFakeDatabase(t)
+	return &Migrator{db: db, migrations: []Migration{
+		{Version: 1, Name: "first", Up: "CREATE TABLE first", Down: "DROP TABLE first"},
+		{Version: 2, Name: "second", Up: "CREATE TABLE second", Down: "DROP TABLE second"},
+	}}, fake
+}
+
+// expectVersion expects the Migrator to read the version of the database
+func expectVersion(fake *fakeDatabase, version int64) {
+	fake.expect("CREATE TABLE IF NOT EXISTS schema_migrations").willAffect(0)
+	fake.expect("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").willReturnRows([]string{"version"}, []driver.Value{version})
+}
+
+func TestMigratorUp(t *testing.T) {
+	migrator, fake := newTestMigrator(t)
+	expectVersion(fake, 0)
+	for _, version := range [2]int64{1, 2} {
+		fake.expect("BEGIN")
+		fake.expect(map[int64]string{1: "CREATE TABLE first", 2: "CREATE TABLE second"}[version]).willAffect(0)
+		fake.expect("INSERT INTO schema_migrations", version).willAffect(1)
+		fake.expect("COMMIT")
+	}
+	if err := migrator.Up(); err != nil {
+		t.Errorf("Migrations should be applied. Instead: %v", err)
+	}
+
+	t.Run("Up to date", func(t *testing.T) {
+		expectVersion(fake, 2)
+		if err := migrator.Up(); err != nil {
+			t.Errorf("Up to date database should not be migrated. Instead: %v", err)
+		}
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		expectVersion(fake, 1)
+		fake.expect("BEGIN")
+		fake.expect("CREATE TABLE second").willFail(errors.New("syntax error"))
+		fake.expect("ROLLBACK")
+		if err := migrator.Up(); !errors.Is(err, migrationFailedError) || !strings.Contains(err.Error(), "2_second") {
+			t.Errorf("Failed migration should be reported. Instead: %v", err)
+		}
+	})
+}
+
+func TestMigratorDown(t *testing.T) {
+	migrator, fake := newTestMigrator(t)
+	expectVersion(fake, 2)
+	expectVersion(fake, 2)
+	fake.expect("BEGIN")
+	fake.expect("DROP TABLE second").willAffect(0)
+	fake.expect("DELETE FROM schema_migrations", int64(2)).willAffect(1)
+	fake.expect("COMMIT")
+	if err := migrator.Down(); err != nil {
+		t.Errorf("Last migration should be reverted. Instead: %v", err)
+	}
+
+	t.Run("To 0", func(t *testing.T) {
+		expectVersion(fake, 1)
+		fake.expect("BEGIN")
+		fake.expect("DROP TABLE first").willAffect(0)
+		fake.expect("DELETE FROM schema_migrations", int64(1)).willAffect(1)
+		fake.expect("COMMIT")
+		if err := migrator.To(0); err != nil {
+			t.Errorf("Every migration should be reverted. Instead: %v", err)
+		}
+	})
+
+	t.Run("Nothing to revert", func(t *testing.T) {
+		expectVersion(fake, 0)
+		if err := migrator.Down(); err != nil {
+			t.Errorf("Empty database should not be reverted. Instead: %v", err)
+		}
+	})
+
+	t.Run("Unknown version", func(t *testing.T) {
+		if err := migrator.To(3); !errors.Is(err, migrationVersionError) {
+			t.Errorf("Unknown version should fail. Instead: %v", err)
+		}
+		expectVersion(fake, 5)
+		if err := migrator.To(1); !errors.Is(err, migrationVersionError) {
+			t.Errorf("Database newer than the migrations should fail. Instead: %v", err)
+		}
+	})
+}
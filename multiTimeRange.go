@@ -0,0 +1,241 @@
+package bookk
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+/*
+MultiTimeRange is a set of TimeRange.
+
+The set operations (Add, Remove, Union, Intersect and Complement) always leave the set
+normalized: ranges are sorted by their lower bound and none of them overlaps or touches
+another one. A MultiTimeRange built by hand can be normalized with Normalize.
+*/
+type MultiTimeRange []*TimeRange
+
+func (mr MultiTimeRange) Len() int {
+	return len(mr)
+}
+
+func (mr MultiTimeRange) Less(i, j int) bool {
+	return compareLowerBounds(mr[i], mr[j]) < 0
+}
+
+func (mr MultiTimeRange) Swap(i, j int) {
+	mr[i], mr[j] = mr[j], mr[i]
+}
+
+/*
+NewMultiTimeRange creates a normalized MultiTimeRange from any number of TimeRange.
+
+Overlapping or touching ranges are combined with TimeRange.Union, and empty ranges are dropped.
+
+Parameters:
+  - ranges: The ranges to include in the set. They are copied, so later changes to them do
+    not affect the set
+
+Returns:
+  - A normalized MultiTimeRange
+*/
+func NewMultiTimeRange(ranges ...*TimeRange) MultiTimeRange {
+	return MultiTimeRange(ranges).Normalize()
+}
+
+/*
+Normalize returns a copy of the set sorted by lower bound with every overlapping or
+touching ranges combined into a single range.
+
+Returns:
+  - A normalized copy of the MultiTimeRange
+*/
+func (mr MultiTimeRange) Normalize() MultiTimeRange {
+	sorted := make(MultiTimeRange, 0, len(mr))
+	for _, r := range mr {
+		if r != nil && !r.IsEmpty() {
+			sorted = append(sorted, r.Clone())
+		}
+	}
+	sort.Stable(sorted)
+
+	normalized := make(MultiTimeRange, 0, len(sorted))
+	for _, r := range sorted {
+		if len(normalized) > 0 {
+			if union := normalized[len(normalized)-1].Union(r); union != nil {
+				normalized[len(normalized)-1] = union
+				continue
+			}
+		}
+		normalized = append(normalized, r)
+	}
+	return normalized
+}
+
+/*
+Clone creates an independent copy of the MultiTimeRange.
+
+Returns:
+  - A MultiTimeRange with a copy of every range in the original set
+*/
+func (mr MultiTimeRange) Clone() MultiTimeRange {
+	clone := make(MultiTimeRange, len(mr))
+	for i, r := range mr {
+		clone[i] = r.Clone()
+	}
+	return clone
+}
+
+/*
+Add includes a TimeRange in the set.
+
+The range is combined with every range of the set that overlaps or touches it.
+
+Parameters:
+  - r: The TimeRange to add to the set
+*/
+func (mr *MultiTimeRange) Add(r TimeRange) {
+	*mr = append(*mr, &r).Normalize()
+}
+
+/*
+Remove excludes from the set every instant contained in a TimeRange.
+
+Ranges of the set that are partially covered by r are cut following the inclusion/exclusion
+configuration of r, as in TimeRange.Difference.
+
+Parameters:
+  - r: The TimeRange to remove from the set
+*/
+func (mr *MultiTimeRange) Remove(r TimeRange) {
+	result := make(MultiTimeRange, 0, len(*mr))
+	for _, current := range (*mr).Normalize() {
+		result = append(result, current.Difference(&r)...)
+	}
+	*mr = result
+}
+
+/*
+ContainsTime checks if an instant is contained in any range of the set.
+
+Parameters:
+  - t: The instant to look for
+*/
+func (mr MultiTimeRange) ContainsTime(t time.Time) bool {
+	for _, r := range mr {
+		if r.ContainsTime(t) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ContainsRange checks if a TimeRange is completely contained in the set.
+
+Since a normalized set has no touching ranges, r is contained only when a single range of the
+set contains it. An empty TimeRange is always contained.
+
+Parameters:
+  - r: The TimeRange to look for
+*/
+func (mr MultiTimeRange) ContainsRange(r *TimeRange) bool {
+	if r.IsEmpty() {
+		return true
+	}
+	for _, current := range mr.Normalize() {
+		if current.Contains(r) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Union combines two sets into a single one.
+
+Parameters:
+  - other: The set to combine with the current one
+
+Returns:
+  - A normalized MultiTimeRange with every instant contained in any of the sets
+*/
+func (mr MultiTimeRange) Union(other MultiTimeRange) MultiTimeRange {
+	combined := make(MultiTimeRange, 0, len(mr)+len(other))
+	combined = append(combined, mr...)
+	combined = append(combined, other...)
+	return combined.Normalize()
+}
+
+/*
+Intersect computes the instants shared by two sets.
+
+Parameters:
+  - other: The set to intersect with the current one
+
+Returns:
+  - A normalized MultiTimeRange with every instant contained in both sets
+*/
+func (mr MultiTimeRange) Intersect(other MultiTimeRange) MultiTimeRange {
+	left, right := mr.Normalize(), other.Normalize()
+	result := MultiTimeRange{}
+
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		if intersection := left[i].Intersection(right[j]); intersection != nil {
+			result = append(result, intersection)
+		}
+
+		// The range ending first cannot intersect with the following ranges of the other set
+		if compareUpperBounds(left[i], right[j]) < 0 {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result.Normalize()
+}
+
+/*
+Complement computes the instants of a TimeRange that are not contained in the set.
+
+This is the typical way to turn a set of busy periods into the free periods of a window.
+
+Parameters:
+  - within: The TimeRange limiting the complement. If nil, the complement is computed over
+    the whole timeline, so it has unbounded ranges before and after the set
+
+Returns:
+  - A normalized MultiTimeRange with every instant of within that is not contained in the set
+*/
+func (mr MultiTimeRange) Complement(within *TimeRange) MultiTimeRange {
+	if within == nil {
+		within = &TimeRange{lowerLimit: TimeRangeUnbounded, upperLimit: TimeRangeUnbounded}
+	}
+
+	complement := NewMultiTimeRange(within)
+	for _, r := range mr {
+		complement.Remove(*r)
+	}
+	return complement
+}
+
+/*
+TotalDuration adds up the duration of every range in the set.
+
+Overlapping ranges are only counted once.
+
+Returns:
+  - The time covered by the set. If any range has an infinite or missing bound, the
+    maximum time.Duration is returned
+*/
+func (mr MultiTimeRange) TotalDuration() time.Duration {
+	var total time.Duration
+	for _, r := range mr.Normalize() {
+		if r.lowerLimit != TimeRangeFinite || r.upperLimit != TimeRangeFinite {
+			return time.Duration(math.MaxInt64)
+		}
+		total += r.Duration()
+	}
+	return total
+}
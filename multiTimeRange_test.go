@@ -0,0 +1,135 @@
+package bookk
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// hoursRange builds a TimeRange relative to testTime using hours as unit
+func hoursRange(lower, upper float64, bounds TimeRangeBound) *TimeRange {
+	r, _ := NewTimeRange(
+		testTime.Add(time.Duration(lower*float64(time.Hour))),
+		testTime.Add(time.Duration(upper*float64(time.Hour))),
+		bounds,
+	)
+	return r
+}
+
+func TestNewMultiTimeRange(t *testing.T) {
+	set := NewMultiTimeRange(
+		hoursRange(5, 6, TimeRangeIlEu),
+		hoursRange(0, 1, TimeRangeIlEu),
+		hoursRange(1, 2, TimeRangeIlEu),
+		hoursRange(3, 4, TimeRangeIlEu),
+		hoursRange(4, 5, TimeRangeElIu),
+		hoursRange(3.5, 3.5, TimeRangeIlEu),
+	)
+
+	expected := "[0,2) [3,4) (4,6)"
+	if repr := multiHoursRepr(set); repr != expected {
+		t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+	}
+}
+
+func TestMultiTimeRangeAddRemove(t *testing.T) {
+	set := MultiTimeRange{}
+	set.Add(*hoursRange(2, 3, TimeRangeIlEu))
+	set.Add(*hoursRange(0, 1, TimeRangeIlEu))
+	set.Add(*hoursRange(1, 2, TimeRangeBoundsExclusion))
+
+	expected := "[0,1) (1,3)"
+	if repr := multiHoursRepr(set); repr != expected {
+		t.Errorf("No expected output after Add:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+	}
+
+	set.Remove(*hoursRange(0.5, 2.5, TimeRangeBoundsInclusion))
+	expected = "[0,0.5) (2.5,3)"
+	if repr := multiHoursRepr(set); repr != expected {
+		t.Errorf("No expected output after Remove:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+	}
+}
+
+func TestMultiTimeRangeContains(t *testing.T) {
+	set := NewMultiTimeRange(hoursRange(0, 1, TimeRangeIlEu), hoursRange(2, 3, TimeRangeBoundsInclusion))
+
+	t.Run("Time", func(t *testing.T) {
+		if !set.ContainsTime(testTime) {
+			t.Errorf("Lower inclusive bound should be contained")
+		}
+		if set.ContainsTime(testTime.Add(time.Hour)) {
+			t.Errorf("Upper exclusive bound should NOT be contained")
+		}
+		if set.ContainsTime(testTime.Add(90 * time.Minute)) {
+			t.Errorf("Instant between ranges should NOT be contained")
+		}
+		if !set.ContainsTime(testTime.Add(3 * time.Hour)) {
+			t.Errorf("Upper inclusive bound should be contained")
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		if !set.ContainsRange(hoursRange(2, 3, TimeRangeElIu)) {
+			t.Errorf("Range inside the set should be contained")
+		}
+		if set.ContainsRange(hoursRange(0, 1, TimeRangeBoundsInclusion)) {
+			t.Errorf("Range including an excluded bound should NOT be contained")
+		}
+		if set.ContainsRange(hoursRange(0.5, 2.5, TimeRangeIlEu)) {
+			t.Errorf("Range across a gap should NOT be contained")
+		}
+	})
+}
+
+func TestMultiTimeRangeUnionIntersect(t *testing.T) {
+	first := NewMultiTimeRange(hoursRange(0, 2, TimeRangeIlEu), hoursRange(4, 6, TimeRangeIlEu))
+	second := NewMultiTimeRange(hoursRange(1, 5, TimeRangeBoundsInclusion), hoursRange(6, 7, TimeRangeIlEu))
+
+	expected := "[0,7)"
+	if repr := multiHoursRepr(first.Union(second)); repr != expected {
+		t.Errorf("No expected Union output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+	}
+
+	expected = "[1,2) [4,5]"
+	if repr := multiHoursRepr(first.Intersect(second)); repr != expected {
+		t.Errorf("No expected Intersect output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+	}
+}
+
+func TestMultiTimeRangeComplement(t *testing.T) {
+	busy := NewMultiTimeRange(
+		hoursRange(9, 10, TimeRangeIlEu),
+		hoursRange(12, 13, TimeRangeIlEu),
+		hoursRange(10, 11, TimeRangeIlEu),
+	)
+
+	free := busy.Complement(hoursRange(8, 18, TimeRangeIlEu))
+	expected := "[8,9) [11,12) [13,18)"
+	if repr := multiHoursRepr(free); repr != expected {
+		t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+	}
+
+	if duration := free.TotalDuration(); duration != 7*time.Hour {
+		t.Errorf("Free duration should be 7h. Instead: %s", duration)
+	}
+	if duration := busy.TotalDuration(); duration != 3*time.Hour {
+		t.Errorf("Busy duration should be 3h. Instead: %s", duration)
+	}
+}
+
+func TestMultiTimeRangeComplementWithoutWindow(t *testing.T) {
+	busy := NewMultiTimeRange(hoursRange(0, 1, TimeRangeIlEu), hoursRange(2, 3, TimeRangeElIu))
+
+	expected := "(,0) [1,2] (3,)"
+	if repr := multiHoursRepr(busy.Complement(nil)); repr != expected {
+		t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+	}
+	if duration := busy.Complement(nil).TotalDuration(); duration != time.Duration(math.MaxInt64) {
+		t.Errorf("Unbounded duration should be the maximum duration. Instead: %s", duration)
+	}
+
+	var empty MultiTimeRange
+	if repr := multiHoursRepr(empty.Complement(nil)); repr != "(,)" {
+		t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", "(,)", repr)
+	}
+}
@@ -0,0 +1,273 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	openingPeriodError              = errors.New("Opening period not recognized")
+	openingHoursWindowError         = errors.New("Opening hours cannot be expanded over an infinite window")
+	bookingOutsideOpeningHoursError = errors.New("Booking is outside opening hours")
+)
+
+// OpeningPeriod is a part of a day when an item is open, as wall clock times of the location of its OpeningHours.
+type OpeningPeriod struct {
+	Opens  time.Duration // Time since midnight the period starts at
+	Closes time.Duration // Time since midnight the period ends at, up to 24 hours. If it is not after Opens, the period ends the following day
+}
+
+/*
+NewOpeningPeriod creates an OpeningPeriod from two wall clock times.
+
+Parameters:
+  - opens: The time the period starts at, as "15:04" or "15:04:05"
+  - closes: The time the period ends at, in the same format. "24:00" closes at midnight
+
+Returns:
+  - The OpeningPeriod
+  - An error if a time cannot be parsed
+*/
+func NewOpeningPeriod(opens, closes string) (OpeningPeriod, error) {
+	var period OpeningPeriod
+	var err error
+	if period.Opens, err = parseTimeOfDay(opens); err != nil {
+		return period, err
+	}
+	period.Closes, err = parseTimeOfDay(closes)
+	return period, err
+}
+
+// parseTimeOfDay reads a wall clock time as the time since midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	if value == "24:00" || value == "24:00:00" {
+		return 24 * time.Hour, nil
+	}
+	for _, layout := range [2]string{"15:04", time.TimeOnly} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", openingPeriodError, value)
+}
+
+// atTimeOfDay returns the wall clock time of a day in its location, so days with DST changes keep their opening times
+func atTimeOfDay(day time.Time, since time.Duration) time.Time {
+	year, month, date := day.Date()
+	// Split in components, as the nanoseconds of a day overflow an int of 32 bits
+	hours, minutes, seconds := since/time.Hour, since%time.Hour/time.Minute, since%time.Minute/time.Second
+	return time.Date(year, month, date, int(hours), int(minutes), int(seconds), int(since%time.Second), day.Location())
+}
+
+/*
+OpeningHours is the schedule of when an item may be booked, in the IANA time zone of the item.
+
+It is built from the opening periods of every day of the week. Overrides replace the periods of
+a given date, so a date overridden with no period, such as a holiday, is closed all day.
+Closures are removed from the schedule at last, for closing times that do not follow days,
+such as maintenance works.
+
+An OpeningHours is not safe for concurrent changes; build it before sharing it.
+*/
+type OpeningHours struct {
+	location  *time.Location
+	weekly    [7][]OpeningPeriod
+	overrides map[string][]OpeningPeriod // Periods of a date as "2006-01-02"
+	closures  MultiTimeRange
+}
+
+/*
+NewOpeningHours creates OpeningHours closed every day.
+
+Parameters:
+  - timezone: The IANA name of the time zone of the item, such as "Europe/Madrid"
+
+Returns:
+  - A pointer to the new OpeningHours
+  - An error if the time zone is not found
+*/
+func NewOpeningHours(timezone string) (*OpeningHours, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &OpeningHours{location: location, overrides: map[string][]OpeningPeriod{}}, nil
+}
+
+// Location returns the time zone of the opening hours.
+func (h *OpeningHours) Location() *time.Location {
+	return h.location
+}
+
+/*
+SetDay sets the opening periods of a day of the week, replacing the previous ones.
+
+Parameters:
+  - day: The day of the week
+  - periods: The periods the item is open. None closes the day
+*/
+func (h *OpeningHours) SetDay(day time.Weekday, periods ...OpeningPeriod) {
+	h.weekly[day] = append([]OpeningPeriod(nil), periods...)
+}
+
+/*
+Override sets the opening periods of a date, instead of the ones of its day of the week.
+
+Parameters:
+  - date: The date as "2006-01-02"
+  - periods: The periods the item is open. None closes the date, as on holidays
+
+Returns:
+  - An error if the date cannot be parsed
+*/
+func (h *OpeningHours) Override(date string, periods ...OpeningPeriod) error {
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return err
+	}
+	h.overrides[date] = append([]OpeningPeriod{}, periods...)
+	return nil
+}
+
+/*
+Close removes a time range from the opening hours, whatever the schedule of its days.
+
+Parameters:
+  - r: The time range the item is closed
+*/
+func (h *OpeningHours) Close(r TimeRange) {
+	h.closures.Add(r)
+}
+
+// periods returns the opening periods of a day in the location of the opening hours
+func (h *OpeningHours) periods(day time.Time) []OpeningPeriod {
+	if periods, found := h.overrides[day.Format(time.DateOnly)]; found {
+		return periods
+	}
+	return h.weekly[day.Weekday()]
+}
+
+/*
+Expand lists the periods the item is open within a window.
+
+Parameters:
+  - window: The time range to list opening periods in. Its bounds must be finite
+
+Returns:
+  - The opening periods within the window, normalized
+  - An error if any bound of the window is not finite
+*/
+func (h *OpeningHours) Expand(window TimeRange) (MultiTimeRange, error) {
+	if window.IsEmpty() {
+		return MultiTimeRange{}, nil
+	} else if window.lowerLimit != TimeRangeFinite || window.upperLimit != TimeRangeFinite {
+		return nil, openingHoursWindowError
+	}
+
+	// Periods of the previous day may end the next one
+	first := truncateTo(window.lowerBound.In(h.location), 24*time.Hour).AddDate(0, 0, -1)
+	last := window.upperBound.In(h.location)
+
+	open := MultiTimeRange{}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		for _, period := range h.periods(day) {
+			closes := atTimeOfDay(day, period.Closes)
+			if period.Closes <= period.Opens {
+				closes = atTimeOfDay(day.AddDate(0, 0, 1), period.Closes)
+			}
+			if r, err := NewTimeRange(atTimeOfDay(day, period.Opens), closes, TimeRangeIlEu); err == nil {
+				open = append(open, r)
+			}
+		}
+	}
+
+	open = open.Normalize().Intersect(NewMultiTimeRange(&window))
+	for _, closure := range h.closures {
+		open.Remove(*closure)
+	}
+	return open, nil
+}
+
+/*
+OpeningHoursRegistry holds the opening hours of every item, and is an OpeningHoursSource.
+
+Items without opening hours are always open, unless a default schedule is set.
+
+An OpeningHoursRegistry is safe for concurrent use.
+*/
+type OpeningHoursRegistry struct {
+	mu       sync.RWMutex
+	items    map[string]*OpeningHours
+	fallback *OpeningHours
+}
+
+var _ OpeningHoursSource = (*OpeningHoursRegistry)(nil)
+
+/*
+NewOpeningHoursRegistry creates an empty OpeningHoursRegistry.
+
+Returns:
+  - A pointer to a new OpeningHoursRegistry
+*/
+func NewOpeningHoursRegistry() *OpeningHoursRegistry {
+	return &OpeningHoursRegistry{items: map[string]*OpeningHours{}}
+}
+
+// Set sets the opening hours of an item. Nil removes them. The opening hours must not be changed afterwards.
+func (r *OpeningHoursRegistry) Set(itemId string, hours *OpeningHours) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hours == nil {
+		delete(r.items, itemId)
+	} else {
+		r.items[itemId] = hours
+	}
+}
+
+// SetDefault sets the opening hours of the items without their own. Nil makes them always open.
+func (r *OpeningHoursRegistry) SetDefault(hours *OpeningHours) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = hours
+}
+
+/*
+OpeningHours lists the periods an item is open within a window.
+
+Parameters:
+  - itemId: The id of the item
+  - window: The time range to list opening periods in
+
+Returns:
+  - The opening periods within the window, normalized
+  - An error if the item has opening hours and any bound of the window is not finite
+*/
+func (r *OpeningHoursRegistry) OpeningHours(itemId string, window TimeRange) (MultiTimeRange, error) {
+	r.mu.RLock()
+	hours, found := r.items[itemId]
+	if !found {
+		hours = r.fallback
+	}
+	r.mu.RUnlock()
+
+	if hours == nil {
+		return NewMultiTimeRange(&window), nil
+	}
+	return hours.Expand(window)
+}
+
+// checkOpeningHours returns an error if a booking is not within the opening hours of its item. Only active bookings are checked
+func checkOpeningHours(source OpeningHoursSource, booking *Booking, r *TimeRange) error {
+	if source == nil || !booking.State.Active() || r.IsEmpty() {
+		return nil
+	}
+	open, err := source.OpeningHours(booking.ItemId, *r)
+	if err != nil {
+		return err
+	}
+	if !open.ContainsRange(r) {
+		return fmt.Errorf("%w: booking %q of item %q", bookingOutsideOpeningHoursError, booking.Id, booking.ItemId)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package bookk
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustOpeningPeriod(t *testing.T, opens, closes string) OpeningPeriod {
+	t.Helper()
+	period, err := NewOpeningPeriod(opens, closes)
+	if err != nil {
+		t.Fatalf("Opening period should be parsed. Instead: %v", err)
+	}
+	return period
+}
+
+// newTestOpeningHours opens Monday to Friday from 09:00 to 18:00 with a lunch break, and Saturday night
+func newTestOpeningHours(t *testing.T) *OpeningHours {
+	hours, err := NewOpeningHours("Europe/Madrid")
+	if err != nil {
+		t.Fatalf("Opening hours should be created. Instead: %v", err)
+	}
+	for day := time.Monday; day <= time.Friday; day++ {
+		hours.SetDay(day, mustOpeningPeriod(t, "09:00", "13:00"), mustOpeningPeriod(t, "14:00", "18:00"))
+	}
+	hours.SetDay(time.Saturday, mustOpeningPeriod(t, "22:00", "02:00"))
+	return hours
+}
+
+// openingRepr represents a set of ranges as local wall clock times with their offset
+func openingRepr(mr MultiTimeRange, location *time.Location) string {
+	repr := []string{}
+	for _, r := range mr {
+		repr = append(repr, r.LowerBound().In(location).Format("Mon 15:04-07")+"/"+r.UpperBound().In(location).Format("Mon 15:04"))
+	}
+	return strings.Join(repr, " ")
+}
+
+func TestNewOpeningPeriod(t *testing.T) {
+	if period := mustOpeningPeriod(t, "09:30", "24:00"); period.Opens != 9*time.Hour+30*time.Minute || period.Closes != 24*time.Hour {
+		t.Errorf("No expected opening period. Instead: %+v", period)
+	}
+	if period := mustOpeningPeriod(t, "09:30:15", "10:00"); period.Opens != 9*time.Hour+30*time.Minute+15*time.Second {
+		t.Errorf("No expected opening period. Instead: %+v", period)
+	}
+	for _, value := range [3]string{"9am", "25:00", ""} {
+		if _, err := NewOpeningPeriod(value, "10:00"); !errors.Is(err, openingPeriodError) {
+			t.Errorf("Time %q should not be parsed. Instead: %v", value, err)
+		}
+	}
+}
+
+func TestOpeningHoursExpand(t *testing.T) {
+	hours := newTestOpeningHours(t)
+	location := hours.Location()
+	week := func(from, to string) TimeRange {
+		lower, _ := time.ParseInLocation(time.DateTime, from, location)
+		upper, _ := time.ParseInLocation(time.DateTime, to, location)
+		r, _ := NewTimeRange(lower, upper, TimeRangeIlEu)
+		return *r
+	}
+
+	t.Run("Weekly schedule", func(t *testing.T) {
+		open, err := hours.Expand(week("2025-03-24 12:00:00", "2025-03-25 10:00:00"))
+		if err != nil {
+			t.Fatalf("Opening hours should be expanded. Instead: %v", err)
+		}
+		expected := "Mon 12:00+01/Mon 13:00 Mon 14:00+01/Mon 18:00 Tue 09:00+01/Tue 10:00"
+		if repr := openingRepr(open, location); repr != expected {
+			t.Errorf("No expected opening hours:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+		}
+	})
+
+	t.Run("Overrides, closures and DST", func(t *testing.T) {
+		hours.Override("2025-03-26", mustOpeningPeriod(t, "10:00", "12:00"))
+		hours.Override("2025-03-28")
+		closure := week("2025-03-27 15:00:00", "2025-03-27 16:00:00")
+		hours.Close(closure)
+
+		open, _ := hours.Expand(week("2025-03-26 00:00:00", "2025-04-01 00:00:00"))
+		expected := strings.Join([]string{
+			"Wed 10:00+01/Wed 12:00",
+			"Thu 09:00+01/Thu 13:00", "Thu 14:00+01/Thu 15:00", "Thu 16:00+01/Thu 18:00",
+			"Sat 22:00+01/Sun 03:00", // 02:00 does not exist on the night clocks move forward
+			"Mon 09:00+02/Mon 13:00", "Mon 14:00+02/Mon 18:00",
+		}, " ")
+		if repr := openingRepr(open, location); repr != expected {
+			t.Errorf("No expected opening hours:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+		}
+	})
+
+	t.Run("Night from the previous day", func(t *testing.T) {
+		open, _ := hours.Expand(week("2025-03-23 01:00:00", "2025-03-23 12:00:00"))
+		if repr := openingRepr(open, location); repr != "Sun 01:00+01/Sun 02:00" {
+			t.Errorf("Night period should continue on the next day. Instead: %s", repr)
+		}
+	})
+
+	t.Run("Infinite window", func(t *testing.T) {
+		since, _ := NewTimeRangeFrom(testTime, TimeRangeIlEu)
+		if _, err := hours.Expand(*since); !errors.Is(err, openingHoursWindowError) {
+			t.Errorf("Infinite window should fail. Instead: %v", err)
+		}
+	})
+
+	if err := hours.Override("2025-02-30"); err == nil {
+		t.Errorf("Invalid date should not be overridden")
+	}
+	if _, err := NewOpeningHours("Mars/Olympus_Mons"); err == nil {
+		t.Errorf("Unknown time zone should fail")
+	}
+}
+
+func TestOpeningHoursRegistry(t *testing.T) {
+	registry := NewOpeningHoursRegistry()
+	hours := newTestOpeningHours(t)
+	window := time.Date(2025, 3, 24, 0, 0, 0, 0, hours.Location())
+	day, _ := NewTimeRange(window, window.AddDate(0, 0, 1), TimeRangeIlEu)
+
+	if open, _ := registry.OpeningHours("item", *day); len(open) != 1 || !open[0].Equal(day) {
+		t.Errorf("Item without opening hours should always be open. Instead: %v", openingRepr(open, hours.Location()))
+	}
+	registry.Set("item", hours)
+	if open, _ := registry.OpeningHours("item", *day); len(open) != 2 {
+		t.Errorf("Item should be open as its opening hours. Instead: %v", openingRepr(open, hours.Location()))
+	}
+
+	closed, _ := NewOpeningHours("UTC")
+	registry.SetDefault(closed)
+	registry.Set("item", nil)
+	if open, _ := registry.OpeningHours("item", *day); len(open) != 0 {
+		t.Errorf("Item should follow the default opening hours. Instead: %v", openingRepr(open, hours.Location()))
+	}
+}
+
+func TestMemoryBookingServiceOpeningHours(t *testing.T) {
+	registry := NewOpeningHoursRegistry()
+	hours := newTestOpeningHours(t)
+	registry.Set("item", hours)
+	service := NewMemoryBookingService(WithOpeningHours(registry))
+	at := func(hour int) time.Time {
+		return time.Date(2025, 3, 24, hour, 0, 0, 0, hours.Location())
+	}
+
+	testCases := map[string]struct {
+		booking Booking
+		err     error
+	}{
+		"Within opening hours":  {Booking{BaseBooking: BaseBooking{ItemId: "item", StartsAt: at(9), EndsAt: at(13)}}, nil},
+		"Through lunch":         {Booking{BaseBooking: BaseBooking{ItemId: "item", StartsAt: at(14), EndsAt: at(19)}}, bookingOutsideOpeningHoursError},
+		"Closed":                {Booking{BaseBooking: BaseBooking{ItemId: "item", StartsAt: at(12), EndsAt: at(15)}}, bookingOutsideOpeningHoursError},
+		"Without opening hours": {Booking{BaseBooking: BaseBooking{ItemId: "other", StartsAt: at(20), EndsAt: at(21)}}, nil},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := service.CreateBooking(testCase.booking); !errors.Is(err, testCase.err) {
+				t.Errorf("No expected error:\nExpecting\t: %v\nRecieved\t: %v", testCase.err, err)
+			}
+		})
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		booking, _ := service.CreateBooking(Booking{BaseBooking: BaseBooking{ItemId: "item", StartsAt: at(15), EndsAt: at(16)}})
+		booking.EndsAt = at(19)
+		if err := service.UpdateBooking(booking); !errors.Is(err, bookingOutsideOpeningHoursError) {
+			t.Errorf("Booking should not be moved outside opening hours. Instead: %v", err)
+		}
+
+		cancelled, _ := service.TransitionBooking(booking.Id, BookingCancelled, "", "")
+		cancelled.EndsAt = at(19)
+		if err := service.UpdateBooking(cancelled); err != nil {
+			t.Errorf("Cancelled booking should be moved anywhere. Instead: %v", err)
+		}
+	})
+}
+
+func TestPostgresBookingServiceOpeningHours(t *testing.T) {
+	registry := NewOpeningHoursRegistry()
+	hours := newTestOpeningHours(t)
+	registry.Set("item", hours)
+	db, fake := newFakeDatabase(t)
+	service := NewPostgresBookingService(db, WithPostgresOpeningHours(registry))
+	booking := Booking{BaseBooking: BaseBooking{
+		Id:       "a",
+		ItemId:   "item",
+		StartsAt: time.Date(2025, 3, 24, 15, 0, 0, 0, hours.Location()),
+		EndsAt:   time.Date(2025, 3, 24, 16, 0, 0, 0, hours.Location()),
+	}}
+
+	expectStoreBooking(fake, "INSERT INTO bookings").willAffect(1)
+	if _, err := service.CreateBooking(booking); err != nil {
+		t.Errorf("Booking within opening hours should be created. Instead: %v", err)
+	}
+	outside := booking
+	outside.EndsAt = outside.EndsAt.Add(3 * time.Hour)
+	if _, err := service.CreateBooking(outside); !errors.Is(err, bookingOutsideOpeningHoursError) {
+		t.Errorf("Booking outside opening hours should not be created. Instead: %v", err)
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		fake.expect("SELECT state, item_id FROM bookings WHERE id = $1", "a").willReturnRows([]string{"state", "item_id"}, []driver.Value{"confirmed", "item"})
+		if err := service.UpdateBooking(&outside); !errors.Is(err, bookingOutsideOpeningHoursError) {
+			t.Errorf("Booking should not be moved outside opening hours. Instead: %v", err)
+		}
+
+		fake.expect("SELECT state, item_id FROM bookings WHERE id = $1", "a").willReturnRows([]string{"state", "item_id"}, []driver.Value{"cancelled", "item"})
+		expectStoreBooking(fake, "UPDATE bookings").willAffect(1)
+		if err := service.UpdateBooking(&outside); err != nil {
+			t.Errorf("Cancelled booking should be moved anywhere. Instead: %v", err)
+		}
+
+		fake.expect("SELECT state, item_id FROM bookings WHERE id = $1", "missing").willReturnRows([]string{"state", "item_id"})
+		missing := booking
+		missing.Id = "missing"
+		if err := service.UpdateBooking(&missing); !errors.Is(err, bookingNotFoundError) {
+			t.Errorf("Missing booking should not be updated. Instead: %v", err)
+		}
+	})
+}
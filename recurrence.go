@@ -0,0 +1,338 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	recurrenceRuleError   = errors.New("Recurrence rule not recognized")
+	recurrenceWindowError = errors.New("Unlimited recurrence cannot be expanded over an infinite window")
+)
+
+// recurrenceMaxEmptyPeriods is the number of periods in a row without occurrences after which a rule is taken as exhausted
+const recurrenceMaxEmptyPeriods = 1000
+
+// Frequency is the period a RecurrenceRule repeats on, as the FREQ rule part of RFC 5545.
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+	FrequencyYearly  Frequency = "YEARLY"
+)
+
+var recurrenceWeekdays = [7]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// RecurrenceDay is a day of the week of the BYDAY rule part, such as TU, 1MO or -1FR.
+type RecurrenceDay struct {
+	Weekday time.Weekday
+	Ordinal int // Nth such weekday of the month or year, counting from the end if negative. Zero is every one
+}
+
+func (day RecurrenceDay) String() string {
+	if day.Ordinal == 0 {
+		return recurrenceWeekdays[day.Weekday]
+	}
+	return strconv.Itoa(day.Ordinal) + recurrenceWeekdays[day.Weekday]
+}
+
+/*
+RecurrenceRule tells when a recurring booking repeats, as the RRULE property of RFC 5545.
+
+Occurrences keep the wall clock time of the first one in its location, so a booking every
+Tuesday at 10:00 stays at 10:00 when daylight saving time changes. Dates that do not exist in a
+period, such as the 31st of a short month, are skipped.
+*/
+type RecurrenceRule struct {
+	Frequency Frequency
+	Interval  int             // Periods between occurrences. Zero is taken as 1
+	ByDay     []RecurrenceDay // Days of the week of the occurrences. Ordinals are only allowed with monthly and yearly rules
+	Count     int             // Number of occurrences, counting the first one. Zero is unlimited
+	Until     time.Time       // Last instant an occurrence may start at. Zero is unlimited
+	WeekStart time.Weekday    // First day of the weeks of weekly rules. ParseRecurrenceRule defaults it to Monday, as RFC 5545 does
+}
+
+/*
+ParseRecurrenceRule reads a recurrence rule as written in RFC 5545, such as
+"FREQ=WEEKLY;BYDAY=TU;UNTIL=20250630T235959Z".
+
+The FREQ, INTERVAL, BYDAY, COUNT, UNTIL and WKST rule parts are supported. An optional "RRULE:"
+prefix is ignored.
+
+Parameters:
+  - rule: The text of the rule
+  - location: The location of the first occurrence, where an UNTIL without time zone is taken in.
+    An UNTIL date includes the whole day
+
+Returns:
+  - A pointer to the RecurrenceRule
+  - An error if the rule cannot be parsed, uses unsupported rule parts or is not valid
+*/
+func ParseRecurrenceRule(rule string, location *time.Location) (*RecurrenceRule, error) {
+	parsed := &RecurrenceRule{WeekStart: time.Monday}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"), ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("%w: %q", recurrenceRuleError, part)
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			parsed.Frequency = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			parsed.Interval, err = strconv.Atoi(value)
+		case "COUNT":
+			parsed.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			parsed.Until, err = parseRecurrenceUntil(value, location)
+		case "WKST":
+			parsed.WeekStart, err = parseRecurrenceWeekday(value)
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				var recurrenceDay RecurrenceDay
+				if recurrenceDay, err = parseRecurrenceDay(day); err != nil {
+					break
+				}
+				parsed.ByDay = append(parsed.ByDay, recurrenceDay)
+			}
+		default:
+			err = errors.New("unsupported rule part")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", recurrenceRuleError, part, err)
+		}
+	}
+
+	if err := parsed.Validate(); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+func parseRecurrenceWeekday(value string) (time.Weekday, error) {
+	if i := slices.Index(recurrenceWeekdays[:], strings.ToUpper(value)); i >= 0 {
+		return time.Weekday(i), nil
+	}
+	return 0, fmt.Errorf("unknown weekday %q", value)
+}
+
+func parseRecurrenceDay(value string) (RecurrenceDay, error) {
+	if len(value) < 2 {
+		return RecurrenceDay{}, fmt.Errorf("unknown weekday %q", value)
+	}
+	split := len(value) - 2
+	weekday, err := parseRecurrenceWeekday(value[split:])
+	if err != nil {
+		return RecurrenceDay{}, err
+	}
+	day := RecurrenceDay{Weekday: weekday}
+	if split > 0 {
+		if day.Ordinal, err = strconv.Atoi(value[:split]); err != nil {
+			return RecurrenceDay{}, err
+		}
+	}
+	return day, nil
+}
+
+// parseRecurrenceUntil reads an UNTIL as a UTC time, a floating time of the location or a whole date
+func parseRecurrenceUntil(value string, location *time.Location) (time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return until, nil
+	}
+	if until, err := time.ParseInLocation("20060102T150405", value, location); err == nil {
+		return until, nil
+	}
+	date, err := time.ParseInLocation("20060102", value, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return date.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+/*
+String writes the rule as the value of an RRULE property of RFC 5545.
+
+UNTIL is written in UTC, and WKST only when weeks do not start on Monday.
+*/
+func (rule *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(rule.Frequency)}
+	if rule.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.Interval))
+	}
+	if len(rule.ByDay) > 0 {
+		days := make([]string, len(rule.ByDay))
+		for i, day := range rule.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if rule.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(rule.Count))
+	}
+	if !rule.Until.IsZero() {
+		parts = append(parts, "UNTIL="+rule.Until.UTC().Format("20060102T150405Z"))
+	}
+	if rule.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+recurrenceWeekdays[rule.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+/*
+Validate checks the rule follows RFC 5545.
+
+Returns:
+  - An error if the frequency or a weekday is unknown, a number is negative, COUNT and UNTIL are
+    both set or a BYDAY ordinal is not allowed by the frequency
+*/
+func (rule *RecurrenceRule) Validate() error {
+	maxOrdinal := 0
+	switch rule.Frequency {
+	case FrequencyDaily, FrequencyWeekly:
+	case FrequencyMonthly:
+		maxOrdinal = 5
+	case FrequencyYearly:
+		maxOrdinal = 53
+	default:
+		return fmt.Errorf("%w: unknown frequency %q", recurrenceRuleError, rule.Frequency)
+	}
+
+	if rule.Interval < 0 || rule.Count < 0 {
+		return fmt.Errorf("%w: negative interval or count", recurrenceRuleError)
+	} else if rule.Count > 0 && !rule.Until.IsZero() {
+		return fmt.Errorf("%w: COUNT and UNTIL cannot be both set", recurrenceRuleError)
+	}
+	if rule.WeekStart < time.Sunday || rule.WeekStart > time.Saturday {
+		return fmt.Errorf("%w: unknown WKST %d", recurrenceRuleError, rule.WeekStart)
+	}
+	for _, day := range rule.ByDay {
+		if day.Weekday < time.Sunday || day.Weekday > time.Saturday {
+			return fmt.Errorf("%w: unknown BYDAY weekday %d", recurrenceRuleError, day.Weekday)
+		} else if day.Ordinal > maxOrdinal || day.Ordinal < -maxOrdinal {
+			return fmt.Errorf("%w: BYDAY %s not allowed with %s frequency", recurrenceRuleError, day, rule.Frequency)
+		}
+	}
+	return nil
+}
+
+// Unlimited tells whether the rule repeats forever, as it has neither COUNT nor UNTIL.
+func (rule *RecurrenceRule) Unlimited() bool {
+	return rule.Count == 0 && rule.Until.IsZero()
+}
+
+// civilDate is a calendar date with no location, so adding days never meets daylight saving time changes
+func civilDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// matchingDays lists the dates of a period, from first until the day before end, that match any day of a BYDAY
+func matchingDays(first, end time.Time, byDay []RecurrenceDay) []time.Time {
+	var weekdays [7][]time.Time
+	for day := first; day.Before(end); day = day.AddDate(0, 0, 1) {
+		weekdays[day.Weekday()] = append(weekdays[day.Weekday()], day)
+	}
+
+	var days []time.Time
+	for _, byDay := range byDay {
+		candidates := weekdays[byDay.Weekday]
+		switch {
+		case byDay.Ordinal == 0:
+			days = append(days, candidates...)
+		case byDay.Ordinal > 0 && byDay.Ordinal <= len(candidates):
+			days = append(days, candidates[byDay.Ordinal-1])
+		case byDay.Ordinal < 0 && -byDay.Ordinal <= len(candidates):
+			days = append(days, candidates[len(candidates)+byDay.Ordinal])
+		}
+	}
+	slices.SortFunc(days, time.Time.Compare)
+	return slices.CompactFunc(days, time.Time.Equal)
+}
+
+// periodDays lists the dates of the nth period of the rule starting at the date of dtstart
+func (rule *RecurrenceRule) periodDays(dtstart time.Time, n int) []time.Time {
+	year, month, day := dtstart.Date()
+	n *= max(rule.Interval, 1)
+
+	switch rule.Frequency {
+	case FrequencyDaily:
+		date := civilDate(year, month, day+n)
+		if len(rule.ByDay) > 0 && len(matchingDays(date, date.AddDate(0, 0, 1), rule.ByDay)) == 0 {
+			return nil
+		}
+		return []time.Time{date}
+	case FrequencyWeekly:
+		byDay := rule.ByDay
+		if len(byDay) == 0 {
+			byDay = []RecurrenceDay{{Weekday: dtstart.Weekday()}}
+		}
+		first := civilDate(year, month, day-(int(dtstart.Weekday()-rule.WeekStart)+7)%7+7*n)
+		return matchingDays(first, first.AddDate(0, 0, 7), byDay)
+	case FrequencyMonthly:
+		first := civilDate(year, month+time.Month(n), 1)
+		if len(rule.ByDay) > 0 {
+			return matchingDays(first, first.AddDate(0, 1, 0), rule.ByDay)
+		}
+		if date := civilDate(first.Year(), first.Month(), day); date.Month() == first.Month() {
+			return []time.Time{date}
+		}
+	case FrequencyYearly:
+		if len(rule.ByDay) > 0 {
+			first := civilDate(year+n, time.January, 1)
+			return matchingDays(first, first.AddDate(1, 0, 0), rule.ByDay)
+		}
+		if date := civilDate(year+n, month, day); date.Month() == month {
+			return []time.Time{date}
+		}
+	}
+	return nil
+}
+
+/*
+starts yields the starts of the occurrences of the rule from dtstart, in order.
+
+As RFC 5545 requires, dtstart is always the first occurrence and counts towards COUNT, even if
+it does not match the rule. Unlimited rules never end, so callers must stop on their own.
+*/
+func (rule *RecurrenceRule) starts(dtstart time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		if !rule.Until.IsZero() && dtstart.After(rule.Until) {
+			return
+		}
+		if !yield(dtstart) {
+			return
+		}
+
+		hour, minute, second := dtstart.Clock()
+		count := 1
+		for n, empty := 0, 0; empty < recurrenceMaxEmptyPeriods; n++ {
+			found := false
+			for _, date := range rule.periodDays(dtstart, n) {
+				start := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, second, dtstart.Nanosecond(), dtstart.Location())
+				if !start.After(dtstart) {
+					continue
+				}
+				if (rule.Count > 0 && count >= rule.Count) || (!rule.Until.IsZero() && start.After(rule.Until)) {
+					return
+				}
+				if !yield(start) {
+					return
+				}
+				count++
+				found = true
+			}
+			if found {
+				empty = 0
+			} else {
+				empty++
+			}
+		}
+	}
+}
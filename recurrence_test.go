@@ -0,0 +1,112 @@
+package bookk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recurrenceTime parses a time in Europe/Madrid as "2006-01-02 15:04"
+func recurrenceTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	location, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatalf("Time zone should be loaded. Instead: %v", err)
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, location)
+	if err != nil {
+		t.Fatalf("Time should be parsed. Instead: %v", err)
+	}
+	return parsed
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	for _, rule := range [5]string{
+		"FREQ=DAILY;COUNT=3",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;UNTIL=20250630T215959Z",
+		"FREQ=MONTHLY;BYDAY=-1FR",
+		"FREQ=YEARLY;BYDAY=20MO;COUNT=2",
+		"FREQ=WEEKLY;BYDAY=SU,MO;WKST=SU",
+	} {
+		parsed, err := ParseRecurrenceRule("RRULE:"+rule, time.UTC)
+		if err != nil {
+			t.Errorf("Rule %q should be parsed. Instead: %v", rule, err)
+		} else if parsed.String() != rule {
+			t.Errorf("No expected rule:\nExpecting\t: %s\nRecieved\t: %s", rule, parsed.String())
+		}
+	}
+
+	for _, rule := range [7]string{
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;COUNT=2;UNTIL=20250101T000000Z",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=DAILY;INTERVAL=-1",
+	} {
+		if _, err := ParseRecurrenceRule(rule, time.UTC); !errors.Is(err, recurrenceRuleError) {
+			t.Errorf("Rule %q should not be parsed. Instead: %v", rule, err)
+		}
+	}
+
+	t.Run("UNTIL date", func(t *testing.T) {
+		parsed, _ := ParseRecurrenceRule("FREQ=DAILY;UNTIL=20250110", recurrenceTime(t, "2025-01-01 00:00").Location())
+		if expected := recurrenceTime(t, "2025-01-11 00:00").Add(-time.Nanosecond); !parsed.Until.Equal(expected) {
+			t.Errorf("No expected until:\nExpecting\t: %v\nRecieved\t: %v", expected, parsed.Until)
+		}
+	})
+}
+
+func TestRecurrenceRuleStarts(t *testing.T) {
+	type TestCase struct {
+		name     string
+		rule     string
+		dtstart  string
+		expected []string
+	}
+
+	testCases := [10]TestCase{
+		{"Daily", "FREQ=DAILY;COUNT=3", "2025-01-06 10:00", []string{"2025-01-06 10:00+01", "2025-01-07 10:00+01", "2025-01-08 10:00+01"}},
+		{"Weekly by day", "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=5", "2025-01-07 10:00",
+			[]string{"2025-01-07 10:00+01", "2025-01-09 10:00+01", "2025-01-14 10:00+01", "2025-01-16 10:00+01", "2025-01-21 10:00+01"}},
+		{"Every other week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=4", "2025-01-06 10:00",
+			[]string{"2025-01-06 10:00+01", "2025-01-10 10:00+01", "2025-01-20 10:00+01", "2025-01-24 10:00+01"}},
+		// RFC 5545 examples of WKST
+		{"Week starting on Monday", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO", "1997-08-05 09:00",
+			[]string{"1997-08-05 09:00+02", "1997-08-10 09:00+02", "1997-08-19 09:00+02", "1997-08-24 09:00+02"}},
+		{"Week starting on Sunday", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", "1997-08-05 09:00",
+			[]string{"1997-08-05 09:00+02", "1997-08-17 09:00+02", "1997-08-19 09:00+02", "1997-08-31 09:00+02"}},
+		{"Monthly skipping short months", "FREQ=MONTHLY;COUNT=4", "2025-01-31 10:00",
+			[]string{"2025-01-31 10:00+01", "2025-03-31 10:00+02", "2025-05-31 10:00+02", "2025-07-31 10:00+02"}},
+		{"Last Friday of the month", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", "2025-01-31 10:00",
+			[]string{"2025-01-31 10:00+01", "2025-02-28 10:00+01", "2025-03-28 10:00+01"}},
+		{"Start not matching the rule", "FREQ=MONTHLY;BYDAY=2TU;COUNT=3", "2025-01-01 10:00",
+			[]string{"2025-01-01 10:00+01", "2025-01-14 10:00+01", "2025-02-11 10:00+01"}},
+		{"Leap day", "FREQ=YEARLY;COUNT=3", "2024-02-29 10:00", []string{"2024-02-29 10:00+01", "2028-02-29 10:00+01", "2032-02-29 10:00+01"}},
+		{"Weekends until", "FREQ=DAILY;BYDAY=SA,SU;UNTIL=20250112T090000Z", "2025-01-06 10:00",
+			[]string{"2025-01-06 10:00+01", "2025-01-11 10:00+01", "2025-01-12 10:00+01"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dtstart := recurrenceTime(t, testCase.dtstart)
+			rule, err := ParseRecurrenceRule(testCase.rule, dtstart.Location())
+			if err != nil {
+				t.Fatalf("Rule should be parsed. Instead: %v", err)
+			}
+
+			starts := []string{}
+			for start := range rule.starts(dtstart) {
+				starts = append(starts, start.Format("2006-01-02 15:04-07"))
+				if len(starts) > len(testCase.expected) {
+					break
+				}
+			}
+			if strings.Join(starts, " ") != strings.Join(testCase.expected, " ") {
+				t.Errorf("No expected starts:\nExpecting\t: %v\nRecieved\t: %v", testCase.expected, starts)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package bookk
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	recurringOccurrenceNotFoundError = errors.New("Occurrence not found in the recurring booking")
+)
+
+/*
+Occurrence is a single time a RecurringBooking takes place.
+
+An occurrence is identified by its RecurrenceId, the start given to it by the series, as the
+RECURRENCE-ID property of RFC 5545. It keeps it even if the occurrence is moved.
+
+Occurrences are only taking place or cancelled, as told by Cancelled, see RecurringBooking.
+*/
+type Occurrence struct {
+	RecurrenceId time.Time
+	StartsAt     time.Time
+	EndsAt       time.Time
+	Description  string
+	Cancelled    bool
+}
+
+/*
+RecurringBooking is a booking repeating over time, such as a room booked every Tuesday from
+10:00 to 11:00 until June.
+
+StartsAt and EndsAt are the ones of the first occurrence, and every occurrence lasts as long.
+The occurrences are the starts given by the Rule, plus RDates, minus ExDates. Single occurrences
+are moved, described or cancelled by Changes.
+
+Recurring bookings are left out of the lifecycle of BookingState: a series and its occurrences
+are only taking place or cancelled, as the events of RFC 5545 are, and TransitionBooking does
+not move them. Occurrences are computed from the series rather than stored one by one, so they
+have no state of their own to be pending, checked in or completed, nor transitions to record.
+Their bookings, as OccurrenceBooking returns them, are confirmed or cancelled. Book single
+bookings instead when every time needs its own lifecycle.
+*/
+type RecurringBooking struct {
+	BaseBooking
+	Description string
+	Cancelled   bool            // Cancels every occurrence
+	Rule        *RecurrenceRule // How the booking repeats. Nil if it only takes place on StartsAt and RDates
+	RDates      []time.Time     // Starts of extra occurrences, as RDATE
+	ExDates     []time.Time     // Starts of occurrences removed from the series, as EXDATE
+	Changes     []Occurrence    // Occurrences changed one by one, identified by their RecurrenceId
+}
+
+func cloneRecurringBooking(booking *RecurringBooking) *RecurringBooking {
+	clone := *booking
+	if booking.Rule != nil {
+		rule := *booking.Rule
+		rule.ByDay = slices.Clone(rule.ByDay)
+		clone.Rule = &rule
+	}
+	clone.RDates = slices.Clone(booking.RDates)
+	clone.ExDates = slices.Clone(booking.ExDates)
+	clone.Changes = slices.Clone(booking.Changes)
+	return &clone
+}
+
+// Unlimited tells whether the booking repeats forever.
+func (b *RecurringBooking) Unlimited() bool {
+	return b.Rule != nil && b.Rule.Unlimited()
+}
+
+// starts lists the starts of the occurrences of the series up to until, or every start if until is nil
+func (b *RecurringBooking) starts(until *time.Time) []time.Time {
+	var starts []time.Time
+	if b.Rule == nil {
+		starts = append(starts, b.StartsAt)
+	} else {
+		for start := range b.Rule.starts(b.StartsAt) {
+			if until != nil && start.After(*until) {
+				break
+			}
+			starts = append(starts, start)
+		}
+	}
+	for _, date := range b.RDates {
+		if until == nil || !date.After(*until) {
+			starts = append(starts, date)
+		}
+	}
+
+	starts = slices.DeleteFunc(starts, func(start time.Time) bool {
+		return slices.ContainsFunc(b.ExDates, start.Equal)
+	})
+	slices.SortFunc(starts, time.Time.Compare)
+	return slices.CompactFunc(starts, time.Time.Equal)
+}
+
+/*
+IsOccurrence tells whether the series has an occurrence with a RecurrenceId.
+
+Parameters:
+  - recurrenceId: The start given to the occurrence by the series
+*/
+func (b *RecurringBooking) IsOccurrence(recurrenceId time.Time) bool {
+	return slices.ContainsFunc(b.starts(&recurrenceId), recurrenceId.Equal)
+}
+
+// change returns the change of an occurrence, or nil if it is not changed
+func (b *RecurringBooking) change(recurrenceId time.Time) *Occurrence {
+	for i := range b.Changes {
+		if b.Changes[i].RecurrenceId.Equal(recurrenceId) {
+			return &b.Changes[i]
+		}
+	}
+	return nil
+}
+
+/*
+Occurrences lists the occurrences of the series touching a window, with their changes applied.
+
+Occurrences whose start or end is at a bound of the window are included, whatever the bounds
+configuration. Cancelled occurrences are included too, and every occurrence is cancelled if
+the series is.
+
+Parameters:
+  - window: The time range to list occurrences in
+
+Returns:
+  - The occurrences, from the oldest to the newest StartsAt
+  - An error if the series repeats forever and the window has no upper bound
+*/
+func (b *RecurringBooking) Occurrences(window TimeRange) ([]Occurrence, error) {
+	if window.IsEmpty() {
+		return []Occurrence{}, nil
+	} else if b.Unlimited() && window.upperLimit != TimeRangeFinite {
+		return nil, recurrenceWindowError
+	}
+
+	var until *time.Time
+	if window.upperLimit == TimeRangeFinite {
+		until = &window.upperBound
+	}
+	closed := window
+	closed.boundsConf = TimeRangeBoundsInclusion
+	touches := func(occurrence Occurrence) bool {
+		r, err := NewTimeRange(occurrence.StartsAt, occurrence.EndsAt, TimeRangeBoundsInclusion)
+		return err == nil && r.Relation(&closed).SharesInstants()
+	}
+
+	occurrences := []Occurrence{}
+	duration := b.EndsAt.Sub(b.StartsAt)
+	for _, start := range b.starts(until) {
+		occurrence := Occurrence{RecurrenceId: start, StartsAt: start, EndsAt: start.Add(duration), Description: b.Description}
+		if b.change(start) == nil && touches(occurrence) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	// Changed occurrences may have been moved from outside the window
+	for _, change := range b.Changes {
+		if touches(change) && b.IsOccurrence(change.RecurrenceId) {
+			occurrences = append(occurrences, change)
+		}
+	}
+
+	for i := range occurrences {
+		occurrences[i].Cancelled = occurrences[i].Cancelled || b.Cancelled
+	}
+	slices.SortFunc(occurrences, func(a, b Occurrence) int {
+		return cmp.Or(a.StartsAt.Compare(b.StartsAt), a.RecurrenceId.Compare(b.RecurrenceId))
+	})
+	return occurrences, nil
+}
+
+/*
+Expand computes the time taken by the series within a window.
+
+Parameters:
+  - window: The time range to expand the series in
+
+Returns:
+  - The occurrences not cancelled as [StartsAt, EndsAt), within the window and normalized
+  - An error if the series repeats forever and the window has no upper bound
+*/
+func (b *RecurringBooking) Expand(window TimeRange) (MultiTimeRange, error) {
+	occurrences, err := b.Occurrences(window)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := MultiTimeRange{}
+	for _, occurrence := range occurrences {
+		if r, err := NewTimeRange(occurrence.StartsAt, occurrence.EndsAt, TimeRangeIlEu); err == nil && !occurrence.Cancelled {
+			taken = append(taken, r)
+		}
+	}
+	return taken.Intersect(NewMultiTimeRange(&window)), nil
+}
+
+/*
+OccurrenceBooking describes an occurrence as a Booking of the series, so it can be checked and
+listed along single bookings.
+
+Parameters:
+  - occurrence: An occurrence of the series
+
+Returns:
+  - A Booking with the id, user, item and creation time of the series and the time,
+    description of the occurrence, confirmed or cancelled as the occurrence is
+*/
+func (b *RecurringBooking) OccurrenceBooking(occurrence Occurrence) *Booking {
+	booking := &Booking{BaseBooking: b.BaseBooking, Description: occurrence.Description, State: occurrenceState(occurrence.Cancelled)}
+	booking.StartsAt, booking.EndsAt = occurrence.StartsAt, occurrence.EndsAt
+	return booking
+}
+
+// occurrenceState returns the state of a booking of an occurrence, which is only confirmed or cancelled
+func occurrenceState(cancelled bool) BookingState {
+	if cancelled {
+		return BookingCancelled
+	}
+	return BookingConfirmed
+}
+
+// validateChanges checks every change belongs to an occurrence of the series and does not end before it starts
+func (b *RecurringBooking) validateChanges() error {
+	for _, change := range b.Changes {
+		if !b.IsOccurrence(change.RecurrenceId) {
+			return fmt.Errorf("%w: %q at %s", recurringOccurrenceNotFoundError, b.Id, change.RecurrenceId.Format(time.RFC3339))
+		} else if change.EndsAt.Before(change.StartsAt) {
+			return fmt.Errorf("%w: booking %q at %s", bookingTimeRangeError, b.Id, change.RecurrenceId.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
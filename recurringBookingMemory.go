@@ -0,0 +1,360 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+var (
+	recurringBookingNotFoundError = errors.New("Recurring booking not found")
+)
+
+const defaultRecurrenceHorizon = 2 * 365 * 24 * time.Hour
+
+// idTaken tells whether a single or recurring booking has an id. It must be called holding the lock
+func (s *MemoryBookingService) idTaken(id string) bool {
+	_, single := s.bookings[id]
+	_, recurring := s.recurring[id]
+	return single || recurring
+}
+
+// seriesBookings lists the occurrences of the recurring bookings of an item sharing any instant with a time range, but the ones of a series. It must be called holding the lock
+func (s *MemoryBookingService) seriesBookings(itemId string, timeRange TimeRange, exceptId string) ([]*Booking, error) {
+	bookings := []*Booking{}
+	for id, series := range s.recurring {
+		if series.ItemId != itemId || id == exceptId {
+			continue
+		}
+		occurrences, err := series.Occurrences(timeRange)
+		if err != nil {
+			return nil, err
+		}
+		for _, occurrence := range occurrences {
+			booking := series.OccurrenceBooking(occurrence)
+			// Stored occurrences were checked to start before they end
+			if r, _ := s.checker.BookingTimeRange(booking); r.Relation(&timeRange).SharesInstants() {
+				bookings = append(bookings, booking)
+			}
+		}
+	}
+	return bookings, nil
+}
+
+/*
+GetRecurringBookingById returns a recurring booking.
+
+Parameters:
+  - bookingId: The id of the recurring booking
+
+Returns:
+  - A copy of the recurring booking
+  - An error if there is no recurring booking with the id
+*/
+func (s *MemoryBookingService) GetRecurringBookingById(bookingId string) (*RecurringBooking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series, found := s.recurring[bookingId]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", recurringBookingNotFoundError, bookingId)
+	}
+	return cloneRecurringBooking(series), nil
+}
+
+/*
+recurrenceWindow returns the time range a series is checked in. It must be called holding the lock.
+
+Limited series are checked from the start of their first occurrence to the end of their last
+one. Series repeating forever are checked up to the horizon from now, or from their start if it
+is later, and at least up to the end of every limited booking of the item, so nothing already
+booked is missed.
+*/
+func (s *MemoryBookingService) recurrenceWindow(series *RecurringBooking) (TimeRange, error) {
+	unbounded := TimeRange{lowerLimit: TimeRangeUnbounded, upperLimit: TimeRangeUnbounded}
+	if !series.Unlimited() {
+		occurrences, err := series.Occurrences(unbounded)
+		if err != nil || len(occurrences) == 0 {
+			return *EmptyTimeRange(), err
+		}
+		lower, upper := occurrences[0].StartsAt, occurrences[0].EndsAt
+		for _, occurrence := range occurrences {
+			upper = latest(upper, occurrence.EndsAt)
+		}
+		window, err := NewTimeRange(lower, upper, TimeRangeBoundsInclusion)
+		return *window, err
+	}
+
+	upper := latest(series.StartsAt, s.now()).Add(s.horizon)
+	for _, change := range series.Changes {
+		upper = latest(upper, change.EndsAt)
+	}
+	if tree, found := s.items[series.ItemId]; found {
+		for _, id := range tree.Overlapping(&unbounded) {
+			upper = latest(upper, s.bookings[id].EndsAt)
+		}
+	}
+	for _, other := range s.recurring {
+		if other.ItemId != series.ItemId || other.Id == series.Id || other.Unlimited() {
+			continue
+		}
+		occurrences, _ := other.Occurrences(unbounded)
+		for _, occurrence := range occurrences {
+			upper = latest(upper, occurrence.EndsAt)
+		}
+	}
+
+	window, err := NewTimeRangeUntil(upper, TimeRangeBoundsInclusion)
+	return *window, err
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+/*
+checkRecurringBooking checks a series as checkBooking does with every occurrence not cancelled,
+within the window given by recurrenceWindow. Occurrences may not collide with each other either,
+and series of items requiring approval are refused, as their occurrences cannot be pending.
+It must be called holding the lock.
+*/
+func (s *MemoryBookingService) checkRecurringBooking(series *RecurringBooking) error {
+	if series.Rule != nil {
+		if err := series.Rule.Validate(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.checker.BookingTimeRange(series.OccurrenceBooking(Occurrence{StartsAt: series.StartsAt, EndsAt: series.EndsAt})); err != nil {
+		return err
+	}
+	if err := series.validateChanges(); err != nil {
+		return err
+	}
+	if series.Cancelled {
+		return nil
+	}
+	if err := checkApprovalRequired(s.approvals, series.ItemId); err != nil {
+		return err
+	}
+
+	window, err := s.recurrenceWindow(series)
+	if err != nil {
+		return err
+	}
+	occurrences, err := series.Occurrences(window)
+	if err != nil {
+		return err
+	}
+
+	// Bookings of the item that may collide with the series
+	candidates, err := s.seriesBookings(series.ItemId, window, series.Id)
+	if err != nil {
+		return err
+	}
+	if tree, found := s.items[series.ItemId]; found {
+		for _, id := range tree.Overlapping(&window) {
+			candidates = append(candidates, s.bookings[id])
+		}
+	}
+	candidates = append(candidates, s.slotHoldBookings(series.ItemId, &window, "")...)
+	index := NewTimeRangeTree[int]()
+	for i, candidate := range candidates {
+		r, _ := s.checker.BookingTimeRange(candidate)
+		index.Insert(i, r)
+	}
+
+	var conflictingIds []string
+	var furthest *TimeRange
+	for _, occurrence := range occurrences {
+		if occurrence.Cancelled {
+			continue
+		}
+		booking := series.OccurrenceBooking(occurrence)
+		r, err := s.checker.BookingTimeRange(booking)
+		if err != nil {
+			return err
+		}
+		if err := checkOpeningHours(s.openingHours, booking, r); err != nil {
+			return err
+		}
+
+		// Occurrences are sorted by their start, so one collides with a previous one if it starts before the furthest end
+		if furthest != nil && r.Relation(furthest).SharesInstants() {
+			conflictingIds = append(conflictingIds, series.Id)
+		}
+		if furthest == nil || compareUpperBounds(r, furthest) > 0 {
+			furthest = r
+		}
+
+		var colliding []*Booking
+		for _, i := range index.Overlapping(r) {
+			colliding = append(colliding, candidates[i])
+		}
+		var conflict *ConflictError
+		if err := s.checker.Check(booking, colliding); errors.As(err, &conflict) {
+			conflictingIds = append(conflictingIds, conflict.ConflictingIds...)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if conflictingIds == nil {
+		return nil
+	}
+	slices.Sort(conflictingIds)
+	return &ConflictError{BookingId: series.Id, ItemId: series.ItemId, ConflictingIds: slices.Compact(conflictingIds)}
+}
+
+/*
+CreateRecurringBooking stores a new recurring booking.
+
+Every occurrence not cancelled is checked as single bookings are: it may not collide with other
+bookings of the item, single or recurring, nor with other occurrences of the series, and must
+be within opening hours. Series repeating forever are checked up to a horizon, see
+WithRecurrenceHorizon. An id is generated when the booking has none, and CreatedAt is set by the
+clock of the service when it is zero.
+
+Parameters:
+  - booking: The recurring booking to create
+
+Returns:
+  - A copy of the created recurring booking
+  - A *ConflictError if any occurrence collides with other bookings of the item, or another
+    error if the rule is not valid, the booking ends before it starts, a change is not an
+    occurrence of the series, an occurrence is outside opening hours, the item requires
+    approval of its bookings or the id is already taken
+*/
+func (s *MemoryBookingService) CreateRecurringBooking(booking RecurringBooking) (*RecurringBooking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireHolds()
+
+	if booking.Id == "" {
+		booking.Id = newRandomId()
+	} else if s.idTaken(booking.Id) {
+		return nil, fmt.Errorf("%w: %q", bookingAlreadyExistsError, booking.Id)
+	}
+	if booking.CreatedAt.IsZero() {
+		booking.CreatedAt = s.now()
+	}
+
+	series := cloneRecurringBooking(&booking)
+	if err := s.checkRecurringBooking(series); err != nil {
+		return nil, err
+	}
+	s.recurring[series.Id] = series
+	return cloneRecurringBooking(series), nil
+}
+
+/*
+UpdateRecurringBooking replaces a stored recurring booking, checking it as CreateRecurringBooking
+does. The CreatedAt of the stored booking is kept when the given one is zero.
+
+Parameters:
+  - booking: The recurring booking to update, identified by its id
+
+Returns:
+  - A *ConflictError if any occurrence collides with other bookings of the item, or another
+    error if the recurring booking does not exist or is not valid
+*/
+func (s *MemoryBookingService) UpdateRecurringBooking(booking *RecurringBooking) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireHolds()
+
+	previous, found := s.recurring[booking.Id]
+	if !found {
+		return fmt.Errorf("%w: %q", recurringBookingNotFoundError, booking.Id)
+	}
+	updated := cloneRecurringBooking(booking)
+	if updated.CreatedAt.IsZero() {
+		updated.CreatedAt = previous.CreatedAt
+	}
+
+	if err := s.checkRecurringBooking(updated); err != nil {
+		return err
+	}
+	s.recurring[updated.Id] = updated
+	return nil
+}
+
+/*
+UpdateOccurrence changes a single occurrence of a recurring booking, replacing its previous
+change if any. The series is checked again as CreateRecurringBooking does.
+
+Parameters:
+  - bookingId: The id of the recurring booking
+  - occurrence: The occurrence as it must be, identified by its RecurrenceId. Set Cancelled to
+    cancel only this occurrence
+
+Returns:
+  - A *ConflictError if the occurrence collides with other bookings of the item, or another
+    error if the recurring booking does not exist, the RecurrenceId is not one of its
+    occurrences or the occurrence ends before it starts
+*/
+func (s *MemoryBookingService) UpdateOccurrence(bookingId string, occurrence Occurrence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireHolds()
+
+	previous, found := s.recurring[bookingId]
+	if !found {
+		return fmt.Errorf("%w: %q", recurringBookingNotFoundError, bookingId)
+	}
+	updated := cloneRecurringBooking(previous)
+	if change := updated.change(occurrence.RecurrenceId); change != nil {
+		*change = occurrence
+	} else {
+		updated.Changes = append(updated.Changes, occurrence)
+	}
+
+	if err := s.checkRecurringBooking(updated); err != nil {
+		return err
+	}
+	s.recurring[updated.Id] = updated
+	return nil
+}
+
+/*
+DeleteRecurringBooking removes a recurring booking with all its occurrences.
+
+Parameters:
+  - bookingId: The id of the recurring booking
+
+Returns:
+  - An error if there is no recurring booking with the id
+*/
+func (s *MemoryBookingService) DeleteRecurringBooking(bookingId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.recurring[bookingId]; !found {
+		return fmt.Errorf("%w: %q", recurringBookingNotFoundError, bookingId)
+	}
+	delete(s.recurring, bookingId)
+	return nil
+}
+
+// restoreRecurringBooking stores a recurring booking as it is, without checking conflicts
+func (s *MemoryBookingService) restoreRecurringBooking(booking *RecurringBooking) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recurring[booking.Id] = cloneRecurringBooking(booking)
+}
+
+// recurringSnapshot copies every recurring booking, sorted by id
+func (s *MemoryBookingService) recurringSnapshot() []*RecurringBooking {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bookings := []*RecurringBooking{}
+	for _, id := range slices.Sorted(maps.Keys(s.recurring)) {
+		bookings = append(bookings, cloneRecurringBooking(s.recurring[id]))
+	}
+	return bookings
+}
@@ -0,0 +1,209 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRecurringBooking books an item every Tuesday from 10:00 to 11:00 since 2025-01-07
+func newTestRecurringBooking(t *testing.T, id, itemId, rule string) RecurringBooking {
+	t.Helper()
+	startsAt := recurrenceTime(t, "2025-01-07 10:00")
+	parsed, err := ParseRecurrenceRule(rule, startsAt.Location())
+	if err != nil {
+		t.Fatalf("Rule should be parsed. Instead: %v", err)
+	}
+	return RecurringBooking{
+		BaseBooking: BaseBooking{Id: id, UserId: "user", ItemId: itemId, StartsAt: startsAt, EndsAt: startsAt.Add(time.Hour)},
+		Rule:        parsed,
+	}
+}
+
+func occurrencesRepr(occurrences []Occurrence) string {
+	repr := []string{}
+	for _, occurrence := range occurrences {
+		value := occurrence.StartsAt.Format("01-02 15:04")
+		if occurrence.Cancelled {
+			value += " cancelled"
+		}
+		repr = append(repr, value)
+	}
+	return strings.Join(repr, ", ")
+}
+
+func TestRecurringBookingOccurrences(t *testing.T) {
+	series := newTestRecurringBooking(t, "series", "room", "FREQ=WEEKLY;COUNT=5")
+	series.ExDates = []time.Time{recurrenceTime(t, "2025-01-14 10:00")}
+	series.RDates = []time.Time{recurrenceTime(t, "2025-01-16 10:00")}
+	series.Changes = []Occurrence{
+		{RecurrenceId: recurrenceTime(t, "2025-01-21 10:00"), StartsAt: recurrenceTime(t, "2025-01-21 12:00"), EndsAt: recurrenceTime(t, "2025-01-21 13:00")},
+		{RecurrenceId: recurrenceTime(t, "2025-01-28 10:00"), StartsAt: recurrenceTime(t, "2025-01-28 10:00"), EndsAt: recurrenceTime(t, "2025-01-28 11:00"), Cancelled: true},
+	}
+	window := func(lower, upper string) TimeRange {
+		r, _ := NewTimeRange(recurrenceTime(t, lower), recurrenceTime(t, upper), TimeRangeIlEu)
+		return *r
+	}
+
+	t.Run("Whole series", func(t *testing.T) {
+		occurrences, err := series.Occurrences(window("2025-01-01 00:00", "2025-03-01 00:00"))
+		if err != nil {
+			t.Fatalf("Occurrences should be listed. Instead: %v", err)
+		}
+		expected := "01-07 10:00, 01-16 10:00, 01-21 12:00, 01-28 10:00 cancelled, 02-04 10:00"
+		if repr := occurrencesRepr(occurrences); repr != expected {
+			t.Errorf("No expected occurrences:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+		}
+	})
+
+	t.Run("Expand", func(t *testing.T) {
+		taken, _ := series.Expand(window("2025-01-07 10:30", "2025-01-21 12:30"))
+		expected := "[10:30:00, 11:00:00) [10:00:00, 11:00:00) [12:00:00, 12:30:00)"
+		repr := []string{}
+		for _, r := range taken {
+			repr = append(repr, fmt.Sprintf("[%s, %s)", r.LowerBound().Format(time.TimeOnly), r.UpperBound().Format(time.TimeOnly)))
+		}
+		if strings.Join(repr, " ") != expected {
+			t.Errorf("No expected ranges:\nExpecting\t: %s\nRecieved\t: %s", expected, strings.Join(repr, " "))
+		}
+	})
+
+	t.Run("Moved into the window", func(t *testing.T) {
+		moved := cloneRecurringBooking(&series)
+		moved.Changes[0].StartsAt, moved.Changes[0].EndsAt = recurrenceTime(t, "2025-01-08 10:00"), recurrenceTime(t, "2025-01-08 11:00")
+		occurrences, _ := moved.Occurrences(window("2025-01-08 00:00", "2025-01-09 00:00"))
+		if repr := occurrencesRepr(occurrences); repr != "01-08 10:00" {
+			t.Errorf("Moved occurrence should be listed. Instead: %s", repr)
+		}
+	})
+
+	t.Run("Cancelled series", func(t *testing.T) {
+		cancelled := cloneRecurringBooking(&series)
+		cancelled.Cancelled = true
+		if taken, _ := cancelled.Expand(window("2025-01-01 00:00", "2025-03-01 00:00")); len(taken) != 0 {
+			t.Errorf("Cancelled series should take no time. Instead: %v", taken)
+		}
+	})
+
+	if !series.IsOccurrence(recurrenceTime(t, "2025-02-04 10:00")) || series.IsOccurrence(recurrenceTime(t, "2025-01-14 10:00")) ||
+		series.IsOccurrence(recurrenceTime(t, "2025-02-11 10:00")) {
+		t.Errorf("Occurrences should be the rule starts plus RDates minus ExDates")
+	}
+
+	unlimited := newTestRecurringBooking(t, "unlimited", "room", "FREQ=WEEKLY")
+	since, _ := NewTimeRangeFrom(recurrenceTime(t, "2025-01-01 00:00"), TimeRangeIlEu)
+	if _, err := unlimited.Occurrences(*since); !errors.Is(err, recurrenceWindowError) {
+		t.Errorf("Unlimited series should not be expanded over an infinite window. Instead: %v", err)
+	}
+}
+
+func TestMemoryBookingServiceRecurringBookings(t *testing.T) {
+	service := NewMemoryBookingService(WithClock(func() time.Time { return recurrenceTime(t, "2025-01-01 00:00") }))
+	booking := func(id, itemId, startsAt, endsAt string) Booking {
+		return Booking{BaseBooking: BaseBooking{Id: id, UserId: "user", ItemId: itemId, StartsAt: recurrenceTime(t, startsAt), EndsAt: recurrenceTime(t, endsAt)}}
+	}
+	conflictingIds := func(err error) []string {
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) {
+			return nil
+		}
+		return conflict.ConflictingIds
+	}
+
+	weekly, err := service.CreateRecurringBooking(newTestRecurringBooking(t, "weekly", "room", "FREQ=WEEKLY"))
+	if err != nil {
+		t.Fatalf("Recurring booking should be created. Instead: %v", err)
+	}
+
+	t.Run("Single booking colliding with an occurrence", func(t *testing.T) {
+		_, err := service.CreateBooking(booking("single", "room", "2025-03-04 10:30", "2025-03-04 11:30"))
+		if ids := conflictingIds(err); !slices.Equal(ids, []string{weekly.Id}) {
+			t.Errorf("No expected conflict:\nExpecting\t: %v\nRecieved\t: %v", []string{weekly.Id}, err)
+		}
+		if _, err := service.CreateBooking(booking("wednesday", "room", "2025-01-22 10:00", "2025-01-22 11:00")); err != nil {
+			t.Errorf("Booking should be created. Instead: %v", err)
+		}
+	})
+
+	t.Run("Series colliding with a series", func(t *testing.T) {
+		daily := newTestRecurringBooking(t, "daily", "room", "FREQ=DAILY;COUNT=10")
+		daily.StartsAt, daily.EndsAt = recurrenceTime(t, "2025-01-06 10:00"), recurrenceTime(t, "2025-01-06 10:30")
+		if _, err := service.CreateRecurringBooking(daily); !slices.Equal(conflictingIds(err), []string{weekly.Id}) {
+			t.Errorf("Series should collide with the weekly series. Instead: %v", err)
+		}
+
+		daily.ExDates = []time.Time{recurrenceTime(t, "2025-01-07 10:00"), recurrenceTime(t, "2025-01-14 10:00")}
+		if _, err := service.CreateRecurringBooking(daily); err != nil {
+			t.Errorf("Series without colliding occurrences should be created. Instead: %v", err)
+		}
+	})
+
+	t.Run("Changing an occurrence", func(t *testing.T) {
+		moved := Occurrence{RecurrenceId: recurrenceTime(t, "2025-01-21 10:00"), StartsAt: recurrenceTime(t, "2025-01-22 10:30"), EndsAt: recurrenceTime(t, "2025-01-22 11:30")}
+		if err := service.UpdateOccurrence(weekly.Id, moved); !slices.Equal(conflictingIds(err), []string{"wednesday"}) {
+			t.Errorf("Occurrence should collide with the Wednesday booking. Instead: %v", err)
+		}
+
+		cancelled := Occurrence{RecurrenceId: recurrenceTime(t, "2025-01-28 10:00"), StartsAt: recurrenceTime(t, "2025-01-28 10:00"), EndsAt: recurrenceTime(t, "2025-01-28 11:00"), Cancelled: true}
+		if err := service.UpdateOccurrence(weekly.Id, cancelled); err != nil {
+			t.Fatalf("Occurrence should be cancelled. Instead: %v", err)
+		}
+		if _, err := service.CreateBooking(booking("instead", "room", "2025-01-28 10:00", "2025-01-28 11:00")); err != nil {
+			t.Errorf("Booking should take the time of the cancelled occurrence. Instead: %v", err)
+		}
+
+		unknown := Occurrence{RecurrenceId: recurrenceTime(t, "2025-01-29 10:00"), StartsAt: recurrenceTime(t, "2025-01-29 10:00"), EndsAt: recurrenceTime(t, "2025-01-29 11:00")}
+		if err := service.UpdateOccurrence(weekly.Id, unknown); !errors.Is(err, recurringOccurrenceNotFoundError) {
+			t.Errorf("Only occurrences of the series should be changed. Instead: %v", err)
+		}
+	})
+
+	t.Run("Bookings of the item", func(t *testing.T) {
+		day, _ := NewTimeRange(recurrenceTime(t, "2025-01-28 00:00"), recurrenceTime(t, "2025-01-29 00:00"), TimeRangeIlEu)
+		bookings, err := service.GetBookingsByTimeRangeAndItemId("room", *day)
+		if err != nil {
+			t.Fatalf("Bookings should be listed. Instead: %v", err)
+		}
+		if ids := bookingIds(bookings); !slices.Equal(ids, []string{"instead", weekly.Id}) || bookings[1].State != BookingCancelled {
+			t.Errorf("No expected bookings:\nExpecting\t: %v\nRecieved\t: %v", []string{"instead", weekly.Id}, ids)
+		}
+	})
+
+	t.Run("Occurrences colliding with each other", func(t *testing.T) {
+		overlapping := newTestRecurringBooking(t, "overlapping", "desk", "FREQ=DAILY;COUNT=2")
+		overlapping.EndsAt = overlapping.StartsAt.Add(25 * time.Hour)
+		if _, err := service.CreateRecurringBooking(overlapping); !slices.Equal(conflictingIds(err), []string{"overlapping"}) {
+			t.Errorf("Series should collide with itself. Instead: %v", err)
+		}
+	})
+
+	t.Run("Bookings beyond the horizon", func(t *testing.T) {
+		if _, err := service.CreateBooking(booking("future", "hall", "2030-01-01 10:00", "2030-01-01 11:00")); err != nil {
+			t.Fatalf("Booking should be created. Instead: %v", err)
+		}
+		if _, err := service.CreateRecurringBooking(newTestRecurringBooking(t, "", "hall", "FREQ=WEEKLY")); !slices.Equal(conflictingIds(err), []string{"future"}) {
+			t.Errorf("Series should collide with bookings beyond the horizon. Instead: %v", err)
+		}
+	})
+
+	t.Run("Shared ids", func(t *testing.T) {
+		if _, err := service.CreateBooking(booking(weekly.Id, "desk", "2025-01-01 10:00", "2025-01-01 11:00")); !errors.Is(err, bookingAlreadyExistsError) {
+			t.Errorf("Single bookings should not take the id of a series. Instead: %v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := service.DeleteRecurringBooking(weekly.Id); err != nil {
+			t.Fatalf("Recurring booking should be deleted. Instead: %v", err)
+		}
+		if _, err := service.GetRecurringBookingById(weekly.Id); !errors.Is(err, recurringBookingNotFoundError) {
+			t.Errorf("Deleted recurring booking should not be found. Instead: %v", err)
+		}
+		if _, err := service.CreateBooking(booking("single", "room", "2025-03-04 10:30", "2025-03-04 11:30")); err != nil {
+			t.Errorf("Booking should be created once the series is deleted. Instead: %v", err)
+		}
+	})
+}
@@ -0,0 +1,313 @@
+package bookk
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	slotHoldNotFoundError  = errors.New("Slot hold not found")
+	slotHoldMismatchError  = errors.New("Booking is not the one held")
+	slotHoldTimeRangeError = errors.New("Slot hold must have finite bounds")
+	slotHoldTTLError       = errors.New("Slot hold must last some time")
+	slotHoldIntervalError  = errors.New("Slot holds must be reaped every some time")
+)
+
+/*
+SlotHold reserves the time of an item for a while, such as during checkout, so nobody else books
+it meanwhile. The hold is booked or released with its token, and expires on its own.
+
+Holds are kept by MemoryBookingService and the FileBookingService of a FileStore in memory, so
+they are lost on restart, and by PostgresBookingService in the slot_holds table, so they are seen
+by every service sharing the database. They are not part of IBookingService.
+*/
+type SlotHold struct {
+	Id        string // Listed by a *ConflictError when a booking collides with the hold
+	Token     string // Secret the hold is booked or released with
+	UserId    string
+	ItemId    string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	ExpiresAt time.Time // When the hold stops taking the time of the item
+}
+
+func cloneSlotHold(hold *SlotHold) *SlotHold {
+	clone := *hold
+	return &clone
+}
+
+// newSlotHold creates a hold of a time range for a while, checking the range is finite and the hold lasts some time
+func newSlotHold(userId, itemId string, timeRange TimeRange, ttl time.Duration, now time.Time) (*SlotHold, error) {
+	if timeRange.IsEmpty() || timeRange.lowerLimit != TimeRangeFinite || timeRange.upperLimit != TimeRangeFinite {
+		return nil, slotHoldTimeRangeError
+	} else if ttl <= 0 {
+		return nil, slotHoldTTLError
+	}
+	return &SlotHold{
+		Id:        newRandomId(),
+		Token:     newRandomId(),
+		UserId:    userId,
+		ItemId:    itemId,
+		StartsAt:  timeRange.LowerBound(),
+		EndsAt:    timeRange.UpperBound(),
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+// fill gives a booking the item, time and user of the hold when they are not given, and returns an error if the booking is not for the item and time of the hold
+func (h *SlotHold) fill(booking *Booking) error {
+	if booking.ItemId == "" {
+		booking.ItemId = h.ItemId
+	}
+	if booking.StartsAt.IsZero() && booking.EndsAt.IsZero() {
+		booking.StartsAt, booking.EndsAt = h.StartsAt, h.EndsAt
+	}
+	if booking.UserId == "" {
+		booking.UserId = h.UserId
+	}
+	if booking.ItemId != h.ItemId || !booking.StartsAt.Equal(h.StartsAt) || !booking.EndsAt.Equal(h.EndsAt) {
+		return fmt.Errorf("%w: hold %q", slotHoldMismatchError, h.Id)
+	}
+	return nil
+}
+
+// booking describes the hold as a confirmed booking, so it is checked along bookings
+func (h *SlotHold) booking() *Booking {
+	return &Booking{
+		BaseBooking: BaseBooking{Id: h.Id, UserId: h.UserId, ItemId: h.ItemId, StartsAt: h.StartsAt, EndsAt: h.EndsAt},
+		State:       BookingConfirmed,
+	}
+}
+
+// expired tells whether the hold is over at a given time
+func (h *SlotHold) expired(now time.Time) bool {
+	return !h.ExpiresAt.After(now)
+}
+
+// itemSlotHolds lists the holds of an item not expired sharing any instant with a time range, but the one with a token. It must be called holding the lock
+func (s *MemoryBookingService) itemSlotHolds(itemId string, timeRange *TimeRange, exceptToken string) []*SlotHold {
+	now := s.now()
+	holds := []*SlotHold{}
+	for token, hold := range s.slotHolds {
+		if token == exceptToken || hold.ItemId != itemId || hold.expired(now) {
+			continue
+		}
+		// Stored holds were checked to start before they end
+		if r, _ := s.checker.BookingTimeRange(hold.booking()); r.Relation(timeRange).SharesInstants() {
+			holds = append(holds, hold)
+		}
+	}
+	return holds
+}
+
+// slotHoldBookings lists the holds of an item as itemSlotHolds does, described as bookings. It must be called holding the lock
+func (s *MemoryBookingService) slotHoldBookings(itemId string, timeRange *TimeRange, exceptToken string) []*Booking {
+	bookings := []*Booking{}
+	for _, hold := range s.itemSlotHolds(itemId, timeRange, exceptToken) {
+		bookings = append(bookings, hold.booking())
+	}
+	return bookings
+}
+
+// slotHold returns a stored hold not expired. It must be called holding the lock
+func (s *MemoryBookingService) slotHold(token string) (*SlotHold, error) {
+	hold, found := s.slotHolds[token]
+	if !found || hold.expired(s.now()) {
+		// The token is not quoted, as it is a secret
+		return nil, slotHoldNotFoundError
+	}
+	return hold, nil
+}
+
+/*
+GetSlotHoldsByTimeRangeAndItemId returns the holds of an item not expired sharing any instant
+with a time range, so the time they take is known, as Availability does.
+
+Parameters:
+  - itemId: The id of the item
+  - timeRange: The time range to look for holds in
+
+Returns:
+  - Copies of the holds, without their token, from the oldest to the newest StartsAt
+  - An error, never returned by this implementation
+*/
+func (s *MemoryBookingService) GetSlotHoldsByTimeRangeAndItemId(itemId string, timeRange TimeRange) ([]*SlotHold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	holds := []*SlotHold{}
+	for _, hold := range s.itemSlotHolds(itemId, &timeRange, "") {
+		// Tokens are secrets of the ones holding the time
+		hold = cloneSlotHold(hold)
+		hold.Token = ""
+		holds = append(holds, hold)
+	}
+	slices.SortFunc(holds, func(a, b *SlotHold) int {
+		return cmp.Or(a.StartsAt.Compare(b.StartsAt), cmp.Compare(a.Id, b.Id))
+	})
+	return holds, nil
+}
+
+/*
+HoldSlot reserves the time of an item for a while, as a booking would take it. The time is
+checked as CreateBooking does, and while the hold lasts, bookings and other holds colliding with
+it are a *ConflictError listing the hold id.
+
+Parameters:
+  - userId: The id of the user holding the time
+  - itemId: The id of the item
+  - timeRange: The time held. Its bounds must be finite, and it is taken as [StartsAt, EndsAt)
+    of a booking with the bounds configuration of the ConflictChecker
+  - ttl: How long the hold lasts
+
+Returns:
+  - A copy of the hold, with its token
+  - A *ConflictError if the time collides with bookings or holds of the item, or another error
+    if the time range is not finite, the hold lasts no time or the time is outside opening hours
+*/
+func (s *MemoryBookingService) HoldSlot(userId, itemId string, timeRange TimeRange, ttl time.Duration) (*SlotHold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireHolds()
+
+	hold, err := newSlotHold(userId, itemId, timeRange, ttl, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkBooking(hold.booking(), ""); err != nil {
+		return nil, err
+	}
+	s.slotHolds[hold.Token] = hold
+	return cloneSlotHold(hold), nil
+}
+
+/*
+GetSlotHold returns a hold.
+
+Parameters:
+  - token: The token of the hold
+
+Returns:
+  - A copy of the hold
+  - An error if there is no hold with the token or it expired
+*/
+func (s *MemoryBookingService) GetSlotHold(token string) (*SlotHold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hold, err := s.slotHold(token)
+	if err != nil {
+		return nil, err
+	}
+	return cloneSlotHold(hold), nil
+}
+
+/*
+ReleaseSlotHold removes a hold before it expires, freeing its time.
+
+Parameters:
+  - token: The token of the hold
+
+Returns:
+  - An error if there is no hold with the token or it expired
+*/
+func (s *MemoryBookingService) ReleaseSlotHold(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.slotHold(token); err != nil {
+		return err
+	}
+	delete(s.slotHolds, token)
+	return nil
+}
+
+/*
+BookSlotHold turns a hold into a booking at once, so nobody books its time in between.
+
+The booking is created as CreateBooking does, taking the item, time and user of the hold when
+they are not given, and the hold is removed.
+
+Parameters:
+  - token: The token of the hold
+  - booking: The booking to create
+
+Returns:
+  - A copy of the created booking
+  - An error if there is no hold with the token or it expired, the booking is not for the item
+    and time of the hold, or the booking cannot be created
+*/
+func (s *MemoryBookingService) BookSlotHold(token string, booking Booking) (*Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireHolds()
+
+	hold, err := s.slotHold(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := hold.fill(&booking); err != nil {
+		return nil, err
+	}
+
+	created, err := s.create(booking, token)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.slotHolds, token)
+	return created, nil
+}
+
+/*
+ExpireSlotHolds removes the holds that expired. Expired holds never take the time of their item,
+so it only frees the memory they use.
+
+Returns:
+  - The number of holds removed
+*/
+func (s *MemoryBookingService) ExpireSlotHolds() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now, expired := s.now(), 0
+	for token, hold := range s.slotHolds {
+		if hold.expired(now) {
+			delete(s.slotHolds, token)
+			expired++
+		}
+	}
+	return expired
+}
+
+/*
+ReapSlotHolds removes the holds that expired every interval, until the context is done. It blocks,
+so it is meant to run in its own goroutine:
+
+	go service.ReapSlotHolds(ctx, time.Minute)
+
+Parameters:
+  - ctx: The context stopping the reaper when done
+  - interval: How often expired holds are removed. It must be greater than 0
+
+Returns:
+  - An error at once if the interval is not greater than 0, or the error of the context once it is done
+*/
+func (s *MemoryBookingService) ReapSlotHolds(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: %s", slotHoldIntervalError, interval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.ExpireSlotHolds()
+		}
+	}
+}
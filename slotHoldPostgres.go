@@ -0,0 +1,254 @@
+package bookk
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const postgresSlotHoldColumns = "id, token, user_id, item_id, period, expires_at"
+
+// scanPostgresSlotHold reads a hold from a row with the columns of postgresSlotHoldColumns
+func scanPostgresSlotHold(row interface{ Scan(...any) error }) (*SlotHold, error) {
+	var hold SlotHold
+	var period TimeRange
+	if err := row.Scan(&hold.Id, &hold.Token, &hold.UserId, &hold.ItemId, &period, &hold.ExpiresAt); err != nil {
+		return nil, err
+	}
+	hold.StartsAt, hold.EndsAt = period.LowerBound(), period.UpperBound()
+	return &hold, nil
+}
+
+// lockPostgresItem makes the changes of the bookings and holds of an item wait for the transaction to end
+func lockPostgresItem(tx *sql.Tx, itemId string) error {
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('bookk.item:' || $1))", itemId)
+	return err
+}
+
+// collidingSlotHoldIds reads the ids of the holds not expired an active booking collides with, none if the booking is stored inactive
+func (s *PostgresBookingService) collidingSlotHoldIds(tx *sql.Tx, booking *Booking, period *TimeRange) ([]string, error) {
+	rows, err := tx.Query(
+		"SELECT id FROM slot_holds WHERE item_id = $1 AND period && $2::tstzrange AND expires_at > $3 "+
+			"AND NOT EXISTS (SELECT 1 FROM bookings WHERE id = $4 AND NOT "+postgresActiveStates+") ORDER BY lower(period), id",
+		booking.ItemId, period, s.now(), booking.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+/*
+GetSlotHoldsByTimeRangeAndItemId returns the holds of an item not expired sharing any instant
+with a time range, so the time they take is known, as Availability does.
+
+Parameters:
+  - itemId: The id of the item
+  - timeRange: The time range to look for holds in
+
+Returns:
+  - The holds, without their token, from the oldest to the newest StartsAt
+  - An error if the query fails
+*/
+func (s *PostgresBookingService) GetSlotHoldsByTimeRangeAndItemId(itemId string, timeRange TimeRange) ([]*SlotHold, error) {
+	rows, err := s.db.Query(
+		"SELECT "+postgresSlotHoldColumns+" FROM slot_holds WHERE item_id = $1 AND period && $2::tstzrange AND expires_at > $3 ORDER BY lower(period), id",
+		itemId, timeRange, s.now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []*SlotHold{}
+	for rows.Next() {
+		hold, err := scanPostgresSlotHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		// Tokens are secrets of the ones holding the time
+		hold.Token = ""
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+/*
+HoldSlot reserves the time of an item for a while, as a booking would take it. The time is
+checked as CreateBooking does, and while the hold lasts, bookings and other holds colliding with
+it are a *ConflictError listing the hold id, for every service sharing the database.
+
+Parameters:
+  - userId: The id of the user holding the time
+  - itemId: The id of the item
+  - timeRange: The time held. Its bounds must be finite, and it is stored as [StartsAt, EndsAt)
+  - ttl: How long the hold lasts
+
+Returns:
+  - The hold, with its token
+  - A *ConflictError if the time collides with bookings or holds of the item, or another error
+    if the time range is not finite, the hold lasts no time, the time is outside opening hours
+    or a statement fails
+*/
+func (s *PostgresBookingService) HoldSlot(userId, itemId string, timeRange TimeRange, ttl time.Duration) (*SlotHold, error) {
+	hold, err := newSlotHold(userId, itemId, timeRange, ttl, s.now())
+	if err != nil {
+		return nil, err
+	}
+	booking := hold.booking()
+	period, err := bookingPeriod(booking)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOpeningHours(s.openingHours, booking, period); err != nil {
+		return nil, err
+	}
+	if err := s.expireHolds(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockPostgresItem(tx, itemId); err != nil {
+		return nil, err
+	}
+	conflict, err := conflictError(tx, booking, period)
+	if err != nil {
+		return nil, err
+	}
+	holdIds, err := s.collidingSlotHoldIds(tx, booking, period)
+	if err != nil {
+		return nil, err
+	}
+	if conflict.ConflictingIds = append(conflict.ConflictingIds, holdIds...); len(conflict.ConflictingIds) > 0 {
+		return nil, conflict
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO slot_holds ("+postgresSlotHoldColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+		hold.Id, hold.Token, hold.UserId, hold.ItemId, period, hold.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+	return hold, tx.Commit()
+}
+
+/*
+GetSlotHold returns a hold.
+
+Parameters:
+  - token: The token of the hold
+
+Returns:
+  - The hold
+  - An error if there is no hold with the token, it expired or the query fails
+*/
+func (s *PostgresBookingService) GetSlotHold(token string) (*SlotHold, error) {
+	hold, err := scanPostgresSlotHold(s.db.QueryRow(
+		"SELECT "+postgresSlotHoldColumns+" FROM slot_holds WHERE token = $1 AND expires_at > $2", token, s.now(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		// The token is not quoted, as it is a secret
+		return nil, slotHoldNotFoundError
+	}
+	return hold, err
+}
+
+/*
+ReleaseSlotHold removes a hold before it expires, freeing its time.
+
+Parameters:
+  - token: The token of the hold
+
+Returns:
+  - An error if there is no hold with the token, it expired or the statement fails
+*/
+func (s *PostgresBookingService) ReleaseSlotHold(token string) error {
+	result, err := s.db.Exec("DELETE FROM slot_holds WHERE token = $1 AND expires_at > $2", token, s.now())
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return slotHoldNotFoundError
+	}
+	return nil
+}
+
+/*
+BookSlotHold turns a hold into a booking at once, so nobody books its time in between. The hold
+is removed and the booking created in a single transaction, so the hold is kept if the booking
+cannot be created.
+
+The booking is created as CreateBooking does, taking the item, time and user of the hold when
+they are not given.
+
+Parameters:
+  - token: The token of the hold
+  - booking: The booking to create
+
+Returns:
+  - The created booking
+  - An error if there is no hold with the token or it expired, the booking is not for the item
+    and time of the hold, or the booking cannot be created
+*/
+func (s *PostgresBookingService) BookSlotHold(token string, booking Booking) (*Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, err := scanPostgresSlotHold(tx.QueryRow(
+		"DELETE FROM slot_holds WHERE token = $1 AND expires_at > $2 RETURNING "+postgresSlotHoldColumns, token, s.now(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, slotHoldNotFoundError
+	} else if err != nil {
+		return nil, err
+	}
+	if err := hold.fill(&booking); err != nil {
+		return nil, err
+	}
+
+	period, transitions, err := s.prepareBooking(&booking)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.insertBooking(tx, &booking, period, transitions); err != nil {
+		return nil, err
+	}
+	return &booking, tx.Commit()
+}
+
+/*
+ExpireSlotHolds removes the holds that expired. Expired holds never take the time of their item,
+so it only frees the space they use, and may be run every some time.
+
+Returns:
+  - The number of holds removed
+  - An error if the statement fails
+*/
+func (s *PostgresBookingService) ExpireSlotHolds() (int, error) {
+	result, err := s.db.Exec("DELETE FROM slot_holds WHERE expires_at <= $1", s.now())
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
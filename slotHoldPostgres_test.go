@@ -0,0 +1,157 @@
+package bookk
+
+import (
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+var postgresSlotHoldColumnNames = []string{"id", "token", "user_id", "item_id", "period", "expires_at"}
+
+func TestPostgresBookingServiceSlotHolds(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	service := NewPostgresBookingService(db, WithPostgresClock(func() time.Time { return now }))
+	slot, _ := NewTimeRange(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC), TimeRangeIlEu)
+	period := "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")"
+	expiresAt := now.Add(10 * time.Minute)
+
+	t.Run("Hold", func(t *testing.T) {
+		fake.expect("BEGIN")
+		fake.expect("pg_advisory_xact_lock", "item-1")
+		fake.expect("FOR SHARE").willReturnRows([]string{"id"})
+		fake.expect("FROM slot_holds").willReturnRows([]string{"id"})
+		insert := fake.expect("INSERT INTO slot_holds").willAffect(1)
+		fake.expect("COMMIT")
+
+		hold, err := service.HoldSlot("user-1", "item-1", *slot, 10*time.Minute)
+		if err != nil || hold.Token == "" || hold.Id == "" || !hold.ExpiresAt.Equal(expiresAt) {
+			t.Fatalf("Slot should be held. Instead: %+v %v", hold, err)
+		}
+		expected := []driver.Value{hold.Id, hold.Token, "user-1", "item-1", period, expiresAt}
+		if !fakeArgsEqual(expected, insert.received) {
+			t.Errorf("No expected insertion:\nExpecting\t: %v\nRecieved\t: %v", expected, insert.received)
+		}
+	})
+
+	t.Run("Hold conflict", func(t *testing.T) {
+		fake.expect("BEGIN")
+		fake.expect("pg_advisory_xact_lock")
+		fake.expect("FOR SHARE").willReturnRows([]string{"id"}, []driver.Value{"a"})
+		fake.expect("FROM slot_holds").willReturnRows([]string{"id"}, []driver.Value{"hold-1"})
+		fake.expect("ROLLBACK")
+
+		var conflict *ConflictError
+		if _, err := service.HoldSlot("user-2", "item-1", *slot, time.Minute); !errors.As(err, &conflict) ||
+			!slices.Equal(conflict.ConflictingIds, []string{"a", "hold-1"}) {
+			t.Errorf("Hold should conflict with a and hold-1. Instead: %v", err)
+		}
+	})
+
+	t.Run("Booking conflict", func(t *testing.T) {
+		fake.expect("BEGIN")
+		fake.expect("pg_advisory_xact_lock")
+		fake.expect("FROM slot_holds").willReturnRows([]string{"id"}, []driver.Value{"hold-1"})
+		fake.expect("FOR SHARE").willReturnRows([]string{"id"})
+		fake.expect("ROLLBACK")
+
+		var conflict *ConflictError
+		_, err := service.CreateBooking(Booking{BaseBooking: BaseBooking{
+			UserId: "user-2", ItemId: "item-1", StartsAt: slot.LowerBound(), EndsAt: slot.UpperBound(),
+		}})
+		if !errors.As(err, &conflict) || !slices.Equal(conflict.ConflictingIds, []string{"hold-1"}) {
+			t.Errorf("Booking should conflict with hold-1. Instead: %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		unbounded, _ := NewTimeRangeFrom(slot.LowerBound(), TimeRangeIlEu)
+		if _, err := service.HoldSlot("user-1", "item-1", *unbounded, time.Minute); !errors.Is(err, slotHoldTimeRangeError) {
+			t.Errorf("Unbounded time should not be held. Instead: %v", err)
+		}
+		if _, err := service.HoldSlot("user-1", "item-1", *slot, 0); !errors.Is(err, slotHoldTTLError) {
+			t.Errorf("Hold lasting no time should fail. Instead: %v", err)
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		fake.expect("FROM slot_holds WHERE token = $1", "token-1", now).
+			willReturnRows(postgresSlotHoldColumnNames, []driver.Value{"hold-1", "token-1", "user-1", "item-1", period, expiresAt})
+		hold, err := service.GetSlotHold("token-1")
+		if err != nil || hold.Id != "hold-1" || hold.Token != "token-1" || !hold.StartsAt.Equal(slot.LowerBound()) ||
+			!hold.EndsAt.Equal(slot.UpperBound()) || !hold.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("Hold should be read. Instead: %+v %v", hold, err)
+		}
+
+		fake.expect("FROM slot_holds WHERE token = $1", "missing", now).willReturnRows(postgresSlotHoldColumnNames)
+		if _, err := service.GetSlotHold("missing"); !errors.Is(err, slotHoldNotFoundError) {
+			t.Errorf("Missing hold should not be read. Instead: %v", err)
+		}
+
+		fake.expect("FROM slot_holds WHERE item_id = $1", "item-1", period, now).
+			willReturnRows(postgresSlotHoldColumnNames, []driver.Value{"hold-1", "token-1", "user-1", "item-1", period, expiresAt})
+		holds, err := service.GetSlotHoldsByTimeRangeAndItemId("item-1", *slot)
+		if err != nil || len(holds) != 1 || holds[0].Id != "hold-1" || holds[0].Token != "" {
+			t.Errorf("Hold should be read without its token. Instead: %+v %v", holds, err)
+		}
+	})
+
+	t.Run("Book", func(t *testing.T) {
+		fake.expect("BEGIN")
+		fake.expect("DELETE FROM slot_holds WHERE token = $1", "token-1", now).
+			willReturnRows(postgresSlotHoldColumnNames, []driver.Value{"hold-1", "token-1", "user-1", "item-1", period, expiresAt})
+		fake.expect("pg_advisory_xact_lock", "item-1")
+		fake.expect("FROM slot_holds").willReturnRows([]string{"id"})
+		fake.expect("SAVEPOINT store_booking")
+		insert := fake.expect("INSERT INTO bookings").willAffect(1)
+		fake.expect("COMMIT")
+
+		booking, err := service.BookSlotHold("token-1", Booking{Description: "Paid"})
+		if err != nil || booking.ItemId != "item-1" || booking.UserId != "user-1" || !booking.StartsAt.Equal(slot.LowerBound()) ||
+			!booking.EndsAt.Equal(slot.UpperBound()) || booking.Description != "Paid" {
+			t.Fatalf("Hold should be booked. Instead: %+v %v", booking, err)
+		}
+		expected := []driver.Value{booking.Id, "user-1", "item-1", now, period, "Paid", "confirmed", "[]", nil}
+		if !fakeArgsEqual(expected, insert.received) {
+			t.Errorf("No expected insertion:\nExpecting\t: %v\nRecieved\t: %v", expected, insert.received)
+		}
+	})
+
+	t.Run("Book mismatch", func(t *testing.T) {
+		// The hold is removed in the transaction rolled back, so it is kept
+		fake.expect("BEGIN")
+		fake.expect("DELETE FROM slot_holds WHERE token = $1").
+			willReturnRows(postgresSlotHoldColumnNames, []driver.Value{"hold-1", "token-1", "user-1", "item-1", period, expiresAt})
+		fake.expect("ROLLBACK")
+		if _, err := service.BookSlotHold("token-1", Booking{BaseBooking: BaseBooking{ItemId: "item-2"}}); !errors.Is(err, slotHoldMismatchError) {
+			t.Errorf("Booking of another item should not take the hold. Instead: %v", err)
+		}
+
+		fake.expect("BEGIN")
+		fake.expect("DELETE FROM slot_holds WHERE token = $1").willReturnRows(postgresSlotHoldColumnNames)
+		fake.expect("ROLLBACK")
+		if _, err := service.BookSlotHold("missing", Booking{}); !errors.Is(err, slotHoldNotFoundError) {
+			t.Errorf("Missing hold should not be booked. Instead: %v", err)
+		}
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		fake.expect("DELETE FROM slot_holds WHERE token = $1", "token-1", now).willAffect(1)
+		if err := service.ReleaseSlotHold("token-1"); err != nil {
+			t.Errorf("Hold should be released. Instead: %v", err)
+		}
+		fake.expect("DELETE FROM slot_holds WHERE token = $1", "token-1", now).willAffect(0)
+		if err := service.ReleaseSlotHold("token-1"); !errors.Is(err, slotHoldNotFoundError) {
+			t.Errorf("Hold should not be released twice. Instead: %v", err)
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		fake.expect("DELETE FROM slot_holds WHERE expires_at <= $1", now).willAffect(2)
+		if expired, err := service.ExpireSlotHolds(); err != nil || expired != 2 {
+			t.Errorf("No expected expired holds:\nExpecting\t: %v\nRecieved\t: %v %v", 2, expired, err)
+		}
+	})
+}
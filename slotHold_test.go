@@ -0,0 +1,177 @@
+package bookk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryBookingServiceSlotHolds(t *testing.T) {
+	now := testTime
+	var mu sync.Mutex
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	setNow := func(t time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = t
+	}
+	service := NewMemoryBookingService(WithClock(clock))
+
+	hold, err := service.HoldSlot("user", "item", *hoursRange(0, 1, TimeRangeIlEu), 10*time.Minute)
+	if err != nil || hold.Token == "" || hold.Id == "" || !hold.ExpiresAt.Equal(testTime.Add(10*time.Minute)) {
+		t.Fatalf("Slot should be held. Instead: %+v %v", hold, err)
+	}
+
+	t.Run("Conflicts", func(t *testing.T) {
+		var conflict *ConflictError
+		if _, err := service.CreateBooking(*hoursBooking("", "item", 0.5, 1.5)); !errors.As(err, &conflict) || !slices.Equal(conflict.ConflictingIds, []string{hold.Id}) {
+			t.Errorf("Booking should conflict with the hold. Instead: %v", err)
+		}
+		if _, err := service.HoldSlot("other", "item", *hoursRange(0.5, 2, TimeRangeIlEu), time.Minute); !errors.Is(err, bookingConflictError) {
+			t.Errorf("Hold should conflict with the hold. Instead: %v", err)
+		}
+		if _, err := service.CreateRecurringBooking(RecurringBooking{
+			BaseBooking: hoursBooking("series", "item", -24, -23).BaseBooking,
+			Rule:        &RecurrenceRule{Frequency: FrequencyDaily, Interval: 1, Count: 2, WeekStart: time.Monday},
+		}); !errors.Is(err, bookingConflictError) {
+			t.Errorf("Recurring booking should conflict with the hold. Instead: %v", err)
+		}
+		if _, err := service.CreateBooking(*hoursBooking("", "other", 0, 1)); err != nil {
+			t.Errorf("Booking of another item should not conflict with the hold. Instead: %v", err)
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		holds, err := service.GetSlotHoldsByTimeRangeAndItemId("item", *hoursRange(0.5, 3, TimeRangeIlEu))
+		if err != nil || len(holds) != 1 || holds[0].Id != hold.Id || holds[0].Token != "" {
+			t.Errorf("Hold should be read without its token. Instead: %+v %v", holds, err)
+		}
+		if holds, _ := service.GetSlotHoldsByTimeRangeAndItemId("item", *hoursRange(1, 2, TimeRangeIlEu)); len(holds) != 0 {
+			t.Errorf("Hold outside the time range should not be read. Instead: %+v", holds)
+		}
+	})
+
+	t.Run("Book", func(t *testing.T) {
+		if _, err := service.BookSlotHold(hold.Token, *hoursBooking("", "item", 0, 2)); !errors.Is(err, slotHoldMismatchError) {
+			t.Errorf("Booking of another time should not take the hold. Instead: %v", err)
+		}
+		booking, err := service.BookSlotHold(hold.Token, Booking{Description: "Paid"})
+		if err != nil || booking.ItemId != "item" || booking.UserId != "user" || !booking.StartsAt.Equal(hold.StartsAt) ||
+			!booking.EndsAt.Equal(hold.EndsAt) || booking.Description != "Paid" {
+			t.Fatalf("Hold should be booked. Instead: %+v %v", booking, err)
+		}
+		if _, err := service.GetSlotHold(hold.Token); !errors.Is(err, slotHoldNotFoundError) {
+			t.Errorf("Booked hold should be removed. Instead: %v", err)
+		}
+		if _, err := service.BookSlotHold(hold.Token, Booking{}); !errors.Is(err, slotHoldNotFoundError) {
+			t.Errorf("Hold should not be booked twice. Instead: %v", err)
+		}
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		released, _ := service.HoldSlot("user", "item", *hoursRange(2, 3, TimeRangeIlEu), time.Minute)
+		if err := service.ReleaseSlotHold(released.Token); err != nil {
+			t.Fatalf("Hold should be released. Instead: %v", err)
+		}
+		if err := service.ReleaseSlotHold(released.Token); !errors.Is(err, slotHoldNotFoundError) {
+			t.Errorf("Hold should not be released twice. Instead: %v", err)
+		}
+		if _, err := service.CreateBooking(*hoursBooking("", "item", 2, 3)); err != nil {
+			t.Errorf("Released hold should free its time. Instead: %v", err)
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		expiring, _ := service.HoldSlot("user", "item", *hoursRange(4, 5, TimeRangeIlEu), time.Minute)
+		setNow(testTime.Add(time.Minute))
+		defer setNow(testTime)
+
+		if _, err := service.GetSlotHold(expiring.Token); !errors.Is(err, slotHoldNotFoundError) {
+			t.Errorf("Expired hold should not be found. Instead: %v", err)
+		}
+		if _, err := service.BookSlotHold(expiring.Token, Booking{}); !errors.Is(err, slotHoldNotFoundError) {
+			t.Errorf("Expired hold should not be booked. Instead: %v", err)
+		}
+		if _, err := service.CreateBooking(*hoursBooking("", "item", 4, 5)); err != nil {
+			t.Errorf("Expired hold should free its time. Instead: %v", err)
+		}
+		if expired := service.ExpireSlotHolds(); expired != 1 {
+			t.Errorf("No expected expired holds:\nExpecting\t: %v\nRecieved\t: %v", 1, expired)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		unbounded, _ := NewTimeRangeFrom(testTime.Add(10*time.Hour), TimeRangeIlEu)
+		if _, err := service.HoldSlot("user", "item", *unbounded, time.Minute); !errors.Is(err, slotHoldTimeRangeError) {
+			t.Errorf("Unbounded time should not be held. Instead: %v", err)
+		}
+		if _, err := service.HoldSlot("user", "item", *hoursRange(10, 11, TimeRangeIlEu), 0); !errors.Is(err, slotHoldTTLError) {
+			t.Errorf("Hold lasting no time should fail. Instead: %v", err)
+		}
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var held sync.Map
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if hold, err := service.HoldSlot("user", "item", *hoursRange(20, 21, TimeRangeIlEu), time.Minute); err == nil {
+					held.Store(hold.Token, hold)
+				}
+			}()
+		}
+		wg.Wait()
+
+		count := 0
+		held.Range(func(any, any) bool {
+			count++
+			return true
+		})
+		if count != 1 {
+			t.Errorf("Only 1 of the colliding holds should be made. Instead: %d", count)
+		}
+	})
+}
+
+func TestMemoryBookingServiceReapSlotHolds(t *testing.T) {
+	service := NewMemoryBookingService(WithClock(func() time.Time { return testTime }))
+	for i := range 3 {
+		service.HoldSlot("user", "item", *hoursRange(float64(i), float64(i+1), TimeRangeIlEu), time.Duration(i)*time.Minute+time.Nanosecond)
+	}
+	service.now = func() time.Time { return testTime.Add(time.Minute) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := service.ReapSlotHolds(ctx, 0); !errors.Is(err, slotHoldIntervalError) {
+		t.Errorf("Reaper without interval should fail. Instead: %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- service.ReapSlotHolds(ctx, time.Millisecond)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		service.mu.RLock()
+		left := len(service.slotHolds)
+		service.mu.RUnlock()
+		if left == 2 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("Expired hold should be reaped. Instead: %d holds left", left)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Reaper should stop with its context. Instead: %v", err)
+	}
+}
@@ -1,10 +1,11 @@
 package bookk
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"math"
 	"sort"
-	"strings"
 	"time"
 )
 
@@ -15,32 +16,31 @@ const (
 	TimeRangeElIu            = 0b01 // Excludes lower bound and includes upper bound of the range: (lower, upper]
 )
 
+const (
+	TimeRangeFinite    TimeRangeLimit = iota // The bound is the time given to the range
+	TimeRangeInfinite                        // The bound is -infinity (lower) or infinity (upper), which may be included in the range
+	TimeRangeUnbounded                       // There is no bound at all, like (,upper) or [lower,) in PostgreSQL
+)
+
 var (
 	timeRangeInitializatioDataError                = errors.New("Lower bound is greater than Upper bound in range")
 	timeRangeInitializationNotRecognizedBoundError = errors.New("Bounds not recognized in range")
+	timeRangeInitializationNotRecognizedLimitError = errors.New("Bound limits not recognized in range")
 	timeRangeParseError                            = errors.New("Error parsing time range")
 )
 
 type TimeRangeBound byte
 
-type MultiTimeRange []*TimeRange
-
-func (mr MultiTimeRange) Len() int {
-	return len(mr)
-}
-
-func (mr MultiTimeRange) Less(i, j int) bool {
-	return mr[i].lowerBound.Unix() < mr[j].lowerBound.Unix()
-}
-
-func (mr MultiTimeRange) Swap(i, j int) {
-	mr[i], mr[j] = mr[j], mr[i]
-}
+// TimeRangeLimit tells whether a bound of a TimeRange is a finite time, an infinite value or missing.
+type TimeRangeLimit byte
 
 type TimeRange struct {
 	lowerBound time.Time
 	upperBound time.Time
 	boundsConf TimeRangeBound
+	lowerLimit TimeRangeLimit
+	upperLimit TimeRangeLimit
+	empty      bool
 }
 
 /*
@@ -65,45 +65,109 @@ func NewTimeRange(lower, upper time.Time, bounds TimeRangeBound) (*TimeRange, er
 	} else if bounds > TimeRangeBoundsInclusion {
 		return nil, timeRangeInitializationNotRecognizedBoundError
 	}
-	return &TimeRange{lower, upper, bounds}, nil
+	return &TimeRange{lowerBound: lower, upperBound: upper, boundsConf: bounds}, nil
 }
 
 /*
-TimeRangeFromPostgresString creates a TimeRange from a PostgreSQL range string format.
+NewTimeRangeWithLimits creates a new TimeRange whose bounds may be infinite or missing.
 
-This function parses a string in PostgreSQL range notation and creates a corresponding
-TimeRange object. The format should follow PostgreSQL conventions where square brackets
-indicate inclusive bounds and parentheses indicate exclusive bounds.
+This function works as NewTimeRange but also receives the limit of each bound. The time given
+for a bound is ignored unless its limit is TimeRangeFinite. As in PostgreSQL, a missing bound
+(TimeRangeUnbounded) is always exclusive, while an infinite bound (TimeRangeInfinite) keeps the
+inclusion/exclusion configuration given.
 
 Parameters:
-  - r: A string in PostgreSQL range format, e.g., "[2025-01-01 00:00:00,2025-01-02 00:00:00)"
+  - lower: The lower time bound of the TimeRange
+  - upper: The upper time bound of the TimeRange
+  - bounds: Configuration for inclusion/exclusion of bounds
+  - lowerLimit: Limit of the lower bound (TimeRangeFinite, TimeRangeInfinite or TimeRangeUnbounded)
+  - upperLimit: Limit of the upper bound (TimeRangeFinite, TimeRangeInfinite or TimeRangeUnbounded)
 
 Returns:
   - A pointer to a new TimeRange object
-  - An error if the string cannot be properly parsed
+  - An error if lower > upper for finite bounds or if the bounds configuration or limits are invalid
 */
-func TimeRangeFromPostgresString(r string) (*TimeRange, error) {
-	bounds := TimeRangeBound(0)
-	if r[0] == '[' {
-		bounds |= 0b10
-	}
-	if r[len(r)-1] == ']' {
-		bounds |= 0b01
+func NewTimeRangeWithLimits(lower, upper time.Time, bounds TimeRangeBound, lowerLimit, upperLimit TimeRangeLimit) (*TimeRange, error) {
+	if lowerLimit > TimeRangeUnbounded || upperLimit > TimeRangeUnbounded {
+		return nil, timeRangeInitializationNotRecognizedLimitError
+	} else if bounds > TimeRangeBoundsInclusion {
+		return nil, timeRangeInitializationNotRecognizedBoundError
+	} else if lowerLimit == TimeRangeFinite && upperLimit == TimeRangeFinite && lower.After(upper) {
+		return nil, timeRangeInitializatioDataError
 	}
 
-	r = strings.ReplaceAll(r, "\"", "")
-	dates := strings.Split(r[1:len(r)-1], ",")[:2]
-	lowerTime, err := time.Parse(time.DateTime, dates[0])
-	if err != nil {
-		return nil, timeRangeParseError
+	newRange := &TimeRange{boundsConf: bounds, lowerLimit: lowerLimit, upperLimit: upperLimit}
+	if lowerLimit == TimeRangeFinite {
+		newRange.lowerBound = lower
+	} else if lowerLimit == TimeRangeUnbounded {
+		newRange.boundsConf &^= 0b10
 	}
-
-	upperTime, err := time.Parse(time.DateTime, dates[1])
-	if err != nil {
-		return nil, timeRangeParseError
+	if upperLimit == TimeRangeFinite {
+		newRange.upperBound = upper
+	} else if upperLimit == TimeRangeUnbounded {
+		newRange.boundsConf &^= 0b01
 	}
+	return newRange, nil
+}
+
+/*
+NewTimeRangeFrom creates a TimeRange with no upper bound, like [lower,) in PostgreSQL.
 
-	return NewTimeRange(lowerTime, upperTime, bounds)
+Parameters:
+  - lower: The lower time bound of the TimeRange
+  - bounds: Configuration for inclusion/exclusion of bounds. Only the lower bound setting is used
+
+Returns:
+  - A pointer to a new TimeRange object
+  - An error if the bounds configuration is invalid
+*/
+func NewTimeRangeFrom(lower time.Time, bounds TimeRangeBound) (*TimeRange, error) {
+	return NewTimeRangeWithLimits(lower, time.Time{}, bounds, TimeRangeFinite, TimeRangeUnbounded)
+}
+
+/*
+NewTimeRangeUntil creates a TimeRange with no lower bound, like (,upper) in PostgreSQL.
+
+Parameters:
+  - upper: The upper time bound of the TimeRange
+  - bounds: Configuration for inclusion/exclusion of bounds. Only the upper bound setting is used
+
+Returns:
+  - A pointer to a new TimeRange object
+  - An error if the bounds configuration is invalid
+*/
+func NewTimeRangeUntil(upper time.Time, bounds TimeRangeBound) (*TimeRange, error) {
+	return NewTimeRangeWithLimits(time.Time{}, upper, bounds, TimeRangeUnbounded, TimeRangeFinite)
+}
+
+/*
+EmptyTimeRange creates a TimeRange that contains no instant at all, like empty in PostgreSQL.
+
+Returns:
+  - A pointer to a new empty TimeRange object
+*/
+func EmptyTimeRange() *TimeRange {
+	return &TimeRange{empty: true}
+}
+
+/*
+TimeRangeFromPostgresString creates a TimeRange from a PostgreSQL range string format.
+
+This function parses a string in PostgreSQL range notation and creates a corresponding
+TimeRange object. The format should follow PostgreSQL conventions where square brackets
+indicate inclusive bounds and parentheses indicate exclusive bounds.
+
+Parameters:
+  - r: A string in PostgreSQL range format, e.g., "[2025-01-01 00:00:00,2025-01-02 00:00:00)".
+    Missing bounds "(,2025-01-01 00:00:00)", infinite bounds "[-infinity,infinity]" and "empty"
+    are also recognized
+
+Returns:
+  - A pointer to a new TimeRange object
+  - A *TimeRangeParseError if the string cannot be properly parsed
+*/
+func TimeRangeFromPostgresString(r string) (*TimeRange, error) {
+	return parsePostgresRange(r, postgresTsrange)
 }
 
 /*
@@ -112,30 +176,41 @@ ToPostgresRangeString converts the TimeRange to a PostgreSQL range string format
 This function formats the TimeRange into a string following PostgreSQL range notation
 where square brackets indicate inclusive bounds and parentheses indicate exclusive bounds.
 
+Missing bounds are left blank, infinite bounds are written as -infinity or infinity and
+an empty range is written as empty, as PostgreSQL does.
+
 Returns:
   - A string representation of the time range in PostgreSQL format
 */
 func (t *TimeRange) ToPostgresRangeString() string {
+	if t.IsEmpty() {
+		return "empty"
+	}
+
 	var formatString string
 
 	switch t.boundsConf {
 	case TimeRangeBoundsExclusion:
-		formatString = "(\"%s\",\"%s\")"
+		formatString = "(%s,%s)"
 	case TimeRangeBoundsInclusion:
-		formatString = "[\"%s\",\"%s\"]"
+		formatString = "[%s,%s]"
 	case TimeRangeElIu:
-		formatString = "(\"%s\",\"%s\"]"
+		formatString = "(%s,%s]"
 	default:
-		formatString = "[\"%s\",\"%s\")"
+		formatString = "[%s,%s)"
 	}
 
 	return fmt.Sprintf(
 		formatString,
-		t.lowerBound.Format(time.DateTime),
-		t.upperBound.Format(time.DateTime),
+		formatPostgresBound(t.lowerBound, t.lowerLimit, "-infinity", formatDateTime),
+		formatPostgresBound(t.upperBound, t.upperLimit, "infinity", formatDateTime),
 	)
 }
 
+func formatDateTime(t time.Time) string {
+	return t.Format(time.DateTime)
+}
+
 /*
 Verbose returns a human-readable string representation of the time range.
 
@@ -146,6 +221,24 @@ Returns:
   - A string describing the time range in natural language
 */
 func (t *TimeRange) Verbose() string {
+	lowerOpen := t.lowerLimit != TimeRangeFinite
+	upperOpen := t.upperLimit != TimeRangeFinite
+
+	switch {
+	case t.IsEmpty():
+		return "Never"
+	case lowerOpen && upperOpen:
+		return "Always"
+	case lowerOpen && t.upperInclusion():
+		return fmt.Sprintf("Until %s", t.upperBound.Format(time.DateTime))
+	case lowerOpen:
+		return fmt.Sprintf("Before %s", t.upperBound.Format(time.DateTime))
+	case upperOpen && t.lowerInclusion():
+		return fmt.Sprintf("From %s onwards", t.lowerBound.Format(time.DateTime))
+	case upperOpen:
+		return fmt.Sprintf("Past %s onwards", t.lowerBound.Format(time.DateTime))
+	}
+
 	var formatString string
 	switch t.boundsConf {
 	case TimeRangeBoundsExclusion:
@@ -175,8 +268,8 @@ Returns:
   - A pointer to a copy of the oroginal TimeRange object
 */
 func (t *TimeRange) Clone() *TimeRange {
-	clone, _ := NewTimeRange(t.lowerBound, t.upperBound, t.boundsConf)
-	return clone
+	clone := *t
+	return &clone
 }
 
 func (t *TimeRange) lowerInclusion() bool {
@@ -187,12 +280,123 @@ func (t *TimeRange) upperInclusion() bool {
 	return t.boundsConf&0b01 == 1
 }
 
+/*
+IsEmpty checks if the TimeRange contains no instant at all.
+
+A TimeRange is empty when it was created with EmptyTimeRange or when both of its bounds are
+the same finite time and any of them is excluded, e.g. [lower, lower).
+*/
+func (t *TimeRange) IsEmpty() bool {
+	if t.empty {
+		return true
+	}
+	return t.lowerLimit == TimeRangeFinite && t.upperLimit == TimeRangeFinite &&
+		t.lowerBound.Equal(t.upperBound) && t.boundsConf != TimeRangeBoundsInclusion
+}
+
+// lowerRank places non finite lower bounds before any finite time
+func (t *TimeRange) lowerRank() int {
+	switch t.lowerLimit {
+	case TimeRangeUnbounded:
+		return -2
+	case TimeRangeInfinite:
+		return -1
+	}
+	return 0
+}
+
+// upperRank places non finite upper bounds after any finite time
+func (t *TimeRange) upperRank() int {
+	switch t.upperLimit {
+	case TimeRangeUnbounded:
+		return 2
+	case TimeRangeInfinite:
+		return 1
+	}
+	return 0
+}
+
+func boundsFromInclusion(lower, upper bool) TimeRangeBound {
+	bounds := TimeRangeBound(0)
+	if lower {
+		bounds |= 0b10
+	}
+	if upper {
+		bounds |= 0b01
+	}
+	return bounds
+}
+
+// joinBounds creates a TimeRange from the lower bound of lower to the upper bound of upper
+func joinBounds(lower, upper *TimeRange) *TimeRange {
+	return &TimeRange{
+		lowerBound: lower.lowerBound,
+		upperBound: upper.upperBound,
+		boundsConf: boundsFromInclusion(lower.lowerInclusion(), upper.upperInclusion()),
+		lowerLimit: lower.lowerLimit,
+		upperLimit: upper.upperLimit,
+	}
+}
+
+// compareLowerBounds returns -1 if t starts before r, 1 if it starts after r and 0 if both
+// start at the same instant. An inclusive lower bound starts before an exclusive one at the same time.
+func compareLowerBounds(t, r *TimeRange) int {
+	if c := cmp.Compare(t.lowerRank(), r.lowerRank()); c != 0 {
+		return c
+	} else if t.lowerRank() == 0 {
+		if c := t.lowerBound.Compare(r.lowerBound); c != 0 {
+			return c
+		}
+	}
+	if t.lowerInclusion() == r.lowerInclusion() {
+		return 0
+	} else if t.lowerInclusion() {
+		return -1
+	}
+	return 1
+}
+
+// compareUpperBounds returns -1 if t ends before r, 1 if it ends after r and 0 if both
+// end at the same instant. An exclusive upper bound ends before an inclusive one at the same time.
+func compareUpperBounds(t, r *TimeRange) int {
+	if c := cmp.Compare(t.upperRank(), r.upperRank()); c != 0 {
+		return c
+	} else if t.upperRank() == 0 {
+		if c := t.upperBound.Compare(r.upperBound); c != 0 {
+			return c
+		}
+	}
+	if t.upperInclusion() == r.upperInclusion() {
+		return 0
+	} else if t.upperInclusion() {
+		return 1
+	}
+	return -1
+}
+
+// compareUpperToLower compares where t ends against where r starts. It returns -1 if there is
+// a gap between them, 0 if they touch without sharing any instant and 1 if they share at least one instant.
+func compareUpperToLower(t, r *TimeRange) int {
+	// Non finite upper bounds are always after any lower bound and vice versa
+	if t.upperRank() != 0 || r.lowerRank() != 0 {
+		return 1
+	} else if c := t.upperBound.Compare(r.lowerBound); c != 0 {
+		return c
+	}
+	if t.upperInclusion() && r.lowerInclusion() {
+		return 1
+	} else if t.upperInclusion() || r.lowerInclusion() {
+		return 0
+	}
+	return -1
+}
+
 /*
 Equal determines whether two time ranges are identical.
 
 This function checks if two TimeRange objects have the same lower bound, upper bound,
 and inclusion/exclusion configuration. It correctly handles time comparison by using
-time.Equal() for comparing Time objects.
+time.Equal() for comparing Time objects. Any two empty ranges are equal.
 
 Parameters:
   - r: The time range to compare with the current range
@@ -200,8 +404,10 @@ Parameters:
 func (t *TimeRange) Equal(r *TimeRange) bool {
 	if t == r {
 		return true
+	} else if t.IsEmpty() || r.IsEmpty() {
+		return t.IsEmpty() && r.IsEmpty()
 	}
-	return t.lowerBound.Equal(r.lowerBound) && t.upperBound.Equal(r.upperBound) && t.boundsConf == r.boundsConf
+	return compareLowerBounds(t, r) == 0 && compareUpperBounds(t, r) == 0
 }
 
 /*
@@ -209,63 +415,55 @@ Contains checks if this TimeRange completely contains another TimeRange.
 
 A TimeRange contains another when both the lower and upper bounds of the contained
 TimeRange lies within the containing range. This function takes into account the
-inclusion/exclusion configuration of both ranges' boundaries.
+inclusion/exclusion configuration of both ranges' boundaries. An empty TimeRange is
+contained in any range.
 
 Parameters:
   - r: The TimeRange to check if it's contained within the current range
 */
 func (t *TimeRange) Contains(r *TimeRange) bool {
 	// Trivial cases
-	if t.lowerBound.After(r.lowerBound) || t.upperBound.Before(r.upperBound) {
-		return false
-	}
-
-	// Lower bound cases
-	lowerBoundInclusion := (t.boundsConf>>1 >= r.boundsConf>>1)
-	if t.lowerBound.Equal(r.lowerBound) && !lowerBoundInclusion {
-		return false
-	}
-
-	// Upper bound cases
-	upperBoundInclusion := (t.boundsConf&0b01 >= r.boundsConf&0b01)
-	if t.upperBound.Equal(r.upperBound) && !upperBoundInclusion {
+	if r.IsEmpty() {
+		return true
+	} else if t.IsEmpty() {
 		return false
 	}
 
-	return true
+	return compareLowerBounds(t, r) <= 0 && compareUpperBounds(t, r) >= 0
 }
 
 /*
 Overlaps determines how two TimeRange overlap with each other.
 
-This function checks if the current TimeRange overlaps with another TimeRange
-and indicates how they overlap. It does not consider exact boundary touches as overlaps;
-for those cases, use the Union function instead.
+This function checks if the current TimeRange shares any instant with another TimeRange
+and indicates which one starts first. It does not consider boundary touches without a
+shared instant as overlaps; for those cases, use the Union function instead. For a detailed
+classification use Relation.
 
 Parameters:
   - r: The TimeRange to check for overlap with the current range
 
 Returns:
-  - 1: if this range overlaps on the upper bound of the other range
-  - -1: if this range overlaps on the lower bound of the other range
-  - 0: if there is no overlap or if the overlap is exactly at the boundaries
+  - 1: if the ranges overlap and this range starts before or together with the other range
+  - -1: if the ranges overlap and the other range starts first
+  - 0: if there is no overlap or if the ranges only touch at their boundaries
 */
 func (t *TimeRange) Overlaps(r *TimeRange) int8 {
-	if t.upperBound.After(r.lowerBound) && t.upperBound.Before(r.upperBound) {
+	if !t.Relation(r).SharesInstants() {
+		return 0
+	} else if compareLowerBounds(t, r) <= 0 {
 		return 1
-	} else if t.lowerBound.After(r.lowerBound) && t.upperBound.Before(r.upperBound) {
-		return -1
 	}
-	return 0
+	return -1
 }
 
 /*
 Union try combining two TimeRange into a single TimeRange if possible.
 
 A union to is possible when ranges overlaps or exactly touch at their boundaries
-with compatible inclusion/exclusion configurations. The boundary configuration of the
-resulting TimeRange uses an OR combination of the original configurations, always producing the
-most inclusive range possible.
+with compatible inclusion/exclusion configurations. Each bound of the resulting TimeRange
+is taken from the range that reaches further on that side, so the union is always the
+smallest range covering both of them.
 
 Parameters:
   - r: The TimeRange to union with the current range
@@ -275,51 +473,27 @@ Returns:
   - nil if the ranges are completely separate and cannot be merged
 */
 func (t *TimeRange) Union(r *TimeRange) *TimeRange {
-	// Equals
-	if t.Equal(r) {
+	// Empty ranges do not add anything to the other range
+	if t.IsEmpty() {
+		return r.Clone()
+	} else if r.IsEmpty() {
 		return t.Clone()
 	}
 
-	// Contains itself
-	if t.Contains(r) {
-		return t.Clone()
-	} else if r.Contains(t) {
-		return r.Clone()
+	// Completely separate ranges
+	if compareUpperToLower(t, r) < 0 || compareUpperToLower(r, t) < 0 {
+		return nil
 	}
 
-	// Overlaps
-	overlap := t.Overlaps(r)
-	if overlap != 0 {
-		var newRange *TimeRange
-		var err error
-		if overlap == 1 {
-			newRange, err = NewTimeRange(t.lowerBound, r.upperBound, t.boundsConf|r.boundsConf)
-		} else {
-			newRange, err = NewTimeRange(r.lowerBound, t.upperBound, t.boundsConf|r.boundsConf)
-		}
-		if err != nil {
-			return nil
-		} else {
-			return newRange
-		}
+	lower, upper := t, t
+	if compareLowerBounds(r, t) < 0 {
+		lower = r
 	}
-
-	// Boundaries overlaps
-	if t.lowerBound.Equal(r.upperBound) && (t.lowerInclusion() || r.upperInclusion()) {
-		newRange, err := NewTimeRange(r.lowerBound, t.upperBound, t.boundsConf|r.boundsConf)
-		if err != nil {
-			return nil
-		}
-		return newRange
-	} else if t.upperBound.Equal(r.lowerBound) && (t.upperInclusion() || r.lowerInclusion()) {
-		newRange, err := NewTimeRange(t.lowerBound, r.upperBound, t.boundsConf|r.boundsConf)
-		if err != nil {
-			return nil
-		}
-		return newRange
+	if compareUpperBounds(r, t) > 0 {
+		upper = r
 	}
 
-	return nil
+	return joinBounds(lower, upper)
 }
 
 /*
@@ -349,3 +523,337 @@ func (t *TimeRange) Merge(r *TimeRange) MultiTimeRange {
 	}
 	return MultiTimeRange{unionRange.Clone()}
 }
+
+/*
+Intersection computes the TimeRange shared by the current TimeRange and another TimeRange.
+
+Each bound of the resulting TimeRange is taken from the range that is more restrictive
+on that side, keeping its inclusion/exclusion configuration. Ranges that only touch at
+a bound excluded by any of them have no instant in common.
+
+Parameters:
+  - r: The TimeRange to intersect with the current range
+
+Returns:
+  - A new TimeRange with the instants contained by both ranges
+  - nil if the ranges have no instant in common
+*/
+func (t *TimeRange) Intersection(r *TimeRange) *TimeRange {
+	if t.IsEmpty() || r.IsEmpty() {
+		return nil
+	}
+
+	if compareUpperToLower(t, r) <= 0 || compareUpperToLower(r, t) <= 0 {
+		return nil
+	}
+
+	lower, upper := t, t
+	if compareLowerBounds(r, t) > 0 {
+		lower = r
+	}
+	if compareUpperBounds(r, t) < 0 {
+		upper = r
+	}
+
+	newRange := joinBounds(lower, upper)
+	if newRange.IsEmpty() {
+		return nil
+	}
+	return newRange
+}
+
+/*
+Difference removes from the current TimeRange every instant contained in another TimeRange.
+
+The bounds of the resulting ranges are the complement of the bounds of r where r cuts the
+current range: an inclusive bound in r becomes an exclusive bound in the result and vice versa.
+
+Parameters:
+  - r: The TimeRange to subtract from the current range
+
+Returns:
+  - A MultiTimeRange with zero, one or two ranges in chronological order. It is empty when r
+    contains the current range and holds a copy of the current range when they do not intersect
+*/
+func (t *TimeRange) Difference(r *TimeRange) MultiTimeRange {
+	if t.IsEmpty() {
+		return MultiTimeRange{}
+	}
+	if t.Intersection(r) == nil {
+		return MultiTimeRange{t.Clone()}
+	}
+
+	// Parts left outside of r by its non finite bounds, e.g. (,-infinity), cannot be represented
+	difference := MultiTimeRange{}
+	if compareLowerBounds(t, r) < 0 && r.lowerLimit == TimeRangeFinite {
+		before := &TimeRange{
+			lowerBound: t.lowerBound,
+			lowerLimit: t.lowerLimit,
+			upperBound: r.lowerBound,
+			boundsConf: boundsFromInclusion(t.lowerInclusion(), !r.lowerInclusion()),
+		}
+		if !before.IsEmpty() {
+			difference = append(difference, before)
+		}
+	}
+	if compareUpperBounds(t, r) > 0 && r.upperLimit == TimeRangeFinite {
+		after := &TimeRange{
+			lowerBound: r.upperBound,
+			upperBound: t.upperBound,
+			upperLimit: t.upperLimit,
+			boundsConf: boundsFromInclusion(!r.upperInclusion(), t.upperInclusion()),
+		}
+		if !after.IsEmpty() {
+			difference = append(difference, after)
+		}
+	}
+	return difference
+}
+
+/*
+SymmetricDifference computes the instants contained in exactly one of two TimeRange.
+
+This function combines the difference of both ranges against each other. Parts that touch
+at their boundaries are merged into a single range.
+
+Parameters:
+  - r: The TimeRange to compare with the current range
+
+Returns:
+  - A MultiTimeRange with the resulting ranges in chronological order. It is empty when
+    both ranges are equal
+*/
+func (t *TimeRange) SymmetricDifference(r *TimeRange) MultiTimeRange {
+	parts := append(t.Difference(r), r.Difference(t)...)
+	sort.Sort(parts)
+
+	result := MultiTimeRange{}
+	for _, part := range parts {
+		if len(result) > 0 {
+			if union := result[len(result)-1].Union(part); union != nil {
+				result[len(result)-1] = union
+				continue
+			}
+		}
+		result = append(result, part)
+	}
+	return result
+}
+
+/*
+LowerBound returns the lower time bound of the TimeRange.
+
+Returns:
+  - The lower bound, or the zero time if the bound is not finite or the range is empty
+*/
+func (t *TimeRange) LowerBound() time.Time {
+	if t.IsEmpty() || t.lowerLimit != TimeRangeFinite {
+		return time.Time{}
+	}
+	return t.lowerBound
+}
+
+/*
+UpperBound returns the upper time bound of the TimeRange.
+
+Returns:
+  - The upper bound, or the zero time if the bound is not finite or the range is empty
+*/
+func (t *TimeRange) UpperBound() time.Time {
+	if t.IsEmpty() || t.upperLimit != TimeRangeFinite {
+		return time.Time{}
+	}
+	return t.upperBound
+}
+
+// Bounds returns the inclusion/exclusion configuration of the TimeRange.
+func (t *TimeRange) Bounds() TimeRangeBound {
+	return t.boundsConf
+}
+
+// LowerLimit tells whether the lower bound of the TimeRange is finite, infinite or missing.
+func (t *TimeRange) LowerLimit() TimeRangeLimit {
+	return t.lowerLimit
+}
+
+// UpperLimit tells whether the upper bound of the TimeRange is finite, infinite or missing.
+func (t *TimeRange) UpperLimit() TimeRangeLimit {
+	return t.upperLimit
+}
+
+// LowerInclusive tells whether the lower bound is included in the TimeRange.
+func (t *TimeRange) LowerInclusive() bool {
+	return t.lowerInclusion()
+}
+
+// UpperInclusive tells whether the upper bound is included in the TimeRange.
+func (t *TimeRange) UpperInclusive() bool {
+	return t.upperInclusion()
+}
+
+/*
+Duration returns the time elapsed between the bounds of the TimeRange.
+
+Returns:
+  - The duration of the range, zero if it is empty, or the maximum time.Duration if any of
+    its bounds is not finite
+*/
+func (t *TimeRange) Duration() time.Duration {
+	if t.IsEmpty() {
+		return 0
+	} else if t.lowerLimit != TimeRangeFinite || t.upperLimit != TimeRangeFinite {
+		return time.Duration(math.MaxInt64)
+	}
+	return t.upperBound.Sub(t.lowerBound)
+}
+
+/*
+ContainsTime checks if an instant lies within the TimeRange.
+
+This function takes into account the inclusion/exclusion configuration of the bounds.
+
+Parameters:
+  - instant: The instant to check
+*/
+func (t *TimeRange) ContainsTime(instant time.Time) bool {
+	return t.Contains(&TimeRange{lowerBound: instant, upperBound: instant, boundsConf: TimeRangeBoundsInclusion})
+}
+
+/*
+Shift moves the TimeRange in time, keeping its duration and bounds configuration.
+
+Parameters:
+  - d: The duration to add to both bounds. Negative values move the range to the past
+
+Returns:
+  - A new shifted TimeRange. Non finite bounds and empty ranges are not changed
+*/
+func (t *TimeRange) Shift(d time.Duration) *TimeRange {
+	return t.Extend(-d, d)
+}
+
+/*
+Extend widens the TimeRange on each side, keeping its bounds configuration.
+
+Parameters:
+  - before: The duration to subtract from the lower bound. Negative values shrink the range
+  - after: The duration to add to the upper bound. Negative values shrink the range
+
+Returns:
+  - A new extended TimeRange. Non finite bounds are not changed, and shrinking the range past
+    itself returns an empty range
+*/
+func (t *TimeRange) Extend(before, after time.Duration) *TimeRange {
+	if t.IsEmpty() {
+		return EmptyTimeRange()
+	}
+
+	extended := t.Clone()
+	if extended.lowerLimit == TimeRangeFinite {
+		extended.lowerBound = extended.lowerBound.Add(-before)
+	}
+	if extended.upperLimit == TimeRangeFinite {
+		extended.upperBound = extended.upperBound.Add(after)
+	}
+	if extended.lowerLimit == TimeRangeFinite && extended.upperLimit == TimeRangeFinite &&
+		extended.lowerBound.After(extended.upperBound) {
+		return EmptyTimeRange()
+	}
+	return extended
+}
+
+/*
+Split divides the TimeRange into consecutive slots of the same duration.
+
+Every slot includes its lower bound and excludes its upper bound, except the first one, which
+keeps the lower bound configuration of the range, and the last one, which keeps the upper bound
+configuration of the range and may be shorter than step.
+
+Since consecutive slots touch each other, the result is not normalized: it holds every slot
+in chronological order.
+
+Parameters:
+  - step: The duration of each slot
+
+Returns:
+  - A MultiTimeRange with the slots. It holds a copy of the range if step is not positive or
+    any bound is not finite, and is empty if the range is empty
+*/
+func (t *TimeRange) Split(step time.Duration) MultiTimeRange {
+	if t.IsEmpty() {
+		return MultiTimeRange{}
+	} else if step <= 0 || t.lowerLimit != TimeRangeFinite || t.upperLimit != TimeRangeFinite {
+		return MultiTimeRange{t.Clone()}
+	}
+
+	slots := MultiTimeRange{}
+	for lower := t.lowerBound; lower.Before(t.upperBound); lower = lower.Add(step) {
+		slot := &TimeRange{lowerBound: lower, upperBound: lower.Add(step), boundsConf: TimeRangeIlEu}
+		if len(slots) == 0 {
+			slot.boundsConf = boundsFromInclusion(t.lowerInclusion(), false)
+		}
+		if !slot.upperBound.Before(t.upperBound) {
+			slot.upperBound = t.upperBound
+			slot.boundsConf = boundsFromInclusion(slot.lowerInclusion(), t.upperInclusion())
+		}
+		if !slot.IsEmpty() {
+			slots = append(slots, slot)
+		}
+	}
+
+	// A range such as [lower, lower] has a single instant
+	if len(slots) == 0 {
+		slots = append(slots, t.Clone())
+	}
+	return slots
+}
+
+/*
+SplitAt divides the TimeRange in two at an instant.
+
+The instant is excluded from the first part and included in the second one.
+
+Parameters:
+  - at: The instant where the range is divided
+
+Returns:
+  - The part of the range before at, or nil if there is none
+  - The part of the range from at onwards, or nil if there is none
+*/
+func (t *TimeRange) SplitAt(at time.Time) (*TimeRange, *TimeRange) {
+	cut := &TimeRange{lowerBound: at, upperBound: at, boundsConf: TimeRangeIlEu, upperLimit: TimeRangeUnbounded}
+
+	before := t.Difference(cut)
+	if len(before) == 0 {
+		before = append(before, nil)
+	}
+	return before[0], t.Intersection(cut)
+}
+
+/*
+Truncate rounds down the bounds of the TimeRange to a multiple of a duration.
+
+Bounds are truncated as time.Time.Truncate does, keeping the bounds configuration. This is
+useful to report ranges at a coarser resolution, e.g. by hour.
+
+Parameters:
+  - to: The resolution of the bounds. If it is not positive, the bounds are not changed
+
+Returns:
+  - A new truncated TimeRange. If both bounds are truncated to the same time, it is empty unless
+    both bounds are inclusive, when it holds just that instant
+*/
+func (t *TimeRange) Truncate(to time.Duration) *TimeRange {
+	if t.IsEmpty() {
+		return EmptyTimeRange()
+	}
+
+	truncated := t.Clone()
+	if truncated.lowerLimit == TimeRangeFinite {
+		truncated.lowerBound = truncated.lowerBound.Truncate(to)
+	}
+	if truncated.upperLimit == TimeRangeFinite {
+		truncated.upperBound = truncated.upperBound.Truncate(to)
+	}
+	return truncated
+}
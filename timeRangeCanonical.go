@@ -0,0 +1,120 @@
+package bookk
+
+import (
+	"time"
+)
+
+// truncateTo rounds down t to the granularity. Days are truncated to the midnight of the location of t
+func truncateTo(t time.Time, granularity time.Duration) time.Time {
+	if granularity == 24*time.Hour {
+		year, month, day := t.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+	return t.Truncate(granularity)
+}
+
+// nextGranule returns the time following t at the granularity. Days are calendar days of the location of t
+func nextGranule(t time.Time, granularity time.Duration) time.Time {
+	if granularity == 24*time.Hour {
+		return t.AddDate(0, 0, 1)
+	}
+	return t.Add(granularity)
+}
+
+/*
+Canonicalize converts the TimeRange to its canonical form at a discrete resolution.
+
+As PostgreSQL does with discrete range types, finite bounds are turned into an inclusive lower
+bound and an exclusive upper bound: [lower, upper). Bounds are first truncated to the
+granularity, as casting a timestamp to a date does; then an excluded lower bound and an
+included upper bound move to the following granule. Thus [10:00, 11:00] and [10:00, 11:00:01)
+are the same range at second resolution.
+
+A granularity of 24 hours works with calendar days in the location of each bound, so dates are
+not shifted by zone offsets or daylight saving time changes. Other granularities truncate as
+time.Time.Truncate does. Non finite bounds keep their configuration.
+
+Parameters:
+  - granularity: The resolution of the range, e.g. time.Second, time.Minute or 24 * time.Hour.
+    If it is not positive, a copy of the range is returned
+
+Returns:
+  - A new canonical TimeRange, which may be empty if no granule lies within the range
+*/
+func (t *TimeRange) Canonicalize(granularity time.Duration) *TimeRange {
+	if t.IsEmpty() {
+		return EmptyTimeRange()
+	} else if granularity <= 0 {
+		return t.Clone()
+	}
+
+	canonical := t.Clone()
+	if t.lowerLimit == TimeRangeFinite {
+		canonical.lowerBound = truncateTo(t.lowerBound, granularity)
+		if !t.lowerInclusion() {
+			canonical.lowerBound = nextGranule(canonical.lowerBound, granularity)
+		}
+		canonical.boundsConf |= 0b10
+	}
+	if t.upperLimit == TimeRangeFinite {
+		canonical.upperBound = truncateTo(t.upperBound, granularity)
+		if t.upperInclusion() {
+			canonical.upperBound = nextGranule(canonical.upperBound, granularity)
+		}
+		canonical.boundsConf &^= 0b01
+	}
+	if canonical.lowerLimit == TimeRangeFinite && canonical.upperLimit == TimeRangeFinite &&
+		!canonical.lowerBound.Before(canonical.upperBound) {
+		return EmptyTimeRange()
+	}
+	return canonical
+}
+
+/*
+CanonicalEqual determines whether two time ranges are identical at a discrete resolution.
+
+Both ranges are compared in their canonical form, see Canonicalize.
+
+Parameters:
+  - r: The time range to compare with the current range
+  - granularity: The resolution of the comparison
+*/
+func (t *TimeRange) CanonicalEqual(r *TimeRange, granularity time.Duration) bool {
+	return t.Canonicalize(granularity).Equal(r.Canonicalize(granularity))
+}
+
+/*
+CanonicalUnion combines two TimeRange at a discrete resolution.
+
+Both ranges are converted to their canonical form, see Canonicalize, before the union. Thus
+ranges separated by less than a granule, such as [10:00, 11:00] and [11:00:01, 12:00) at second
+resolution, are combined.
+
+Parameters:
+  - r: The TimeRange to union with the current range
+  - granularity: The resolution of the union
+
+Returns:
+  - A new canonical TimeRange representing the union if the ranges can be merged
+  - nil if the ranges are completely separate and cannot be merged
+*/
+func (t *TimeRange) CanonicalUnion(r *TimeRange, granularity time.Duration) *TimeRange {
+	return t.Canonicalize(granularity).Union(r.Canonicalize(granularity))
+}
+
+/*
+Canonicalize converts every range of the set to its canonical form at a discrete resolution.
+
+Parameters:
+  - granularity: The resolution of the ranges, see TimeRange.Canonicalize
+
+Returns:
+  - A normalized MultiTimeRange of canonical ranges
+*/
+func (mr MultiTimeRange) Canonicalize(granularity time.Duration) MultiTimeRange {
+	canonical := make(MultiTimeRange, 0, len(mr))
+	for _, r := range mr {
+		canonical = append(canonical, r.Canonicalize(granularity))
+	}
+	return canonical.Normalize()
+}
@@ -0,0 +1,84 @@
+package bookk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeRangeCanonicalize(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return base.Add(d) }
+
+	type TestCase struct {
+		name        string
+		lower       time.Time
+		upper       time.Time
+		bounds      TimeRangeBound
+		granularity time.Duration
+		expected    string
+	}
+
+	testCases := []TestCase{
+		{"Inclusive upper bound", at(0), at(time.Hour), TimeRangeBoundsInclusion, time.Second, "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:01+00\")"},
+		{"Exclusive lower bound", at(0), at(time.Hour), TimeRangeBoundsExclusion, time.Minute, "[\"2025-01-01 10:01:00+00\",\"2025-01-01 11:00:00+00\")"},
+		{"Truncated bounds", at(1500 * time.Millisecond), at(time.Hour + 500*time.Millisecond), TimeRangeIlEu, time.Second, "[\"2025-01-01 10:00:01+00\",\"2025-01-01 11:00:00+00\")"},
+		{"Days", at(0), at(48 * time.Hour), TimeRangeBoundsInclusion, 24 * time.Hour, "[\"2025-01-01 00:00:00+00\",\"2025-01-04 00:00:00+00\")"},
+		{"No granule inside", at(0), at(time.Second), TimeRangeBoundsExclusion, time.Second, "empty"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			timeRange, _ := NewTimeRange(testCase.lower, testCase.upper, testCase.bounds)
+			if repr := timeRange.Canonicalize(testCase.granularity).ToTstzrangeString(); repr != testCase.expected {
+				t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.expected, repr)
+			}
+		})
+	}
+
+	t.Run("Days in the location of the bounds", func(t *testing.T) {
+		zone := time.FixedZone("", -5*60*60)
+		timeRange, _ := NewTimeRange(time.Date(2025, 1, 1, 22, 0, 0, 0, zone), time.Date(2025, 1, 2, 9, 0, 0, 0, zone), TimeRangeBoundsInclusion)
+
+		expected := "[2025-01-01,2025-01-03)"
+		if repr := timeRange.ToDaterangeString(); repr != expected {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+		}
+	})
+
+	t.Run("Non finite bounds", func(t *testing.T) {
+		timeRange, _ := NewTimeRangeWithLimits(time.Time{}, at(time.Hour), TimeRangeBoundsInclusion, TimeRangeInfinite, TimeRangeFinite)
+
+		expected := "[-infinity,\"2025-01-01 11:00:01+00\")"
+		if repr := timeRange.Canonicalize(time.Second).ToTstzrangeString(); repr != expected {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+		}
+	})
+}
+
+func TestTimeRangeCanonicalComparisons(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	inclusive, _ := NewTimeRange(base, base.Add(time.Hour), TimeRangeBoundsInclusion)
+	halfOpen, _ := NewTimeRange(base, base.Add(time.Hour+time.Second), TimeRangeIlEu)
+	following, _ := NewTimeRange(base.Add(time.Hour+time.Second), base.Add(2*time.Hour), TimeRangeIlEu)
+
+	if inclusive.Equal(halfOpen) {
+		t.Errorf("%s and %s should NOT be equal", inclusive.ToTstzrangeString(), halfOpen.ToTstzrangeString())
+	}
+	if !inclusive.CanonicalEqual(halfOpen, time.Second) {
+		t.Errorf("%s and %s should be equal at second resolution", inclusive.ToTstzrangeString(), halfOpen.ToTstzrangeString())
+	}
+
+	if union := inclusive.Union(following); union != nil {
+		t.Errorf("%s and %s should NOT be combined", inclusive.ToTstzrangeString(), following.ToTstzrangeString())
+	}
+	union := inclusive.CanonicalUnion(following, time.Second)
+	expected := "[\"2025-01-01 10:00:00+00\",\"2025-01-01 12:00:00+00\")"
+	if union == nil || union.ToTstzrangeString() != expected {
+		t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %v", expected, union)
+	}
+
+	set := MultiTimeRange{inclusive, following}.Canonicalize(time.Second)
+	if len(set) != 1 || set[0].ToTstzrangeString() != expected {
+		t.Errorf("No expected output:\nExpecting\t: {%s}\nRecieved\t: %s", expected, set.ToTstzmultirangeString())
+	}
+}
@@ -0,0 +1,383 @@
+package bookk
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const timeRangeBinaryVersion = 1
+
+var (
+	timeRangeJSONError   = errors.New("Invalid JSON time range")
+	timeRangeBinaryError = errors.New("Invalid binary time range")
+)
+
+// timeRangeJSON is the JSON representation of a TimeRange. Missing bounds are null
+type timeRangeJSON struct {
+	Start  *string `json:"start"`
+	End    *string `json:"end"`
+	Bounds string  `json:"bounds"`
+	Empty  bool    `json:"empty,omitempty"`
+}
+
+func formatJSONBound(bound time.Time, limit TimeRangeLimit, infinity string) *string {
+	switch limit {
+	case TimeRangeUnbounded:
+		return nil
+	case TimeRangeInfinite:
+		return &infinity
+	}
+	value := bound.Format(time.RFC3339Nano)
+	return &value
+}
+
+func parseJSONBound(bound *string, infinity string) (time.Time, TimeRangeLimit, error) {
+	if bound == nil {
+		return time.Time{}, TimeRangeUnbounded, nil
+	} else if *bound == infinity {
+		return time.Time{}, TimeRangeInfinite, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, *bound)
+	if err != nil {
+		return time.Time{}, TimeRangeFinite, errors.Join(timeRangeJSONError, err)
+	}
+	return t, TimeRangeFinite, nil
+}
+
+/*
+MarshalJSON implements the json.Marshaler interface.
+
+The range is written as an object with RFC 3339 "start" and "end" times and a "bounds" flag
+using PostgreSQL notation ("[)", "[]", "()" or "(]"). Missing bounds are written as null and
+infinite bounds as "-infinity" or "infinity". An empty range is written as {"empty":true}.
+
+Returns:
+  - The JSON representation of the range
+*/
+func (t TimeRange) MarshalJSON() ([]byte, error) {
+	if t.IsEmpty() {
+		return []byte(`{"empty":true}`), nil
+	}
+
+	bounds := "("
+	if t.lowerInclusion() {
+		bounds = "["
+	}
+	if t.upperInclusion() {
+		bounds += "]"
+	} else {
+		bounds += ")"
+	}
+
+	return json.Marshal(timeRangeJSON{
+		Start:  formatJSONBound(t.lowerBound, t.lowerLimit, "-infinity"),
+		End:    formatJSONBound(t.upperBound, t.upperLimit, "infinity"),
+		Bounds: bounds,
+	})
+}
+
+/*
+UnmarshalJSON implements the json.Unmarshaler interface.
+
+It reads the object written by MarshalJSON. When "bounds" is missing, the range includes its
+start and excludes its end.
+
+Parameters:
+  - data: The JSON representation of the range
+
+Returns:
+  - An error if the object is malformed or describes an invalid range
+*/
+func (t *TimeRange) UnmarshalJSON(data []byte) error {
+	var decoded timeRangeJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return errors.Join(timeRangeJSONError, err)
+	}
+	if decoded.Empty {
+		*t = *EmptyTimeRange()
+		return nil
+	}
+
+	var bounds TimeRangeBound
+	switch decoded.Bounds {
+	case "[)", "":
+		bounds = TimeRangeIlEu
+	case "[]":
+		bounds = TimeRangeBoundsInclusion
+	case "()":
+		bounds = TimeRangeBoundsExclusion
+	case "(]":
+		bounds = TimeRangeElIu
+	default:
+		return errors.Join(timeRangeJSONError, timeRangeInitializationNotRecognizedBoundError)
+	}
+
+	lower, lowerLimit, err := parseJSONBound(decoded.Start, "-infinity")
+	if err != nil {
+		return err
+	}
+	upper, upperLimit, err := parseJSONBound(decoded.End, "infinity")
+	if err != nil {
+		return err
+	}
+
+	decodedRange, err := NewTimeRangeWithLimits(lower, upper, bounds, lowerLimit, upperLimit)
+	if err != nil {
+		return errors.Join(timeRangeJSONError, err)
+	}
+	*t = *decodedRange
+	return nil
+}
+
+/*
+MarshalText implements the encoding.TextMarshaler interface.
+
+The range is written in PostgreSQL tstzrange notation, which keeps its bounds configuration.
+
+Returns:
+  - The text representation of the range
+*/
+func (t TimeRange) MarshalText() ([]byte, error) {
+	return []byte(t.ToTstzrangeString()), nil
+}
+
+/*
+UnmarshalText implements the encoding.TextUnmarshaler interface.
+
+The text may use PostgreSQL range notation (tstzrange, tsrange or daterange) or ISO 8601
+interval notation as accepted by TimeRangeFromISO8601String, e.g. 2025-01-01T10:00:00Z/PT1H.
+ISO 8601 intervals include their start and exclude their end.
+
+Parameters:
+  - text: The text representation of the range
+
+Returns:
+  - A *TimeRangeParseError if the text cannot be properly parsed
+*/
+func (t *TimeRange) UnmarshalText(text []byte) error {
+	var parsed *TimeRange
+	var err error
+
+	trimmed := strings.TrimSpace(string(text))
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "(") || strings.EqualFold(trimmed, "empty") {
+		parsed, err = parseAnyPostgresRange(trimmed)
+	} else {
+		parsed, err = TimeRangeFromISO8601String(trimmed)
+	}
+	if err != nil {
+		return err
+	}
+	*t = *parsed
+	return nil
+}
+
+/*
+MarshalBinary implements the encoding.BinaryMarshaler interface.
+
+The compact form starts with a version byte and a byte packing the bounds configuration,
+limits and emptiness of the range, followed by each finite bound as encoded by time.Time.
+
+Returns:
+  - The binary representation of the range
+*/
+func (t TimeRange) MarshalBinary() ([]byte, error) {
+	if t.IsEmpty() {
+		return []byte{timeRangeBinaryVersion, 1 << 6}, nil
+	}
+
+	flags := byte(t.boundsConf) | byte(t.lowerLimit)<<2 | byte(t.upperLimit)<<4
+	data := []byte{timeRangeBinaryVersion, flags}
+	for _, bound := range [2]struct {
+		time  time.Time
+		limit TimeRangeLimit
+	}{{t.lowerBound, t.lowerLimit}, {t.upperBound, t.upperLimit}} {
+		if bound.limit != TimeRangeFinite {
+			continue
+		}
+		encoded, err := bound.time.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, byte(len(encoded)))
+		data = append(data, encoded...)
+	}
+	return data, nil
+}
+
+/*
+UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+
+Parameters:
+  - data: The binary representation written by MarshalBinary
+
+Returns:
+  - An error if the data is malformed or describes an invalid range
+*/
+func (t *TimeRange) UnmarshalBinary(data []byte) error {
+	decoded, rest, err := decodeTimeRangeBinary(data)
+	if err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return timeRangeBinaryError
+	}
+	*t = *decoded
+	return nil
+}
+
+func decodeTimeRangeBinary(data []byte) (*TimeRange, []byte, error) {
+	if len(data) < 2 || data[0] != timeRangeBinaryVersion {
+		return nil, nil, timeRangeBinaryError
+	}
+	flags := data[1]
+	data = data[2:]
+	if flags&(1<<6) != 0 {
+		return EmptyTimeRange(), data, nil
+	}
+
+	bounds := TimeRangeBound(flags & 0b11)
+	limits := [2]TimeRangeLimit{TimeRangeLimit(flags >> 2 & 0b11), TimeRangeLimit(flags >> 4 & 0b11)}
+	var times [2]time.Time
+	for i, limit := range limits {
+		if limit != TimeRangeFinite {
+			continue
+		}
+		if len(data) < 1 || len(data) < 1+int(data[0]) {
+			return nil, nil, timeRangeBinaryError
+		}
+		if err := times[i].UnmarshalBinary(data[1 : 1+int(data[0])]); err != nil {
+			return nil, nil, errors.Join(timeRangeBinaryError, err)
+		}
+		data = data[1+int(data[0]):]
+	}
+
+	decoded, err := NewTimeRangeWithLimits(times[0], times[1], bounds, limits[0], limits[1])
+	if err != nil {
+		return nil, nil, errors.Join(timeRangeBinaryError, err)
+	}
+	return decoded, data, nil
+}
+
+/*
+MarshalJSON implements the json.Marshaler interface.
+
+The set is written as an array of ranges in the format of TimeRange.MarshalJSON, or null
+for a nil MultiTimeRange.
+
+Returns:
+  - The JSON representation of the normalized set
+*/
+func (mr MultiTimeRange) MarshalJSON() ([]byte, error) {
+	if mr == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal([]*TimeRange(mr.Normalize()))
+}
+
+/*
+UnmarshalJSON implements the json.Unmarshaler interface.
+
+Parameters:
+  - data: An array of ranges in the format of TimeRange.UnmarshalJSON
+
+Returns:
+  - An error if the array is malformed. The decoded set is normalized
+*/
+func (mr *MultiTimeRange) UnmarshalJSON(data []byte) error {
+	var ranges []*TimeRange
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return err
+	}
+	if ranges == nil {
+		*mr = nil
+		return nil
+	}
+	*mr = MultiTimeRange(ranges).Normalize()
+	return nil
+}
+
+/*
+MarshalText implements the encoding.TextMarshaler interface.
+
+The set is written in PostgreSQL tstzmultirange notation.
+
+Returns:
+  - The text representation of the normalized set
+*/
+func (mr MultiTimeRange) MarshalText() ([]byte, error) {
+	return []byte(mr.ToTstzmultirangeString()), nil
+}
+
+/*
+UnmarshalText implements the encoding.TextUnmarshaler interface.
+
+Parameters:
+  - text: A set in PostgreSQL multirange notation (tstzmultirange, tsmultirange or datemultirange)
+
+Returns:
+  - A *TimeRangeParseError if the text cannot be properly parsed
+*/
+func (mr *MultiTimeRange) UnmarshalText(text []byte) error {
+	parsed, err := parseAnyPostgresMultirange(string(text))
+	if err != nil {
+		return err
+	}
+	*mr = parsed
+	return nil
+}
+
+/*
+MarshalBinary implements the encoding.BinaryMarshaler interface.
+
+The compact form is the number of ranges followed by each range as written by
+TimeRange.MarshalBinary.
+
+Returns:
+  - The binary representation of the normalized set
+*/
+func (mr MultiTimeRange) MarshalBinary() ([]byte, error) {
+	normalized := mr.Normalize()
+	data := binary.AppendUvarint(nil, uint64(len(normalized)))
+	for _, r := range normalized {
+		encoded, err := r.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, encoded...)
+	}
+	return data, nil
+}
+
+/*
+UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+
+Parameters:
+  - data: The binary representation written by MarshalBinary
+
+Returns:
+  - An error if the data is malformed
+*/
+func (mr *MultiTimeRange) UnmarshalBinary(data []byte) error {
+	count, read := binary.Uvarint(data)
+	if read <= 0 || count > uint64(len(data)) {
+		return timeRangeBinaryError
+	}
+	data = data[read:]
+
+	decoded := make(MultiTimeRange, 0, count)
+	for range count {
+		r, rest, err := decodeTimeRangeBinary(data)
+		if err != nil {
+			return err
+		}
+		decoded = append(decoded, r)
+		data = rest
+	}
+	if len(data) > 0 {
+		return timeRangeBinaryError
+	}
+	*mr = decoded.Normalize()
+	return nil
+}
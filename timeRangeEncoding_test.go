@@ -0,0 +1,196 @@
+package bookk
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeRangeJSON(t *testing.T) {
+	type TestCase struct {
+		name     string
+		postgres string
+		json     string
+	}
+
+	testCases := [4]TestCase{
+		{
+			"Finite",
+			"(\"2025-01-01 10:00:00.5+02\",\"2025-01-01 11:00:00+00\"]",
+			`{"start":"2025-01-01T10:00:00.5+02:00","end":"2025-01-01T11:00:00Z","bounds":"(]"}`,
+		},
+		{
+			"Missing bound",
+			"[\"2025-01-01 10:00:00+00\",)",
+			`{"start":"2025-01-01T10:00:00Z","end":null,"bounds":"[)"}`,
+		},
+		{
+			"Infinite bounds",
+			"[-infinity,infinity]",
+			`{"start":"-infinity","end":"infinity","bounds":"[]"}`,
+		},
+		{
+			"Empty",
+			"empty",
+			`{"empty":true}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			timeRange, _ := TimeRangeFromTstzrangeString(testCase.postgres)
+
+			// Marshalled as a value inside a struct, as in HTTP responses
+			encoded, err := json.Marshal(struct{ Period TimeRange }{*timeRange})
+			if err != nil {
+				t.Fatalf("Cannot marshal TimeRange. Throwed error: %s", err.Error())
+			}
+			expected := `{"Period":` + testCase.json + `}`
+			if string(encoded) != expected {
+				t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected, encoded)
+			}
+
+			var decoded struct{ Period *TimeRange }
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatalf("Cannot unmarshal TimeRange. Throwed error: %s", err.Error())
+			}
+			if !decoded.Period.Equal(timeRange) {
+				t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.postgres, decoded.Period.ToTstzrangeString())
+			}
+		})
+	}
+
+	t.Run("Default bounds", func(t *testing.T) {
+		var decoded TimeRange
+		if err := json.Unmarshal([]byte(`{"start":"2025-01-01T10:00:00Z","end":"2025-01-01T11:00:00Z"}`), &decoded); err != nil {
+			t.Fatalf("Cannot unmarshal TimeRange. Throwed error: %s", err.Error())
+		}
+		if decoded.boundsConf != TimeRangeIlEu {
+			t.Errorf("Bounds should default to [). Instead: %s", decoded.ToTstzrangeString())
+		}
+	})
+
+	t.Run("Failed due to malformed object", func(t *testing.T) {
+		for _, testCase := range [3]string{
+			`{"start":"yesterday","end":"2025-01-01T11:00:00Z"}`,
+			`{"start":"2025-01-01T10:00:00Z","end":"2025-01-01T11:00:00Z","bounds":"{}"}`,
+			`{"start":"2025-01-01T12:00:00Z","end":"2025-01-01T11:00:00Z"}`,
+		} {
+			var decoded TimeRange
+			if err := json.Unmarshal([]byte(testCase), &decoded); !errors.Is(err, timeRangeJSONError) {
+				t.Errorf("Unmarshalling %s should have failed due to: %s. Instead: %v", testCase, timeRangeJSONError.Error(), err)
+			}
+		}
+	})
+}
+
+func TestTimeRangeText(t *testing.T) {
+	type TestCase struct {
+		input    string
+		expected string
+	}
+
+	testCases := [4]TestCase{
+		{"[\"2025-01-01 10:00:00+02\",\"2025-01-01 11:00:00+02\"]", "[\"2025-01-01 10:00:00+02\",\"2025-01-01 11:00:00+02\"]"},
+		{"[2025-01-01,2025-01-02)", "[\"2025-01-01 00:00:00+00\",\"2025-01-02 00:00:00+00\")"},
+		{"2025-01-01T10:00:00Z/2025-01-01T11:00:00.25Z", "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00.25+00\")"},
+		{"2025-01-01T10:00:00-03:00/..", "[\"2025-01-01 10:00:00-03\",)"},
+	}
+
+	for _, testCase := range testCases {
+		var decoded TimeRange
+		if err := decoded.UnmarshalText([]byte(testCase.input)); err != nil {
+			t.Errorf("Cannot unmarshal %s. Throwed error: %s", testCase.input, err.Error())
+			continue
+		}
+
+		encoded, _ := decoded.MarshalText()
+		if string(encoded) != testCase.expected {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.expected, encoded)
+		}
+	}
+
+	t.Run("Failed due to malformed text", func(t *testing.T) {
+		var decoded TimeRange
+		if err := decoded.UnmarshalText([]byte("2025-01-01T10:00:00Z")); !errors.Is(err, timeRangeParseError) {
+			t.Errorf("Should have failed due to: %s. Instead: %v", timeRangeParseError.Error(), err)
+		}
+	})
+}
+
+func TestTimeRangeBinary(t *testing.T) {
+	zone := time.FixedZone("", -3*60*60)
+	testCases := [4]*TimeRange{
+		hoursRange(0, 1, TimeRangeElIu),
+		EmptyTimeRange(),
+	}
+	testCases[2], _ = NewTimeRangeFrom(time.Date(2025, 1, 1, 10, 0, 0, 123, zone), TimeRangeIlEu)
+	testCases[3], _ = NewTimeRangeWithLimits(time.Time{}, time.Time{}, TimeRangeBoundsInclusion, TimeRangeInfinite, TimeRangeUnbounded)
+
+	for _, testCase := range testCases {
+		encoded, err := testCase.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Cannot marshal TimeRange. Throwed error: %s", err.Error())
+		}
+
+		var decoded TimeRange
+		if err := decoded.UnmarshalBinary(encoded); err != nil {
+			t.Fatalf("Cannot unmarshal TimeRange. Throwed error: %s", err.Error())
+		}
+		if !decoded.Equal(testCase) || decoded.ToTstzrangeString() != testCase.ToTstzrangeString() {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.ToTstzrangeString(), decoded.ToTstzrangeString())
+		}
+	}
+
+	t.Run("Failed due to truncated data", func(t *testing.T) {
+		encoded, _ := testCases[0].MarshalBinary()
+		var decoded TimeRange
+		if err := decoded.UnmarshalBinary(encoded[:len(encoded)-1]); !errors.Is(err, timeRangeBinaryError) {
+			t.Errorf("Should have failed due to: %s. Instead: %v", timeRangeBinaryError.Error(), err)
+		}
+	})
+}
+
+func TestMultiTimeRangeEncoding(t *testing.T) {
+	original := NewMultiTimeRange(hoursRange(0, 1, TimeRangeIlEu), hoursRange(2, 3, TimeRangeBoundsInclusion))
+
+	t.Run("JSON", func(t *testing.T) {
+		encoded, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("Cannot marshal MultiTimeRange. Throwed error: %s", err.Error())
+		}
+
+		var decoded MultiTimeRange
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatalf("Cannot unmarshal MultiTimeRange. Throwed error: %s", err.Error())
+		}
+		if multiHoursRepr(decoded) != multiHoursRepr(original) {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", multiHoursRepr(original), multiHoursRepr(decoded))
+		}
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		encoded, _ := original.MarshalText()
+
+		var decoded MultiTimeRange
+		if err := decoded.UnmarshalText(encoded); err != nil {
+			t.Fatalf("Cannot unmarshal MultiTimeRange. Throwed error: %s", err.Error())
+		}
+		if decoded.ToTstzmultirangeString() != string(encoded) {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", encoded, decoded.ToTstzmultirangeString())
+		}
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		encoded, _ := original.MarshalBinary()
+
+		var decoded MultiTimeRange
+		if err := decoded.UnmarshalBinary(encoded); err != nil {
+			t.Fatalf("Cannot unmarshal MultiTimeRange. Throwed error: %s", err.Error())
+		}
+		if multiHoursRepr(decoded) != multiHoursRepr(original) {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", multiHoursRepr(original), multiHoursRepr(decoded))
+		}
+	})
+}
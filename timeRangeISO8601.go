@@ -0,0 +1,392 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeRangeISO8601DurationError  = errors.New("Invalid ISO 8601 duration")
+	timeRangeISO8601RepeatingError = errors.New("Cannot write ranges as an ISO 8601 repeating interval")
+)
+
+// iso8601TimeLayouts lists the accepted ISO 8601 representations of a time, in extended and basic format
+var iso8601TimeLayouts = func() []string {
+	layouts := []string{}
+	for _, base := range [6]string{
+		"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02T15",
+		"20060102T150405", "20060102T1504", "20060102T15",
+	} {
+		for _, zone := range [4]string{"Z07:00", "Z0700", "Z07", ""} {
+			layouts = append(layouts, base+zone)
+		}
+	}
+	return append(layouts, "2006-01-02", "20060102")
+}()
+
+// iso8601Duration is a duration whose years, months and days depend on the calendar
+type iso8601Duration struct {
+	years, months, days int
+	clock               time.Duration
+}
+
+func (d iso8601Duration) addTo(t time.Time, times int) time.Time {
+	return t.AddDate(d.years*times, d.months*times, d.days*times).Add(d.clock * time.Duration(times))
+}
+
+func parseISO8601Time(value string) (time.Time, error) {
+	var firstErr error
+	for _, layout := range iso8601TimeLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return time.Time{}, firstErr
+}
+
+// parseISO8601Duration reads durations such as P1Y2M10DT2H30M, P2W or PT1.5H. Years and months must be integers
+func parseISO8601Duration(value string) (iso8601Duration, error) {
+	var duration iso8601Duration
+	if len(value) < 2 || value[0] != 'P' {
+		return duration, timeRangeISO8601DurationError
+	}
+
+	inTime, components := false, 0
+	rest := value[1:]
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return duration, timeRangeISO8601DurationError
+			}
+			inTime = true
+			rest = rest[1:]
+			continue
+		}
+
+		end := strings.IndexAny(rest, "YMWDHS")
+		if end <= 0 {
+			return duration, timeRangeISO8601DurationError
+		}
+		number, err := strconv.ParseFloat(strings.Replace(rest[:end], ",", ".", 1), 64)
+		if err != nil || number < 0 {
+			return duration, timeRangeISO8601DurationError
+		}
+
+		whole := number == float64(int(number))
+		switch designator := rest[end]; {
+		case !inTime && designator == 'Y' && whole:
+			duration.years += int(number)
+		case !inTime && designator == 'M' && whole:
+			duration.months += int(number)
+		case !inTime && designator == 'W':
+			duration.clock += time.Duration(number * 7 * float64(24*time.Hour))
+		case !inTime && designator == 'D':
+			duration.days += int(number)
+			duration.clock += time.Duration((number - float64(int(number))) * float64(24*time.Hour))
+		case inTime && designator == 'H':
+			duration.clock += time.Duration(number * float64(time.Hour))
+		case inTime && designator == 'M':
+			duration.clock += time.Duration(number * float64(time.Minute))
+		case inTime && designator == 'S':
+			duration.clock += time.Duration(number * float64(time.Second))
+		default:
+			return duration, timeRangeISO8601DurationError
+		}
+		components++
+		rest = rest[end+1:]
+	}
+
+	if components == 0 {
+		return duration, timeRangeISO8601DurationError
+	}
+	return duration, nil
+}
+
+func formatISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("PT")
+	if hours := d / time.Hour; hours > 0 {
+		fmt.Fprintf(&builder, "%dH", hours)
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		fmt.Fprintf(&builder, "%dM", minutes)
+		d -= minutes * time.Minute
+	}
+	if d > 0 {
+		builder.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S")
+	}
+	return builder.String()
+}
+
+// splitISO8601Zone separates the zone designator, if any, from the end of a time representation
+func splitISO8601Zone(value string) (string, string) {
+	anchor := max(strings.IndexByte(value, 'T'), strings.IndexByte(value, ':'))
+	if anchor < 0 {
+		return value, ""
+	} else if strings.HasSuffix(value, "Z") {
+		return value[:len(value)-1], "Z"
+	} else if zone := strings.LastIndexAny(value, "+-"); zone > anchor {
+		return value[:zone], value[zone:]
+	}
+	return value, ""
+}
+
+// expandISO8601End completes an abbreviated end, e.g. 15:30 after 2025-01-01T13:30, with the components of start
+func expandISO8601End(start, end string) string {
+	startBody, startZone := splitISO8601Zone(start)
+	endBody, endZone := splitISO8601Zone(end)
+	if len(endBody) >= len(startBody) {
+		return end
+	}
+	if endZone == "" {
+		endZone = startZone
+	}
+	return startBody[:len(startBody)-len(endBody)] + endBody + endZone
+}
+
+// iso8601Interval is an ISO 8601 interval before being turned into a TimeRange
+type iso8601Interval struct {
+	start, end                   time.Time
+	startLimit, endLimit         TimeRangeLimit
+	duration                     iso8601Duration
+	hasDuration, durationIsStart bool
+	endIsDate                    bool // The end is a date, so the interval takes the whole day
+}
+
+func (interval iso8601Interval) timeRange() (*TimeRange, error) {
+	start, end := interval.start, interval.end
+	if interval.endIsDate {
+		end = end.AddDate(0, 0, 1)
+	}
+	if interval.hasDuration && interval.durationIsStart {
+		start = interval.duration.addTo(end, -1)
+	} else if interval.hasDuration {
+		end = interval.duration.addTo(start, 1)
+	}
+	return NewTimeRangeWithLimits(start, end, TimeRangeIlEu, interval.startLimit, interval.endLimit)
+}
+
+func parseISO8601IntervalParts(input string, offset int) (iso8601Interval, error) {
+	var interval iso8601Interval
+	fail := func(position int, expected string, err error) error {
+		return &TimeRangeParseError{Input: input, Position: position, Expected: expected, Err: err}
+	}
+
+	body := input[offset:]
+	separator, separatorLength := strings.IndexByte(body, '/'), 1
+	if separator < 0 {
+		separator, separatorLength = strings.Index(body, "--"), 2
+	}
+	if separator < 0 {
+		return interval, fail(len(input), "'/'", nil)
+	}
+	start, end := body[:separator], body[separator+separatorLength:]
+	endPosition := offset + separator + separatorLength
+
+	startIsDuration, endIsDuration := strings.HasPrefix(start, "P"), strings.HasPrefix(end, "P")
+	if startIsDuration && endIsDuration {
+		return interval, fail(endPosition, "time or '..'", nil)
+	}
+
+	var err error
+	switch {
+	case startIsDuration:
+		interval.hasDuration, interval.durationIsStart = true, true
+		if interval.duration, err = parseISO8601Duration(start); err != nil {
+			return interval, fail(offset, "duration", err)
+		}
+	case start == "..":
+		interval.startLimit = TimeRangeUnbounded
+	default:
+		if interval.start, err = parseISO8601Time(start); err != nil {
+			return interval, fail(offset, "time, duration or '..'", err)
+		}
+	}
+
+	switch {
+	case endIsDuration:
+		interval.hasDuration = true
+		if interval.duration, err = parseISO8601Duration(end); err != nil {
+			return interval, fail(endPosition, "duration", err)
+		}
+	case end == "..":
+		interval.endLimit = TimeRangeUnbounded
+	default:
+		if interval.end, err = parseISO8601Time(end); err != nil && !startIsDuration && start != ".." {
+			end = expandISO8601End(start, end)
+			interval.end, err = parseISO8601Time(end)
+		}
+		if err != nil {
+			return interval, fail(endPosition, "time, duration or '..'", err)
+		}
+		interval.endIsDate = !strings.ContainsRune(end, 'T')
+	}
+
+	if interval.hasDuration && (interval.startLimit != TimeRangeFinite || interval.endLimit != TimeRangeFinite) {
+		return interval, fail(offset, "time on the other side of the duration", nil)
+	}
+	return interval, nil
+}
+
+/*
+TimeRangeFromISO8601String creates a TimeRange from an ISO 8601 time interval.
+
+All the interval forms are recognized: start and end (2025-01-01T10:00:00Z/2025-01-01T11:00:00Z),
+start and duration (2025-01-01T10:00:00Z/PT1H), duration and end (PT1H/2025-01-01T11:00:00Z)
+and abbreviated ends (2025-01-01T10:00/11:30). A missing bound may be written as ".." and
+"--" may be used instead of "/". Times without zone designator are read as UTC and dates as
+their midnight, but for a date ending the interval, which takes the whole day up to the next
+midnight (2025-01-01/2025-01-07 ends on 2025-01-08T00:00:00Z). As usual for ISO 8601 intervals, the range includes its start and excludes its end.
+
+Parameters:
+  - r: A string in ISO 8601 interval format
+
+Returns:
+  - A pointer to a new TimeRange object
+  - A *TimeRangeParseError if the string cannot be properly parsed
+*/
+func TimeRangeFromISO8601String(r string) (*TimeRange, error) {
+	interval, err := parseISO8601IntervalParts(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	newRange, err := interval.timeRange()
+	if err != nil {
+		return nil, &TimeRangeParseError{Input: r, Position: 0, Expected: "start before end", Err: err}
+	}
+	return newRange, nil
+}
+
+/*
+MultiTimeRangeFromISO8601String creates the occurrences of an ISO 8601 repeating interval.
+
+The string has the form Rn/<interval>, e.g. R5/2025-01-01T09:00:00Z/P1D, where <interval> is any
+form accepted by TimeRangeFromISO8601String and n is the number of occurrences. Each occurrence
+starts where the previous one ends; when the interval is given as a duration and an end, the
+occurrences go backwards from the end. A string without the Rn/ prefix is a single occurrence.
+
+Since consecutive occurrences touch each other, the result is not normalized: it holds every
+occurrence in chronological order. Use Normalize to merge them into the covered periods.
+
+Parameters:
+  - r: A string in ISO 8601 repeating interval format
+
+Returns:
+  - A MultiTimeRange with the occurrences of the interval
+  - A *TimeRangeParseError if the string cannot be properly parsed or repeats forever
+*/
+func MultiTimeRangeFromISO8601String(r string) (MultiTimeRange, error) {
+	if !strings.HasPrefix(r, "R") {
+		single, err := TimeRangeFromISO8601String(r)
+		if err != nil {
+			return nil, err
+		}
+		return MultiTimeRange{single}, nil
+	}
+
+	separator := strings.IndexByte(r, '/')
+	if separator < 0 {
+		return nil, &TimeRangeParseError{Input: r, Position: len(r), Expected: "'/'"}
+	}
+	repetitions, err := strconv.Atoi(r[1:separator])
+	if err != nil || repetitions < 0 {
+		return nil, &TimeRangeParseError{Input: r, Position: 1, Expected: "number of repetitions", Err: err}
+	}
+
+	interval, err := parseISO8601IntervalParts(r, separator+1)
+	if err != nil {
+		return nil, err
+	}
+	if interval.startLimit != TimeRangeFinite || interval.endLimit != TimeRangeFinite {
+		return nil, &TimeRangeParseError{Input: r, Position: separator + 1, Expected: "bounded interval"}
+	}
+
+	first, err := interval.timeRange()
+	if err != nil {
+		return nil, &TimeRangeParseError{Input: r, Position: separator + 1, Expected: "start before end", Err: err}
+	}
+
+	occurrences := make(MultiTimeRange, 0, repetitions)
+	for i := range repetitions {
+		occurrence := first.Clone()
+		switch {
+		case interval.hasDuration && interval.durationIsStart:
+			occurrence.lowerBound = interval.duration.addTo(first.lowerBound, -i)
+			occurrence.upperBound = interval.duration.addTo(first.upperBound, -i)
+		case interval.hasDuration:
+			occurrence.lowerBound = interval.duration.addTo(first.lowerBound, i)
+			occurrence.upperBound = interval.duration.addTo(first.upperBound, i)
+		default:
+			length := first.upperBound.Sub(first.lowerBound)
+			occurrence.lowerBound = first.lowerBound.Add(length * time.Duration(i))
+			occurrence.upperBound = first.upperBound.Add(length * time.Duration(i))
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	sort.Sort(occurrences)
+	return occurrences, nil
+}
+
+/*
+ToISO8601String converts the TimeRange to an ISO 8601 time interval.
+
+The interval is written as <start>/<end> with RFC 3339 times. Missing and infinite bounds are
+written as "..". ISO 8601 intervals have no inclusion/exclusion configuration, so it is not
+kept; an empty range is written as an empty string.
+
+Returns:
+  - A string representation of the time range in ISO 8601 format
+*/
+func (t *TimeRange) ToISO8601String() string {
+	if t.IsEmpty() {
+		return ""
+	}
+
+	start, end := "..", ".."
+	if t.lowerLimit == TimeRangeFinite {
+		start = t.lowerBound.Format(time.RFC3339Nano)
+	}
+	if t.upperLimit == TimeRangeFinite {
+		end = t.upperBound.Format(time.RFC3339Nano)
+	}
+	return start + "/" + end
+}
+
+/*
+ToISO8601String converts the MultiTimeRange to an ISO 8601 repeating interval.
+
+The occurrences must be consecutive finite ranges of the same length, as returned by
+MultiTimeRangeFromISO8601String, and are written as Rn/<start>/<duration>.
+
+Returns:
+  - A string representation of the occurrences in ISO 8601 format
+  - An error if the set is empty or the occurrences cannot be written as a repeating interval
+*/
+func (mr MultiTimeRange) ToISO8601String() (string, error) {
+	if len(mr) == 0 {
+		return "", timeRangeISO8601RepeatingError
+	}
+
+	first := mr[0]
+	length := first.upperBound.Sub(first.lowerBound)
+	for i, r := range mr {
+		if r.lowerLimit != TimeRangeFinite || r.upperLimit != TimeRangeFinite ||
+			r.upperBound.Sub(r.lowerBound) != length || (i > 0 && !r.lowerBound.Equal(mr[i-1].upperBound)) {
+			return "", timeRangeISO8601RepeatingError
+		}
+	}
+	return fmt.Sprintf("R%d/%s/%s", len(mr), first.lowerBound.Format(time.RFC3339Nano), formatISO8601Duration(length)), nil
+}
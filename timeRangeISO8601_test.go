@@ -0,0 +1,145 @@
+package bookk
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTimeRangeFromISO8601String(t *testing.T) {
+	type TestCase struct {
+		input    string
+		expected string
+	}
+
+	testCases := []TestCase{
+		{"2025-01-01T10:00:00Z/2025-01-01T11:00:00Z", "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")"},
+		{"2025-01-01T10:00:00Z/PT1H", "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")"},
+		{"PT1H30M/2025-01-01T11:00:00+02:00", "[\"2025-01-01 09:30:00+02\",\"2025-01-01 11:00:00+02\")"},
+		{"2025-01-01/2025-01-07", "[\"2025-01-01 00:00:00+00\",\"2025-01-08 00:00:00+00\")"},
+		{"P1D/2025-01-07", "[\"2025-01-07 00:00:00+00\",\"2025-01-08 00:00:00+00\")"},
+		{"2025-01-31/P1M", "[\"2025-01-31 00:00:00+00\",\"2025-03-03 00:00:00+00\")"},
+		{"2025-01-01T10:00/P1DT0.5H", "[\"2025-01-01 10:00:00+00\",\"2025-01-02 10:30:00+00\")"},
+		{"2025-01-01/P2W", "[\"2025-01-01 00:00:00+00\",\"2025-01-15 00:00:00+00\")"},
+		{"2025-01-01T13:30-03:00/15:30", "[\"2025-01-01 13:30:00-03\",\"2025-01-01 15:30:00-03\")"},
+		{"2025-02-15/03-14", "[\"2025-02-15 00:00:00+00\",\"2025-03-15 00:00:00+00\")"},
+		{"20250101T100000Z--20250101T110000,5Z", "[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00.5+00\")"},
+		{"2025-01-01T10:00:00Z/..", "[\"2025-01-01 10:00:00+00\",)"},
+		{"../2025-01-01T10:00:00Z", "(,\"2025-01-01 10:00:00+00\")"},
+	}
+
+	for _, testCase := range testCases {
+		timeRange, err := TimeRangeFromISO8601String(testCase.input)
+		if err != nil {
+			t.Errorf("There is an error in ISO 8601 parser. Parser Error: %s", err.Error())
+			continue
+		}
+		if repr := timeRange.ToTstzrangeString(); repr != testCase.expected {
+			t.Errorf("No expected output for %s:\nExpecting\t: %s\nRecieved\t: %s", testCase.input, testCase.expected, repr)
+		}
+	}
+
+	t.Run("Failed due to malformed interval", func(t *testing.T) {
+		type FailCase struct {
+			input    string
+			position int
+		}
+
+		failCases := [6]FailCase{
+			{"2025-01-01T10:00:00Z", 20},
+			{"2025-01-01T10:00:00Z/P1H", 21},
+			{"tomorrow/PT1H", 0},
+			{"PT1H/PT2H", 5},
+			{"../PT1H", 0},
+			{"2025-01-03/2025-01-01", 0},
+		}
+
+		for _, failCase := range failCases {
+			_, err := TimeRangeFromISO8601String(failCase.input)
+			var parseError *TimeRangeParseError
+			if !errors.As(err, &parseError) || !errors.Is(err, timeRangeParseError) {
+				t.Errorf("Parsing %q should have failed with a TimeRangeParseError. Instead: %v", failCase.input, err)
+			} else if parseError.Position != failCase.position {
+				t.Errorf("Parsing %q should have failed at position %d. Instead: %d", failCase.input, failCase.position, parseError.Position)
+			}
+		}
+	})
+}
+
+func TestTimeRangeToISO8601String(t *testing.T) {
+	testCases := [3]string{
+		"2025-01-01T10:00:00.5+02:00/2025-01-01T11:00:00+02:00",
+		"2025-01-01T10:00:00Z/..",
+		"../..",
+	}
+
+	for _, testCase := range testCases {
+		timeRange, _ := TimeRangeFromISO8601String(testCase)
+		if repr := timeRange.ToISO8601String(); repr != testCase {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase, repr)
+		}
+	}
+}
+
+func TestMultiTimeRangeFromISO8601String(t *testing.T) {
+	type TestCase struct {
+		input    string
+		expected string
+	}
+
+	testCases := [4]TestCase{
+		{
+			"R3/2025-01-01T09:00:00Z/P1D",
+			"{[\"2025-01-01 09:00:00+00\",\"2025-01-02 09:00:00+00\")," +
+				"[\"2025-01-02 09:00:00+00\",\"2025-01-03 09:00:00+00\")," +
+				"[\"2025-01-03 09:00:00+00\",\"2025-01-04 09:00:00+00\")}",
+		},
+		{
+			"R2/2025-01-01T09:00:00Z/2025-01-01T10:00:00Z",
+			"{[\"2025-01-01 09:00:00+00\",\"2025-01-01 10:00:00+00\"),[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")}",
+		},
+		{
+			"R2/PT1H/2025-01-01T10:00:00Z",
+			"{[\"2025-01-01 08:00:00+00\",\"2025-01-01 09:00:00+00\"),[\"2025-01-01 09:00:00+00\",\"2025-01-01 10:00:00+00\")}",
+		},
+		{"R0/2025-01-01T09:00:00Z/PT1H", "{}"},
+	}
+
+	for _, testCase := range testCases {
+		occurrences, err := MultiTimeRangeFromISO8601String(testCase.input)
+		if err != nil {
+			t.Errorf("There is an error in ISO 8601 parser. Parser Error: %s", err.Error())
+			continue
+		}
+
+		// Occurrences are not normalized, so they are written one by one
+		repr := "{"
+		for i, occurrence := range occurrences {
+			if i > 0 {
+				repr += ","
+			}
+			repr += occurrence.ToTstzrangeString()
+		}
+		repr += "}"
+		if repr != testCase.expected {
+			t.Errorf("No expected output for %s:\nExpecting\t: %s\nRecieved\t: %s", testCase.input, testCase.expected, repr)
+		}
+	}
+
+	t.Run("Formats back", func(t *testing.T) {
+		occurrences, _ := MultiTimeRangeFromISO8601String("R5/2025-01-01T09:00:00Z/PT1H30M")
+		expected := "R5/2025-01-01T09:00:00Z/PT1H30M"
+		if repr, err := occurrences.ToISO8601String(); err != nil || repr != expected {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s (%v)", expected, repr, err)
+		}
+
+		if _, err := NewMultiTimeRange(hoursRange(0, 1, TimeRangeIlEu), hoursRange(2, 3, TimeRangeIlEu)).ToISO8601String(); !errors.Is(err, timeRangeISO8601RepeatingError) {
+			t.Errorf("Should have failed due to: %s. Instead: %v", timeRangeISO8601RepeatingError.Error(), err)
+		}
+	})
+
+	t.Run("Failed due to endless repetitions", func(t *testing.T) {
+		if _, err := MultiTimeRangeFromISO8601String("R/2025-01-01T09:00:00Z/P1D"); !errors.Is(err, timeRangeParseError) {
+			t.Errorf("Should have failed due to: %s. Instead: %v", timeRangeParseError.Error(), err)
+		}
+	})
+}
@@ -0,0 +1,472 @@
+package bookk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	postgresTimestampLayout = "2006-01-02 15:04:05.999999"
+	postgresDateLayout      = time.DateOnly
+)
+
+/*
+TimeRangeParseError describes where and why a range string could not be parsed.
+
+It matches the generic time range parse error with errors.Is and, when the failure comes from
+parsing a time value, unwraps to the error returned by the time package.
+*/
+type TimeRangeParseError struct {
+	Input    string // The string being parsed
+	Position int    // Byte offset of the input where the parser failed
+	Expected string // Description of the token the parser expected at Position
+	Err      error  // Underlying error, if any
+}
+
+func (e *TimeRangeParseError) Error() string {
+	message := fmt.Sprintf("Error parsing time range %q at position %d: expected %s", e.Input, e.Position, e.Expected)
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *TimeRangeParseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeRangeParseError) Is(target error) bool {
+	return target == timeRangeParseError
+}
+
+// postgresRangeSubtype describes how the bounds of a PostgreSQL range type are read and written
+type postgresRangeSubtype struct {
+	name      string
+	parse     func(string) (time.Time, error)
+	format    func(time.Time) string
+	canonical bool // Whether the range type is discrete and canonicalized to [lower, upper)
+}
+
+var (
+	postgresTstzrange = postgresRangeSubtype{
+		name:   "timestamp with time zone",
+		parse:  parsePostgresTimestamptz,
+		format: formatPostgresTimestamptz,
+	}
+	postgresTsrange = postgresRangeSubtype{
+		name: "timestamp",
+		parse: func(s string) (time.Time, error) {
+			return time.Parse(postgresTimestampLayout, s)
+		},
+		format: func(t time.Time) string {
+			return t.Format(postgresTimestampLayout)
+		},
+	}
+	postgresDaterange = postgresRangeSubtype{
+		name: "date",
+		parse: func(s string) (time.Time, error) {
+			return time.Parse(postgresDateLayout, s)
+		},
+		format: func(t time.Time) string {
+			return t.Format(postgresDateLayout)
+		},
+		canonical: true,
+	}
+)
+
+func parsePostgresTimestamptz(s string) (time.Time, error) {
+	// PostgreSQL only writes the minutes and seconds of the offset when they are not zero
+	var err error
+	for _, layout := range [3]string{"2006-01-02 15:04:05-07", "2006-01-02 15:04:05-07:00", "2006-01-02 15:04:05-07:00:00"} {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func formatPostgresTimestamptz(t time.Time) string {
+	_, offset := t.Zone()
+	switch {
+	case offset%60 != 0:
+		return t.Format(postgresTimestampLayout + "-07:00:00")
+	case offset%3600 != 0:
+		return t.Format(postgresTimestampLayout + "-07:00")
+	}
+	return t.Format(postgresTimestampLayout + "-07")
+}
+
+/*
+TimeRangeFromTstzrangeString creates a TimeRange from the text representation of a PostgreSQL tstzrange.
+
+Zone offsets and fractional seconds are kept, e.g. ["2025-01-01 10:00:00.123+02","2025-01-01 11:00:00+02").
+Missing bounds, -infinity, infinity and empty are recognized as in TimeRangeFromPostgresString.
+
+Parameters:
+  - r: A string in PostgreSQL tstzrange format
+
+Returns:
+  - A pointer to a new TimeRange object
+  - A *TimeRangeParseError if the string cannot be properly parsed
+*/
+func TimeRangeFromTstzrangeString(r string) (*TimeRange, error) {
+	return parsePostgresRange(r, postgresTstzrange)
+}
+
+/*
+TimeRangeFromTsrangeString creates a TimeRange from the text representation of a PostgreSQL tsrange.
+
+Fractional seconds are kept and the bounds are read as UTC, since tsrange has no time zone.
+
+Parameters:
+  - r: A string in PostgreSQL tsrange format, e.g. ["2025-01-01 10:00:00.5","2025-01-01 11:00:00")
+
+Returns:
+  - A pointer to a new TimeRange object
+  - A *TimeRangeParseError if the string cannot be properly parsed
+*/
+func TimeRangeFromTsrangeString(r string) (*TimeRange, error) {
+	return parsePostgresRange(r, postgresTsrange)
+}
+
+/*
+TimeRangeFromDaterangeString creates a TimeRange from the text representation of a PostgreSQL daterange.
+
+The bounds are read as midnight UTC of each date. As PostgreSQL does, the result is canonicalized
+to an inclusive lower bound and an exclusive upper bound, so [2025-01-01,2025-01-07] becomes
+[2025-01-01,2025-01-08).
+
+Parameters:
+  - r: A string in PostgreSQL daterange format, e.g. [2025-01-01,2025-01-08)
+
+Returns:
+  - A pointer to a new TimeRange object
+  - A *TimeRangeParseError if the string cannot be properly parsed
+*/
+func TimeRangeFromDaterangeString(r string) (*TimeRange, error) {
+	return parsePostgresRange(r, postgresDaterange)
+}
+
+/*
+ToTstzrangeString converts the TimeRange to the text representation of a PostgreSQL tstzrange.
+
+Each bound is written with its own zone offset and up to microsecond precision.
+
+Returns:
+  - A string representation of the time range in PostgreSQL tstzrange format
+*/
+func (t *TimeRange) ToTstzrangeString() string {
+	return formatPostgresRange(t, postgresTstzrange)
+}
+
+/*
+ToTsrangeString converts the TimeRange to the text representation of a PostgreSQL tsrange.
+
+Each bound is written as the wall clock of its own location, up to microsecond precision.
+
+Returns:
+  - A string representation of the time range in PostgreSQL tsrange format
+*/
+func (t *TimeRange) ToTsrangeString() string {
+	return formatPostgresRange(t, postgresTsrange)
+}
+
+/*
+ToDaterangeString converts the TimeRange to the text representation of a PostgreSQL daterange.
+
+Bounds are reduced to the date of their own location and canonicalized to [lower, upper),
+so an excluded lower date or an included upper date move to the following day.
+
+Returns:
+  - A string representation of the time range in PostgreSQL daterange format
+*/
+func (t *TimeRange) ToDaterangeString() string {
+	return formatPostgresRange(t, postgresDaterange)
+}
+
+func formatPostgresRange(t *TimeRange, subtype postgresRangeSubtype) string {
+	if subtype.canonical {
+		t = t.Canonicalize(24 * time.Hour)
+	}
+	if t.IsEmpty() {
+		return "empty"
+	}
+
+	lower, upper := "(", ")"
+	if t.lowerInclusion() {
+		lower = "["
+	}
+	if t.upperInclusion() {
+		upper = "]"
+	}
+
+	return lower + formatPostgresBound(t.lowerBound, t.lowerLimit, "-infinity", subtype.format) + "," +
+		formatPostgresBound(t.upperBound, t.upperLimit, "infinity", subtype.format) + upper
+}
+
+func formatPostgresBound(bound time.Time, limit TimeRangeLimit, infinity string, format func(time.Time) string) string {
+	switch limit {
+	case TimeRangeUnbounded:
+		return ""
+	case TimeRangeInfinite:
+		return infinity
+	}
+
+	// As PostgreSQL does, values are only quoted when they contain special characters
+	value := format(bound)
+	if strings.ContainsAny(value, " ,()[]\"\\") {
+		return "\"" + value + "\""
+	}
+	return value
+}
+
+// postgresRangeParser reads the text representation of PostgreSQL ranges
+type postgresRangeParser struct {
+	input string
+	pos   int
+}
+
+func (p *postgresRangeParser) fail(expected string, err error) error {
+	return &TimeRangeParseError{Input: p.input, Position: p.pos, Expected: expected, Err: err}
+}
+
+func (p *postgresRangeParser) skipSpaces() {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\n\r\v\f", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *postgresRangeParser) expect(chars string, expected string) (byte, error) {
+	if p.pos >= len(p.input) || strings.IndexByte(chars, p.input[p.pos]) < 0 {
+		return 0, p.fail(expected, nil)
+	}
+	p.pos++
+	return p.input[p.pos-1], nil
+}
+
+// readBound reads a possibly quoted bound value until one of the terminators is found
+func (p *postgresRangeParser) readBound(terminators string) (value string, quoted bool, err error) {
+	var builder strings.Builder
+	inQuotes := false
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\':
+			if p.pos+1 >= len(p.input) {
+				p.pos++
+				return "", quoted, p.fail("escaped character", nil)
+			}
+			builder.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+		case c == '"' && inQuotes && p.pos+1 < len(p.input) && p.input[p.pos+1] == '"':
+			builder.WriteByte('"')
+			p.pos += 2
+		case c == '"':
+			inQuotes = !inQuotes
+			quoted = true
+			p.pos++
+		case !inQuotes && strings.IndexByte(terminators, c) >= 0:
+			return builder.String(), quoted, nil
+		default:
+			builder.WriteByte(c)
+			p.pos++
+		}
+	}
+	if inQuotes {
+		return "", quoted, p.fail("closing quote", nil)
+	}
+	return builder.String(), quoted, nil
+}
+
+func (p *postgresRangeParser) parseBound(terminators, infinity string, subtype postgresRangeSubtype) (time.Time, TimeRangeLimit, error) {
+	start := p.pos
+	value, quoted, err := p.readBound(terminators)
+	if err != nil {
+		return time.Time{}, TimeRangeFinite, err
+	}
+
+	if !quoted {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return time.Time{}, TimeRangeUnbounded, nil
+		}
+	}
+	if strings.EqualFold(value, infinity) {
+		return time.Time{}, TimeRangeInfinite, nil
+	}
+
+	bound, err := subtype.parse(value)
+	if err != nil {
+		p.pos = start
+		return time.Time{}, TimeRangeFinite, p.fail(fmt.Sprintf("%s or %s", subtype.name, infinity), err)
+	}
+	return bound, TimeRangeFinite, nil
+}
+
+// parseRange reads a single range, or the empty keyword, from the current position
+func (p *postgresRangeParser) parseRange(subtype postgresRangeSubtype) (*TimeRange, error) {
+	p.skipSpaces()
+	if len(p.input)-p.pos >= 5 && strings.EqualFold(p.input[p.pos:p.pos+5], "empty") {
+		p.pos += 5
+		return EmptyTimeRange(), nil
+	}
+
+	start := p.pos
+	opening, err := p.expect("[(", "'[' or '('")
+	if err != nil {
+		return nil, err
+	}
+	lower, lowerLimit, err := p.parseBound(",", "-infinity", subtype)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(",", "','"); err != nil {
+		return nil, err
+	}
+	upper, upperLimit, err := p.parseBound("])", "infinity", subtype)
+	if err != nil {
+		return nil, err
+	}
+	closing, err := p.expect("])", "']' or ')'")
+	if err != nil {
+		return nil, err
+	}
+
+	newRange, err := NewTimeRangeWithLimits(
+		lower,
+		upper,
+		boundsFromInclusion(opening == '[', closing == ']'),
+		lowerLimit,
+		upperLimit,
+	)
+	if err != nil {
+		return nil, &TimeRangeParseError{Input: p.input, Position: start, Expected: "lower bound before upper bound", Err: err}
+	}
+	if subtype.canonical {
+		return newRange.Canonicalize(24 * time.Hour), nil
+	}
+	return newRange, nil
+}
+
+func (p *postgresRangeParser) end() error {
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return p.fail("end of input", nil)
+	}
+	return nil
+}
+
+func parsePostgresRange(input string, subtype postgresRangeSubtype) (*TimeRange, error) {
+	p := &postgresRangeParser{input: input}
+	newRange, err := p.parseRange(subtype)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.end(); err != nil {
+		return nil, err
+	}
+	return newRange, nil
+}
+
+func parsePostgresMultirange(input string, subtype postgresRangeSubtype) (MultiTimeRange, error) {
+	p := &postgresRangeParser{input: input}
+	p.skipSpaces()
+	if _, err := p.expect("{", "'{'"); err != nil {
+		return nil, err
+	}
+
+	ranges := MultiTimeRange{}
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == '}' {
+		p.pos++
+	} else {
+		for {
+			newRange, err := p.parseRange(subtype)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, newRange)
+
+			p.skipSpaces()
+			separator, err := p.expect(",}", "',' or '}'")
+			if err != nil {
+				return nil, err
+			}
+			if separator == '}' {
+				break
+			}
+		}
+	}
+
+	if err := p.end(); err != nil {
+		return nil, err
+	}
+	return ranges.Normalize(), nil
+}
+
+func formatPostgresMultirange(mr MultiTimeRange, subtype postgresRangeSubtype) string {
+	ranges := make([]string, 0, len(mr))
+	for _, r := range mr.Normalize() {
+		ranges = append(ranges, formatPostgresRange(r, subtype))
+	}
+	return "{" + strings.Join(ranges, ",") + "}"
+}
+
+/*
+MultiTimeRangeFromTstzmultirangeString creates a MultiTimeRange from the text representation
+of a PostgreSQL tstzmultirange.
+
+Parameters:
+  - mr: A string in PostgreSQL tstzmultirange format, e.g.
+    {["2025-01-01 10:00:00+00","2025-01-01 11:00:00+00"),["2025-01-01 12:00:00+00",)}
+
+Returns:
+  - A normalized MultiTimeRange
+  - A *TimeRangeParseError if the string cannot be properly parsed
+*/
+func MultiTimeRangeFromTstzmultirangeString(mr string) (MultiTimeRange, error) {
+	return parsePostgresMultirange(mr, postgresTstzrange)
+}
+
+/*
+ToTstzmultirangeString converts the MultiTimeRange to the text representation of a PostgreSQL tstzmultirange.
+
+Returns:
+  - A string representation of the normalized set in PostgreSQL tstzmultirange format
+*/
+func (mr MultiTimeRange) ToTstzmultirangeString() string {
+	return formatPostgresMultirange(mr, postgresTstzrange)
+}
+
+// postgresAnySubtypes lists the range types recognized when the type is unknown, from the most to the least precise
+var postgresAnySubtypes = [3]postgresRangeSubtype{postgresTstzrange, postgresTsrange, postgresDaterange}
+
+// parseAnyPostgresRange reads a tstzrange, tsrange or daterange, returning the tstzrange error if none matches
+func parseAnyPostgresRange(input string) (*TimeRange, error) {
+	var firstErr error
+	for _, subtype := range postgresAnySubtypes {
+		parsed, err := parsePostgresRange(input, subtype)
+		if err == nil {
+			return parsed, nil
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// parseAnyPostgresMultirange reads a tstzmultirange, tsmultirange or datemultirange
+func parseAnyPostgresMultirange(input string) (MultiTimeRange, error) {
+	var firstErr error
+	for _, subtype := range postgresAnySubtypes {
+		parsed, err := parsePostgresMultirange(input, subtype)
+		if err == nil {
+			return parsed, nil
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
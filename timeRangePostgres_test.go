@@ -0,0 +1,129 @@
+package bookk
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeRangeTstzrangeString(t *testing.T) {
+	testCases := [5]string{
+		"[\"2025-01-01 10:00:00.123+02\",\"2025-01-01 11:00:00+02\")",
+		"(\"2025-01-01 10:00:00.123456-03\",\"2025-01-01 14:00:00+00\"]",
+		"[\"2025-01-01 10:00:00+05:30\",infinity]",
+		"(,\"2025-01-01 10:00:00.5+00:00:36\")",
+		"empty",
+	}
+
+	for _, testCase := range testCases {
+		timeRange, err := TimeRangeFromTstzrangeString(testCase)
+		if err != nil {
+			t.Errorf("There is an error in tstzrange parser. Parser Error: %s", err.Error())
+			continue
+		}
+
+		if repr := timeRange.ToTstzrangeString(); repr != testCase {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase, repr)
+		}
+	}
+
+	t.Run("Keeps offset and fractional seconds", func(t *testing.T) {
+		timeRange, _ := TimeRangeFromTstzrangeString(testCases[0])
+		expected := time.Date(2025, 1, 1, 8, 0, 0, 123000000, time.UTC)
+		if !timeRange.lowerBound.Equal(expected) {
+			t.Errorf("Lower bound should be %s. Instead: %s", expected, timeRange.lowerBound)
+		}
+		if _, offset := timeRange.lowerBound.Zone(); offset != 2*60*60 {
+			t.Errorf("Lower bound offset should be +02. Instead: %d seconds", offset)
+		}
+	})
+}
+
+func TestTimeRangeTsrangeString(t *testing.T) {
+	testCase := "[\"2025-01-01 10:00:00.25\",\"2025-01-01 11:00:00\")"
+	timeRange, err := TimeRangeFromTsrangeString(testCase)
+	if err != nil {
+		t.Fatalf("There is an error in tsrange parser. Parser Error: %s", err.Error())
+	}
+
+	if repr := timeRange.ToTsrangeString(); repr != testCase {
+		t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase, repr)
+	}
+	if timeRange.lowerBound.Location() != time.UTC {
+		t.Errorf("tsrange bounds should be read as UTC. Instead: %s", timeRange.lowerBound.Location())
+	}
+}
+
+func TestTimeRangeDaterangeString(t *testing.T) {
+	type TestCase struct {
+		input    string
+		expected string
+	}
+
+	testCases := [4]TestCase{
+		{"[2025-01-01,2025-01-08)", "[2025-01-01,2025-01-08)"},
+		{"[2025-01-01,2025-01-07]", "[2025-01-01,2025-01-08)"},
+		{"(2024-12-31,2025-01-07]", "[2025-01-01,2025-01-08)"},
+		{"(2025-01-01,2025-01-02)", "empty"},
+	}
+
+	for _, testCase := range testCases {
+		timeRange, err := TimeRangeFromDaterangeString(testCase.input)
+		if err != nil {
+			t.Errorf("There is an error in daterange parser. Parser Error: %s", err.Error())
+			continue
+		}
+
+		if repr := timeRange.ToDaterangeString(); repr != testCase.expected {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.expected, repr)
+		}
+	}
+}
+
+func TestTimeRangeParseError(t *testing.T) {
+	type TestCase struct {
+		input    string
+		position int
+		expected string
+	}
+
+	testCases := [6]TestCase{
+		{"", 0, "'[' or '('"},
+		{"  {2025-01-01,2025-01-02)", 2, "'[' or '('"},
+		{"[\"2025-01-01\"", 13, "','"},
+		{"[2025-01-01,2025-13-02)", 12, "date or infinity"},
+		{"[2025-01-01,\"2025-01-02)", 24, "closing quote"},
+		{"[2025-01-01,2025-01-02) x", 24, "end of input"},
+	}
+
+	for _, testCase := range testCases {
+		_, err := TimeRangeFromDaterangeString(testCase.input)
+
+		var parseError *TimeRangeParseError
+		if !errors.As(err, &parseError) {
+			t.Errorf("Parsing %q should have failed with a TimeRangeParseError. Instead: %v", testCase.input, err)
+			continue
+		}
+		if !errors.Is(err, timeRangeParseError) {
+			t.Errorf("Parsing %q should have failed due to: %s", testCase.input, timeRangeParseError.Error())
+		}
+		if parseError.Position != testCase.position || parseError.Expected != testCase.expected {
+			t.Errorf(
+				"Parsing %q should have failed at position %d expecting %s. Instead: position %d expecting %s",
+				testCase.input,
+				testCase.position,
+				testCase.expected,
+				parseError.Position,
+				parseError.Expected,
+			)
+		}
+	}
+
+	t.Run("Unwraps time errors", func(t *testing.T) {
+		_, err := TimeRangeFromTstzrangeString("[\"2025-01-01 10:00:00\",)")
+		var timeError *time.ParseError
+		if !errors.As(err, &timeError) {
+			t.Errorf("Missing offset should unwrap to a time.ParseError. Instead: %v", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package bookk
+
+// TimeRangeRelation is one of the relations of Allen's interval algebra between two TimeRange.
+type TimeRangeRelation byte
+
+const (
+	TimeRangeUnrelated    TimeRangeRelation = iota // Any of the ranges is empty
+	TimeRangeBefore                                // The range ends before the other starts, leaving a gap between them
+	TimeRangeMeets                                 // The range ends exactly where the other starts, without sharing any instant
+	TimeRangeOverlaps                              // The range starts first and ends inside the other
+	TimeRangeStarts                                // Both start together and the range ends first
+	TimeRangeDuring                                // The range starts after and ends before the other
+	TimeRangeFinishes                              // The range starts after the other and both end together
+	TimeRangeEquals                                // Both ranges have the same instants
+	TimeRangeFinishedBy                            // Inverse of TimeRangeFinishes
+	TimeRangeContains                              // Inverse of TimeRangeDuring
+	TimeRangeStartedBy                             // Inverse of TimeRangeStarts
+	TimeRangeOverlappedBy                          // Inverse of TimeRangeOverlaps
+	TimeRangeMetBy                                 // Inverse of TimeRangeMeets
+	TimeRangeAfter                                 // Inverse of TimeRangeBefore
+)
+
+var timeRangeRelationNames = [...]string{
+	"unrelated",
+	"before",
+	"meets",
+	"overlaps",
+	"starts",
+	"during",
+	"finishes",
+	"equals",
+	"finished by",
+	"contains",
+	"started by",
+	"overlapped by",
+	"met by",
+	"after",
+}
+
+func (relation TimeRangeRelation) String() string {
+	if int(relation) < len(timeRangeRelationNames) {
+		return timeRangeRelationNames[relation]
+	}
+	return "unknown"
+}
+
+/*
+Inverse returns the relation seen from the other range.
+
+If t.Relation(r) is TimeRangeBefore, then r.Relation(t) is TimeRangeAfter and so on.
+TimeRangeEquals and TimeRangeUnrelated are their own inverse.
+*/
+func (relation TimeRangeRelation) Inverse() TimeRangeRelation {
+	if relation == TimeRangeUnrelated || relation > TimeRangeAfter {
+		return relation
+	}
+	return TimeRangeAfter + TimeRangeBefore - relation
+}
+
+/*
+SharesInstants tells whether ranges in this relation have at least one instant in common.
+
+This is the case for every relation except before, meets, met by, after and unrelated.
+*/
+func (relation TimeRangeRelation) SharesInstants() bool {
+	return relation >= TimeRangeOverlaps && relation <= TimeRangeOverlappedBy
+}
+
+/*
+Relation classifies how the current TimeRange relates to another TimeRange following Allen's
+interval algebra.
+
+The inclusion/exclusion configuration of the bounds is taken into account: [10:00, 11:00) meets
+[11:00, 12:00), and so does (10:00, 11:00) as only one of them includes 11:00. [10:00, 11:00]
+overlaps it because both include 11:00, and (10:00, 11:00) is before (11:00, 12:00) because
+11:00 is left out of both.
+
+Parameters:
+  - r: The TimeRange to compare with the current range
+
+Returns:
+  - The relation of the current range with r, or TimeRangeUnrelated if any of them is empty
+*/
+func (t *TimeRange) Relation(r *TimeRange) TimeRangeRelation {
+	if t.IsEmpty() || r.IsEmpty() {
+		return TimeRangeUnrelated
+	}
+
+	switch compareUpperToLower(t, r) {
+	case -1:
+		return TimeRangeBefore
+	case 0:
+		return TimeRangeMeets
+	}
+	switch compareUpperToLower(r, t) {
+	case -1:
+		return TimeRangeAfter
+	case 0:
+		return TimeRangeMetBy
+	}
+
+	lower, upper := compareLowerBounds(t, r), compareUpperBounds(t, r)
+	switch {
+	case lower == 0 && upper == 0:
+		return TimeRangeEquals
+	case lower == 0 && upper < 0:
+		return TimeRangeStarts
+	case lower == 0:
+		return TimeRangeStartedBy
+	case upper == 0 && lower > 0:
+		return TimeRangeFinishes
+	case upper == 0:
+		return TimeRangeFinishedBy
+	case lower > 0 && upper < 0:
+		return TimeRangeDuring
+	case lower < 0 && upper > 0:
+		return TimeRangeContains
+	case lower < 0:
+		return TimeRangeOverlaps
+	}
+	return TimeRangeOverlappedBy
+}
@@ -0,0 +1,86 @@
+package bookk
+
+import (
+	"testing"
+)
+
+func TestTimeRangeRelation(t *testing.T) {
+	type TestCase struct {
+		first    *TimeRange
+		second   *TimeRange
+		expected TimeRangeRelation
+	}
+
+	testCases := []TestCase{
+		{hoursRange(0, 1, TimeRangeIlEu), hoursRange(2, 3, TimeRangeIlEu), TimeRangeBefore},
+		{hoursRange(0, 1, TimeRangeBoundsExclusion), hoursRange(1, 2, TimeRangeBoundsExclusion), TimeRangeBefore},
+		{hoursRange(0, 1, TimeRangeIlEu), hoursRange(1, 2, TimeRangeIlEu), TimeRangeMeets},
+		{hoursRange(0, 1, TimeRangeBoundsExclusion), hoursRange(1, 2, TimeRangeIlEu), TimeRangeMeets},
+		{hoursRange(0, 1, TimeRangeBoundsInclusion), hoursRange(1, 2, TimeRangeElIu), TimeRangeMeets},
+		{hoursRange(0, 1, TimeRangeBoundsInclusion), hoursRange(1, 2, TimeRangeBoundsInclusion), TimeRangeOverlaps},
+		{hoursRange(0, 2, TimeRangeIlEu), hoursRange(1, 3, TimeRangeIlEu), TimeRangeOverlaps},
+		{hoursRange(0, 1, TimeRangeIlEu), hoursRange(0, 2, TimeRangeIlEu), TimeRangeStarts},
+		{hoursRange(0, 2, TimeRangeElIu), hoursRange(0, 2, TimeRangeBoundsInclusion), TimeRangeFinishes},
+		{hoursRange(1, 2, TimeRangeIlEu), hoursRange(0, 3, TimeRangeIlEu), TimeRangeDuring},
+		{hoursRange(0, 2, TimeRangeBoundsExclusion), hoursRange(0, 2, TimeRangeBoundsInclusion), TimeRangeDuring},
+		{hoursRange(1, 2, TimeRangeIlEu), hoursRange(0, 2, TimeRangeIlEu), TimeRangeFinishes},
+		{hoursRange(0, 2, TimeRangeIlEu), hoursRange(0, 2, TimeRangeIlEu), TimeRangeEquals},
+		{hoursRange(0, 3, TimeRangeIlEu), hoursRange(1, 2, TimeRangeIlEu), TimeRangeContains},
+		{hoursRange(1, 3, TimeRangeIlEu), hoursRange(0, 2, TimeRangeIlEu), TimeRangeOverlappedBy},
+		{hoursRange(1, 2, TimeRangeIlEu), hoursRange(0, 1, TimeRangeIlEu), TimeRangeMetBy},
+		{hoursRange(2, 3, TimeRangeIlEu), hoursRange(0, 1, TimeRangeIlEu), TimeRangeAfter},
+		{EmptyTimeRange(), hoursRange(0, 1, TimeRangeIlEu), TimeRangeUnrelated},
+	}
+
+	for _, testCase := range testCases {
+		relation := testCase.first.Relation(testCase.second)
+		if relation != testCase.expected {
+			t.Errorf(
+				"No expected relation between %s and %s:\nExpecting\t: %s\nRecieved\t: %s",
+				hoursRepr(testCase.first),
+				hoursRepr(testCase.second),
+				testCase.expected,
+				relation,
+			)
+		}
+
+		inverse := testCase.second.Relation(testCase.first)
+		if inverse != testCase.expected.Inverse() {
+			t.Errorf(
+				"No expected relation between %s and %s:\nExpecting\t: %s\nRecieved\t: %s",
+				hoursRepr(testCase.second),
+				hoursRepr(testCase.first),
+				testCase.expected.Inverse(),
+				inverse,
+			)
+		}
+	}
+}
+
+func TestTimeRangeOverlaps(t *testing.T) {
+	type TestCase struct {
+		first    *TimeRange
+		second   *TimeRange
+		expected int8
+	}
+
+	testCases := [5]TestCase{
+		{hoursRange(0, 2, TimeRangeIlEu), hoursRange(1, 3, TimeRangeIlEu), 1},
+		{hoursRange(1, 3, TimeRangeIlEu), hoursRange(0, 2, TimeRangeIlEu), -1},
+		{hoursRange(0, 3, TimeRangeIlEu), hoursRange(1, 2, TimeRangeIlEu), 1},
+		{hoursRange(1, 2, TimeRangeIlEu), hoursRange(0, 3, TimeRangeIlEu), -1},
+		{hoursRange(0, 1, TimeRangeIlEu), hoursRange(1, 2, TimeRangeIlEu), 0},
+	}
+
+	for _, testCase := range testCases {
+		if overlap := testCase.first.Overlaps(testCase.second); overlap != testCase.expected {
+			t.Errorf(
+				"No expected overlap between %s and %s:\nExpecting\t: %d\nRecieved\t: %d",
+				hoursRepr(testCase.first),
+				hoursRepr(testCase.second),
+				testCase.expected,
+				overlap,
+			)
+		}
+	}
+}
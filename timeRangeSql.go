@@ -0,0 +1,107 @@
+package bookk
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+var (
+	timeRangeScanError     = errors.New("Cannot scan value into time range")
+	timeRangeScanNullError = errors.New("Cannot scan NULL into time range")
+)
+
+func scanText(src any) (string, error) {
+	switch value := src.(type) {
+	case string:
+		return value, nil
+	case []byte:
+		return string(value), nil
+	}
+	return "", fmt.Errorf("%w: unsupported type %T", timeRangeScanError, src)
+}
+
+/*
+Scan implements the sql.Scanner interface for PostgreSQL range columns.
+
+The value is read from the text representation of a tstzrange, tsrange or daterange column,
+in that order of preference.
+
+Parameters:
+  - src: The value read from the database, as a string or []byte
+
+Returns:
+  - An error if the value is NULL, is not text or is not a valid range
+*/
+func (t *TimeRange) Scan(src any) error {
+	if src == nil {
+		return timeRangeScanNullError
+	}
+	text, err := scanText(src)
+	if err != nil {
+		return err
+	}
+
+	scanned, err := parseAnyPostgresRange(text)
+	if err != nil {
+		return err
+	}
+	*t = *scanned
+	return nil
+}
+
+/*
+Value implements the driver.Valuer interface for PostgreSQL range columns.
+
+The range is written as a tstzrange, keeping the zone offset and microseconds of each bound.
+
+Returns:
+  - The text representation of the range in PostgreSQL tstzrange format
+*/
+func (t TimeRange) Value() (driver.Value, error) {
+	return t.ToTstzrangeString(), nil
+}
+
+/*
+Scan implements the sql.Scanner interface for PostgreSQL multirange columns.
+
+The value is read from the text representation of a tstzmultirange, tsmultirange or
+datemultirange column, in that order of preference. NULL is scanned as a nil MultiTimeRange.
+
+Parameters:
+  - src: The value read from the database, as a string or []byte
+
+Returns:
+  - An error if the value is not text or is not a valid multirange
+*/
+func (mr *MultiTimeRange) Scan(src any) error {
+	if src == nil {
+		*mr = nil
+		return nil
+	}
+	text, err := scanText(src)
+	if err != nil {
+		return err
+	}
+
+	scanned, err := parseAnyPostgresMultirange(text)
+	if err != nil {
+		return err
+	}
+	*mr = scanned
+	return nil
+}
+
+/*
+Value implements the driver.Valuer interface for PostgreSQL multirange columns.
+
+Returns:
+  - The text representation of the normalized set in PostgreSQL tstzmultirange format,
+    or nil (NULL) for a nil MultiTimeRange
+*/
+func (mr MultiTimeRange) Value() (driver.Value, error) {
+	if mr == nil {
+		return nil, nil
+	}
+	return mr.ToTstzmultirangeString(), nil
+}
@@ -0,0 +1,122 @@
+package bookk
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestTimeRangeScan(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+
+	testCases := [4]driver.Value{
+		"[\"2025-01-01 10:00:00.123+02\",\"2025-01-01 11:00:00+02\")",
+		[]byte("[\"2025-01-01 10:00:00\",\"2025-01-01 11:00:00\")"),
+		"[2025-01-01,2025-01-02)",
+		"empty",
+	}
+	expected := [4]string{
+		"[\"2025-01-01 10:00:00.123+02\",\"2025-01-01 11:00:00+02\")",
+		"[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\")",
+		"[\"2025-01-01 00:00:00+00\",\"2025-01-02 00:00:00+00\")",
+		"empty",
+	}
+
+	for i, testCase := range testCases {
+		fake.expect("SELECT period").willReturnRows([]string{"period"}, []driver.Value{testCase})
+
+		var timeRange TimeRange
+		if err := db.QueryRow("SELECT period FROM bookings").Scan(&timeRange); err != nil {
+			t.Errorf("Cannot scan %v. Throwed error: %s", testCase, err.Error())
+			continue
+		}
+		if repr := timeRange.ToTstzrangeString(); repr != expected[i] {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected[i], repr)
+		}
+	}
+
+	t.Run("Failed due to NULL", func(t *testing.T) {
+		fake.expect("SELECT period").willReturnRows([]string{"period"}, []driver.Value{nil})
+
+		var timeRange TimeRange
+		err := db.QueryRow("SELECT period FROM bookings").Scan(&timeRange)
+		if !errors.Is(err, timeRangeScanNullError) {
+			t.Errorf("Should have failed to scan due to: %s. Instead: %v", timeRangeScanNullError.Error(), err)
+		}
+	})
+
+	t.Run("Failed due to malformed range", func(t *testing.T) {
+		fake.expect("SELECT period").willReturnRows([]string{"period"}, []driver.Value{"[2025-01-01"})
+
+		var timeRange TimeRange
+		err := db.QueryRow("SELECT period FROM bookings").Scan(&timeRange)
+		if !errors.Is(err, timeRangeParseError) {
+			t.Errorf("Should have failed to scan due to: %s. Instead: %v", timeRangeParseError.Error(), err)
+		}
+	})
+}
+
+func TestTimeRangeValue(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+	timeRange, _ := TimeRangeFromTstzrangeString("[\"2025-01-01 10:00:00.5+02\",)")
+
+	fake.expect("INSERT INTO bookings", "[\"2025-01-01 10:00:00.5+02\",)").willAffect(1)
+	if _, err := db.Exec("INSERT INTO bookings (period) VALUES ($1)", timeRange); err != nil {
+		t.Errorf("Cannot write TimeRange pointer. Throwed error: %s", err.Error())
+	}
+
+	fake.expect("INSERT INTO bookings", "[\"2025-01-01 10:00:00.5+02\",)").willAffect(1)
+	if _, err := db.Exec("INSERT INTO bookings (period) VALUES ($1)", *timeRange); err != nil {
+		t.Errorf("Cannot write TimeRange value. Throwed error: %s", err.Error())
+	}
+}
+
+func TestMultiTimeRangeScanValue(t *testing.T) {
+	db, fake := newFakeDatabase(t)
+
+	multirange := "{[\"2025-01-01 10:00:00+00\",\"2025-01-01 11:00:00+00\"),[\"2025-01-01 12:00:00+00\",)}"
+	fake.expect("SELECT periods").willReturnRows(
+		[]string{"periods"},
+		[]driver.Value{[]byte(multirange)},
+		[]driver.Value{"{}"},
+		[]driver.Value{nil},
+	)
+
+	rows, err := db.Query("SELECT periods FROM availability")
+	if err != nil {
+		t.Fatalf("Cannot query multiranges. Throwed error: %s", err.Error())
+	}
+	defer rows.Close()
+
+	scanned := []MultiTimeRange{}
+	for rows.Next() {
+		var periods MultiTimeRange
+		if err := rows.Scan(&periods); err != nil {
+			t.Fatalf("Cannot scan multirange. Throwed error: %s", err.Error())
+		}
+		scanned = append(scanned, periods)
+	}
+
+	if len(scanned) != 3 {
+		t.Fatalf("Should have scanned 3 multiranges. Instead: %d", len(scanned))
+	}
+	if repr := scanned[0].ToTstzmultirangeString(); repr != multirange {
+		t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", multirange, repr)
+	}
+	if scanned[1] == nil || len(scanned[1]) != 0 {
+		t.Errorf("Empty multirange should be scanned as an empty set. Instead: %v", scanned[1])
+	}
+	if scanned[2] != nil {
+		t.Errorf("NULL should be scanned as a nil set. Instead: %v", scanned[2])
+	}
+
+	fake.expect("INSERT INTO availability", multirange).willAffect(1)
+	if _, err := db.Exec("INSERT INTO availability (periods) VALUES ($1)", scanned[0]); err != nil {
+		t.Errorf("Cannot write MultiTimeRange. Throwed error: %s", err.Error())
+	}
+
+	fake.expect("INSERT INTO availability", nil).willAffect(1)
+	if _, err := db.Exec("INSERT INTO availability (periods) VALUES ($1)", MultiTimeRange(nil)); err != nil {
+		t.Errorf("Cannot write nil MultiTimeRange. Throwed error: %s", err.Error())
+	}
+}
@@ -0,0 +1,304 @@
+package bookk
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+/*
+TimeRangeTree is an interval tree indexing TimeRange by a key, such as a booking ID.
+
+It is a treap ordered by lower bound where every node knows the furthest upper bound of its
+subtree, so overlap queries only visit the branches that can hold a match. Bound inclusion is
+taken into account by every query: ranges that only touch at an excluded bound do not overlap.
+
+A TimeRangeTree is not safe for concurrent use.
+*/
+type TimeRangeTree[K comparable] struct {
+	root  *timeRangeTreeNode[K]
+	nodes map[K]*timeRangeTreeNode[K]
+	seq   uint64
+}
+
+type timeRangeTreeNode[K comparable] struct {
+	key         K
+	timeRange   *TimeRange
+	priority    uint64
+	seq         uint64 // Keeps the insertion order of ranges with the same lower bound
+	left, right *timeRangeTreeNode[K]
+	maxUpper    *TimeRange // Range of the subtree with the furthest upper bound
+}
+
+/*
+NewTimeRangeTree creates an empty TimeRangeTree.
+
+Returns:
+  - A pointer to a new TimeRangeTree
+*/
+func NewTimeRangeTree[K comparable]() *TimeRangeTree[K] {
+	return &TimeRangeTree[K]{nodes: map[K]*timeRangeTreeNode[K]{}}
+}
+
+func (n *timeRangeTreeNode[K]) less(other *timeRangeTreeNode[K]) bool {
+	if c := compareLowerBounds(n.timeRange, other.timeRange); c != 0 {
+		return c < 0
+	}
+	return n.seq < other.seq
+}
+
+func (n *timeRangeTreeNode[K]) update() {
+	n.maxUpper = n.timeRange
+	for _, child := range [2]*timeRangeTreeNode[K]{n.left, n.right} {
+		if child != nil && compareUpperBounds(child.maxUpper, n.maxUpper) > 0 {
+			n.maxUpper = child.maxUpper
+		}
+	}
+}
+
+func rotateRight[K comparable](n *timeRangeTreeNode[K]) *timeRangeTreeNode[K] {
+	left := n.left
+	n.left = left.right
+	n.update()
+	left.right = n
+	left.update()
+	return left
+}
+
+func rotateLeft[K comparable](n *timeRangeTreeNode[K]) *timeRangeTreeNode[K] {
+	right := n.right
+	n.right = right.left
+	n.update()
+	right.left = n
+	right.update()
+	return right
+}
+
+func insertTreeNode[K comparable](root, n *timeRangeTreeNode[K]) *timeRangeTreeNode[K] {
+	if root == nil {
+		n.update()
+		return n
+	}
+
+	if n.less(root) {
+		root.left = insertTreeNode(root.left, n)
+		if root.left.priority > root.priority {
+			return rotateRight(root)
+		}
+	} else {
+		root.right = insertTreeNode(root.right, n)
+		if root.right.priority > root.priority {
+			return rotateLeft(root)
+		}
+	}
+	root.update()
+	return root
+}
+
+func mergeTreeNodes[K comparable](left, right *timeRangeTreeNode[K]) *timeRangeTreeNode[K] {
+	if left == nil {
+		return right
+	} else if right == nil {
+		return left
+	}
+
+	if left.priority > right.priority {
+		left.right = mergeTreeNodes(left.right, right)
+		left.update()
+		return left
+	}
+	right.left = mergeTreeNodes(left, right.left)
+	right.update()
+	return right
+}
+
+func deleteTreeNode[K comparable](root, n *timeRangeTreeNode[K]) *timeRangeTreeNode[K] {
+	if root == nil {
+		return nil
+	} else if root == n {
+		return mergeTreeNodes(root.left, root.right)
+	}
+
+	if n.less(root) {
+		root.left = deleteTreeNode(root.left, n)
+	} else {
+		root.right = deleteTreeNode(root.right, n)
+	}
+	root.update()
+	return root
+}
+
+// Len returns the number of ranges in the tree.
+func (tree *TimeRangeTree[K]) Len() int {
+	return len(tree.nodes)
+}
+
+/*
+Insert adds a TimeRange to the tree under a key.
+
+Parameters:
+  - key: The key identifying the range. If it is already in the tree, its range is replaced
+  - r: The TimeRange to index. It is copied; empty ranges are not indexed, so inserting one
+    just removes the key from the tree
+*/
+func (tree *TimeRangeTree[K]) Insert(key K, r *TimeRange) {
+	tree.Delete(key)
+	if r.IsEmpty() {
+		return
+	}
+
+	tree.seq++
+	n := &timeRangeTreeNode[K]{key: key, timeRange: r.Clone(), priority: rand.Uint64(), seq: tree.seq}
+	tree.nodes[key] = n
+	tree.root = insertTreeNode(tree.root, n)
+}
+
+/*
+Delete removes the range of a key from the tree.
+
+Parameters:
+  - key: The key identifying the range
+
+Returns:
+  - true if the key was in the tree
+*/
+func (tree *TimeRangeTree[K]) Delete(key K) bool {
+	n, found := tree.nodes[key]
+	if !found {
+		return false
+	}
+	delete(tree.nodes, key)
+	tree.root = deleteTreeNode(tree.root, n)
+	return true
+}
+
+/*
+Get returns the range indexed under a key.
+
+Parameters:
+  - key: The key identifying the range
+
+Returns:
+  - A copy of the range, or nil if the key is not in the tree
+  - true if the key is in the tree
+*/
+func (tree *TimeRangeTree[K]) Get(key K) (*TimeRange, bool) {
+	n, found := tree.nodes[key]
+	if !found {
+		return nil, false
+	}
+	return n.timeRange.Clone(), true
+}
+
+/*
+Overlapping finds every range of the tree sharing at least one instant with a TimeRange.
+
+Parameters:
+  - r: The TimeRange to look for
+
+Returns:
+  - The keys of the overlapping ranges, ordered by their lower bound
+*/
+func (tree *TimeRangeTree[K]) Overlapping(r *TimeRange) []K {
+	keys := []K{}
+	if r.IsEmpty() {
+		return keys
+	}
+	tree.collectOverlapping(tree.root, r, &keys)
+	return keys
+}
+
+func (tree *TimeRangeTree[K]) collectOverlapping(n *timeRangeTreeNode[K], r *TimeRange, keys *[]K) {
+	// Nothing in this subtree reaches r
+	if n == nil || compareUpperToLower(n.maxUpper, r) <= 0 {
+		return
+	}
+
+	tree.collectOverlapping(n.left, r, keys)
+	if compareUpperToLower(n.timeRange, r) > 0 && compareUpperToLower(r, n.timeRange) > 0 {
+		*keys = append(*keys, n.key)
+	}
+
+	// Ranges in the right subtree start after this one, so they cannot reach r if r ends before it
+	if compareUpperToLower(r, n.timeRange) > 0 {
+		tree.collectOverlapping(n.right, r, keys)
+	}
+}
+
+/*
+Containing finds every range of the tree containing an instant.
+
+Parameters:
+  - instant: The instant to look for
+
+Returns:
+  - The keys of the ranges containing the instant, ordered by their lower bound
+*/
+func (tree *TimeRangeTree[K]) Containing(instant time.Time) []K {
+	return tree.Overlapping(&TimeRange{lowerBound: instant, upperBound: instant, boundsConf: TimeRangeBoundsInclusion})
+}
+
+// coveringStart finds the range, among the ones starting no later than cursor and sharing its first instant,
+// that reaches further
+func (tree *TimeRangeTree[K]) coveringStart(n *timeRangeTreeNode[K], cursor *TimeRange, furthest *TimeRange) *TimeRange {
+	if n == nil || compareUpperToLower(n.maxUpper, cursor) <= 0 {
+		return furthest
+	}
+
+	furthest = tree.coveringStart(n.left, cursor, furthest)
+	if compareLowerBounds(n.timeRange, cursor) > 0 {
+		// Neither this range nor the ones of the right subtree start before the cursor
+		return furthest
+	}
+	if compareUpperToLower(n.timeRange, cursor) > 0 && (furthest == nil || compareUpperBounds(n.timeRange, furthest) > 0) {
+		furthest = n.timeRange
+	}
+	return tree.coveringStart(n.right, cursor, furthest)
+}
+
+// nextStart finds the range with the earliest lower bound after the one of cursor
+func (tree *TimeRangeTree[K]) nextStart(cursor *TimeRange) *TimeRange {
+	var next *TimeRange
+	for n := tree.root; n != nil; {
+		if compareLowerBounds(n.timeRange, cursor) > 0 {
+			next = n.timeRange
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return next
+}
+
+/*
+NextFreeGap finds the first period, starting at or after an instant, not covered by any range of the tree.
+
+Parameters:
+  - after: The instant where the search starts
+
+Returns:
+  - The free period, from the end of the ranges covering after (or after itself) to the start
+    of the next range. Its upper bound is missing if no range follows it
+  - nil if the ranges of the tree cover every instant from after onwards
+*/
+func (tree *TimeRangeTree[K]) NextFreeGap(after time.Time) *TimeRange {
+	cursor := &TimeRange{lowerBound: after, boundsConf: TimeRangeIlEu, upperLimit: TimeRangeUnbounded}
+
+	// Jump to the end of the ranges covering the cursor until it lies in a gap
+	for covering := tree.coveringStart(tree.root, cursor, nil); covering != nil; covering = tree.coveringStart(tree.root, cursor, nil) {
+		if covering.upperLimit != TimeRangeFinite {
+			return nil
+		}
+		cursor = &TimeRange{
+			lowerBound: covering.upperBound,
+			boundsConf: boundsFromInclusion(!covering.upperInclusion(), false),
+			upperLimit: TimeRangeUnbounded,
+		}
+	}
+
+	if next := tree.nextStart(cursor); next != nil {
+		cursor.upperBound = next.lowerBound
+		cursor.upperLimit = TimeRangeFinite
+		cursor.boundsConf = boundsFromInclusion(cursor.lowerInclusion(), !next.lowerInclusion())
+	}
+	return cursor
+}
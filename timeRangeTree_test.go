@@ -0,0 +1,173 @@
+package bookk
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// randomTimeRanges creates ranges of up to 3 hours with random bounds configuration within a year
+func randomTimeRanges(random *rand.Rand, count int) []*TimeRange {
+	ranges := make([]*TimeRange, count)
+	for i := range ranges {
+		lower := testTime.Add(time.Duration(random.IntN(365*24*4)) * 15 * time.Minute)
+		upper := lower.Add(time.Duration(random.IntN(13)) * 15 * time.Minute)
+		ranges[i], _ = NewTimeRange(lower, upper, TimeRangeBound(random.IntN(4)))
+	}
+	return ranges
+}
+
+func naiveOverlapping(ranges []*TimeRange, r *TimeRange) []int {
+	keys := []int{}
+	for i, current := range ranges {
+		if current.Relation(r).SharesInstants() {
+			keys = append(keys, i)
+		}
+	}
+	return keys
+}
+
+func TestTimeRangeTreeOverlapping(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	ranges := randomTimeRanges(random, 2000)
+
+	tree := NewTimeRangeTree[int]()
+	for i, r := range ranges {
+		tree.Insert(i, r)
+	}
+
+	// Deleted ranges must not be found anymore
+	for i := 0; i < len(ranges); i += 3 {
+		if deleted := tree.Delete(i); deleted == ranges[i].IsEmpty() {
+			t.Fatalf("Range %d should only be in the tree if NOT empty", i)
+		}
+		ranges[i] = EmptyTimeRange()
+	}
+	if tree.Delete(0) {
+		t.Errorf("Range 0 should NOT be in the tree anymore")
+	}
+
+	for _, query := range randomTimeRanges(random, 300) {
+		expected := naiveOverlapping(ranges, query)
+		found := tree.Overlapping(query)
+		slices.Sort(found)
+		if !slices.Equal(found, expected) {
+			t.Fatalf("No expected ranges overlapping %s:\nExpecting\t: %v\nRecieved\t: %v", hoursRepr(query), expected, found)
+		}
+
+		expected = naiveOverlapping(ranges, &TimeRange{lowerBound: query.lowerBound, upperBound: query.lowerBound, boundsConf: TimeRangeBoundsInclusion})
+		found = tree.Containing(query.lowerBound)
+		slices.Sort(found)
+		if !slices.Equal(found, expected) {
+			t.Fatalf("No expected ranges containing %s:\nExpecting\t: %v\nRecieved\t: %v", query.lowerBound, expected, found)
+		}
+	}
+}
+
+func TestTimeRangeTreeInsert(t *testing.T) {
+	tree := NewTimeRangeTree[string]()
+	tree.Insert("first", hoursRange(0, 1, TimeRangeIlEu))
+	tree.Insert("first", hoursRange(2, 3, TimeRangeIlEu))
+	tree.Insert("empty", hoursRange(1, 1, TimeRangeIlEu))
+
+	if tree.Len() != 1 {
+		t.Errorf("Tree should have 1 range. Instead: %d", tree.Len())
+	}
+	if r, found := tree.Get("first"); !found || hoursRepr(r) != "[2,3)" {
+		t.Errorf("Inserting an existing key should replace its range. Instead: %v", r)
+	}
+	if found := tree.Overlapping(hoursRange(0, 1, TimeRangeBoundsInclusion)); len(found) != 0 {
+		t.Errorf("Replaced range should NOT be found. Instead: %v", found)
+	}
+}
+
+func TestTimeRangeTreeNextFreeGap(t *testing.T) {
+	tree := NewTimeRangeTree[string]()
+	tree.Insert("a", hoursRange(1, 2, TimeRangeIlEu))
+	tree.Insert("b", hoursRange(1.5, 3, TimeRangeBoundsInclusion))
+	tree.Insert("c", hoursRange(3, 4, TimeRangeElIu))
+	tree.Insert("d", hoursRange(5, 6, TimeRangeElIu))
+
+	type TestCase struct {
+		after    float64
+		expected string
+	}
+
+	testCases := [5]TestCase{
+		{0, "[0,1)"},
+		{1, "(4,5]"},
+		{4.5, "[4.5,5]"},
+		{5, "[5,5]"},
+		{5.5, "(6,)"},
+	}
+
+	for _, testCase := range testCases {
+		gap := tree.NextFreeGap(testTime.Add(time.Duration(testCase.after * float64(time.Hour))))
+		if gap == nil || hoursRepr(gap) != testCase.expected {
+			t.Errorf("No expected gap after %g:\nExpecting\t: %s\nRecieved\t: %v", testCase.after, testCase.expected, gap)
+		}
+	}
+
+	t.Run("Covered forever", func(t *testing.T) {
+		since, _ := NewTimeRangeFrom(testTime.Add(7*time.Hour), TimeRangeIlEu)
+		tree.Insert("e", since)
+		if gap := tree.NextFreeGap(testTime.Add(6 * time.Hour)); gap == nil || hoursRepr(gap) != "(6,7)" {
+			t.Errorf("No expected gap before the last range. Instead: %v", gap)
+		}
+		if gap := tree.NextFreeGap(testTime.Add(7 * time.Hour)); gap != nil {
+			t.Errorf("There should be no gap. Instead: %s", hoursRepr(gap))
+		}
+	})
+}
+
+func benchmarkRanges(count int) ([]*TimeRange, []*TimeRange) {
+	random := rand.New(rand.NewPCG(3, 4))
+	return randomTimeRanges(random, count), randomTimeRanges(random, 1000)
+}
+
+func BenchmarkTimeRangeTreeOverlapping(b *testing.B) {
+	for _, count := range [3]int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("%d ranges", count), func(b *testing.B) {
+			ranges, queries := benchmarkRanges(count)
+			tree := NewTimeRangeTree[int]()
+			for i, r := range ranges {
+				tree.Insert(i, r)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tree.Overlapping(queries[i%len(queries)])
+			}
+		})
+	}
+}
+
+func BenchmarkNaiveOverlapping(b *testing.B) {
+	for _, count := range [3]int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("%d ranges", count), func(b *testing.B) {
+			ranges, queries := benchmarkRanges(count)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				naiveOverlapping(ranges, queries[i%len(queries)])
+			}
+		})
+	}
+}
+
+func BenchmarkTimeRangeTreeInsertDelete(b *testing.B) {
+	ranges, _ := benchmarkRanges(10000)
+	tree := NewTimeRangeTree[int]()
+	for i, r := range ranges {
+		tree.Insert(i, r)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := i % len(ranges)
+		tree.Delete(key)
+		tree.Insert(key, ranges[key])
+	}
+}
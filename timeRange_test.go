@@ -9,7 +9,8 @@ import (
 )
 
 var (
-	testTime = time.Now()
+	// Midnight of the current day in UTC, so alignments to hours and days are easy to follow
+	testTime = time.Now().UTC().Truncate(24 * time.Hour)
 )
 
 func TestTimeRangeInitialization(t *testing.T) {
@@ -115,6 +116,115 @@ func TestTimeRangeFromPostgresRangeString(t *testing.T) {
 	}
 }
 
+func TestTimeRangeFromPostgresRangeStringWithLimits(t *testing.T) {
+	testCases := [6]string{
+		"empty",
+		"(,\"2025-01-01 00:00:00\")",
+		"[\"2025-01-01 00:00:00\",)",
+		"(,)",
+		"[-infinity,infinity]",
+		"(-infinity,\"2025-01-01 00:00:00\"]",
+	}
+
+	for _, testCase := range testCases {
+		timeRange, err := TimeRangeFromPostgresString(testCase)
+		if err != nil {
+			t.Errorf("There is an error in TimeRange string parser. Parser Error: %s", err.Error())
+			continue
+		}
+
+		timeRangeStringRepr := timeRange.ToPostgresRangeString()
+		if timeRangeStringRepr != testCase {
+			t.Errorf(
+				"No expected output:\nExpecting\t: %s\nRecieved\t: %s",
+				testCase,
+				timeRangeStringRepr,
+			)
+		}
+	}
+
+	t.Run("Missing bounds are always excluded", func(t *testing.T) {
+		timeRange, err := TimeRangeFromPostgresString("[,]")
+		if err != nil {
+			t.Fatalf("There is an error in TimeRange string parser. Parser Error: %s", err.Error())
+		}
+		if repr := timeRange.ToPostgresRangeString(); repr != "(,)" {
+			t.Errorf("No expected output:\nExpecting\t: (,)\nRecieved\t: %s", repr)
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, testCase := range [3]string{"", "[infinity,)", "[\"2025-01-01 00:00:00\"]"} {
+			if _, err := TimeRangeFromPostgresString(testCase); !errors.Is(err, timeRangeParseError) {
+				t.Errorf("Parsing %q should have failed due to: %s", testCase, timeRangeParseError.Error())
+			}
+		}
+	})
+}
+
+func TestTimeRangeWithLimits(t *testing.T) {
+	since, _ := NewTimeRangeFrom(testTime, TimeRangeIlEu)
+	until, _ := NewTimeRangeUntil(testTime.Add(time.Hour), TimeRangeIlEu)
+	always, _ := NewTimeRangeWithLimits(time.Time{}, time.Time{}, TimeRangeBoundsInclusion, TimeRangeUnbounded, TimeRangeUnbounded)
+	infinite, _ := NewTimeRangeWithLimits(time.Time{}, time.Time{}, TimeRangeBoundsInclusion, TimeRangeInfinite, TimeRangeInfinite)
+	finite := hoursRange(1, 2, TimeRangeBoundsInclusion)
+
+	t.Run("Contains", func(t *testing.T) {
+		if !since.Contains(finite) {
+			t.Errorf("%s should contain %s", hoursRepr(since), hoursRepr(finite))
+		}
+		if until.Contains(finite) {
+			t.Errorf("%s should NOT contain %s", hoursRepr(until), hoursRepr(finite))
+		}
+		if !always.Contains(since) || !always.Contains(until) {
+			t.Errorf("%s should contain any range", hoursRepr(always))
+		}
+		if since.Contains(always) {
+			t.Errorf("%s should NOT contain %s", hoursRepr(since), hoursRepr(always))
+		}
+		if infinite.Contains(until) || !always.Contains(infinite) {
+			t.Errorf("Infinite bounds should be inside missing bounds")
+		}
+		if !finite.Contains(EmptyTimeRange()) || EmptyTimeRange().Contains(finite) {
+			t.Errorf("Empty range should be contained by any range and contain nothing")
+		}
+	})
+
+	t.Run("Union", func(t *testing.T) {
+		if repr := hoursRepr(since.Union(until)); repr != "(,)" {
+			t.Errorf("No expected output:\nExpecting\t: (,)\nRecieved\t: %s", repr)
+		}
+		if repr := hoursRepr(until.Union(finite)); repr != "(,2]" {
+			t.Errorf("No expected output:\nExpecting\t: (,2]\nRecieved\t: %s", repr)
+		}
+		if repr := hoursRepr(EmptyTimeRange().Union(finite)); repr != "[1,2]" {
+			t.Errorf("No expected output:\nExpecting\t: [1,2]\nRecieved\t: %s", repr)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		merged := until.Merge(hoursRange(2, 3, TimeRangeIlEu))
+		if repr := multiHoursRepr(merged); repr != "(,1) [2,3)" {
+			t.Errorf("No expected output:\nExpecting\t: (,1) [2,3)\nRecieved\t: %s", repr)
+		}
+	})
+
+	t.Run("Difference", func(t *testing.T) {
+		if repr := multiHoursRepr(infinite.Difference(finite)); repr != "[-inf,1) (2,inf]" {
+			t.Errorf("No expected output:\nExpecting\t: [-inf,1) (2,inf]\nRecieved\t: %s", repr)
+		}
+	})
+
+	t.Run("Equal", func(t *testing.T) {
+		if !EmptyTimeRange().Equal(hoursRange(1, 1, TimeRangeIlEu)) {
+			t.Errorf("Empty ranges should be equal")
+		}
+		if since.Equal(always) {
+			t.Errorf("%s should NOT be equal to %s", hoursRepr(since), hoursRepr(always))
+		}
+	})
+}
+
 func TestTimeRangeContains(t *testing.T) {
 	containerIlEu, _ := NewTimeRange(
 		testTime,
@@ -362,3 +472,299 @@ func TestTimeRangeClone(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeRangeUnion(t *testing.T) {
+	type TestCase struct {
+		name     string
+		first    [2]time.Duration
+		firstB   TimeRangeBound
+		second   [2]time.Duration
+		secondB  TimeRangeBound
+		expected string
+	}
+
+	testCases := []TestCase{
+		{"Overlapping keeps outer bounds", [2]time.Duration{0, 2 * time.Hour}, TimeRangeElIu, [2]time.Duration{time.Hour, 3 * time.Hour}, TimeRangeIlEu, "(0,3)"},
+		{"Touching with one inclusive bound", [2]time.Duration{0, time.Hour}, TimeRangeIlEu, [2]time.Duration{time.Hour, 2 * time.Hour}, TimeRangeIlEu, "[0,2)"},
+		{"Contained", [2]time.Duration{0, 3 * time.Hour}, TimeRangeBoundsExclusion, [2]time.Duration{time.Hour, 2 * time.Hour}, TimeRangeBoundsInclusion, "(0,3)"},
+		{"Same bounds with different inclusion", [2]time.Duration{0, time.Hour}, TimeRangeIlEu, [2]time.Duration{0, time.Hour}, TimeRangeElIu, "[0,1]"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			first, _ := NewTimeRange(testTime.Add(testCase.first[0]), testTime.Add(testCase.first[1]), testCase.firstB)
+			second, _ := NewTimeRange(testTime.Add(testCase.second[0]), testTime.Add(testCase.second[1]), testCase.secondB)
+
+			for _, union := range [2]*TimeRange{first.Union(second), second.Union(first)} {
+				if union == nil {
+					t.Fatalf("Union should exist for %s and %s", first.ToPostgresRangeString(), second.ToPostgresRangeString())
+				}
+				if repr := hoursRepr(union); repr != testCase.expected {
+					t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.expected, repr)
+				}
+			}
+		})
+	}
+
+	t.Run("Touching with both bounds excluded", func(t *testing.T) {
+		first, _ := NewTimeRange(testTime, testTime.Add(time.Hour), TimeRangeIlEu)
+		second, _ := NewTimeRange(testTime.Add(time.Hour), testTime.Add(2*time.Hour), TimeRangeElIu)
+		if union := first.Union(second); union != nil {
+			t.Errorf("Union should not exist. Instead: %s", union.ToPostgresRangeString())
+		}
+	})
+}
+
+func TestTimeRangeIntersection(t *testing.T) {
+	type TestCase struct {
+		name     string
+		first    [2]time.Duration
+		firstB   TimeRangeBound
+		second   [2]time.Duration
+		secondB  TimeRangeBound
+		expected string
+	}
+
+	testCases := []TestCase{
+		{"Overlapping keeps inner bounds", [2]time.Duration{0, 2 * time.Hour}, TimeRangeIlEu, [2]time.Duration{time.Hour, 3 * time.Hour}, TimeRangeElIu, "(1,2)"},
+		{"Contained", [2]time.Duration{0, 3 * time.Hour}, TimeRangeBoundsExclusion, [2]time.Duration{time.Hour, 2 * time.Hour}, TimeRangeBoundsInclusion, "[1,2]"},
+		{"Same bounds with different inclusion", [2]time.Duration{0, time.Hour}, TimeRangeIlEu, [2]time.Duration{0, time.Hour}, TimeRangeElIu, "(0,1)"},
+		{"Touching with both bounds included", [2]time.Duration{0, time.Hour}, TimeRangeBoundsInclusion, [2]time.Duration{time.Hour, 2 * time.Hour}, TimeRangeIlEu, "[1,1]"},
+		{"Touching with one bound excluded", [2]time.Duration{0, time.Hour}, TimeRangeIlEu, [2]time.Duration{time.Hour, 2 * time.Hour}, TimeRangeIlEu, ""},
+		{"Separated", [2]time.Duration{0, time.Hour}, TimeRangeBoundsInclusion, [2]time.Duration{2 * time.Hour, 3 * time.Hour}, TimeRangeBoundsInclusion, ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			first, _ := NewTimeRange(testTime.Add(testCase.first[0]), testTime.Add(testCase.first[1]), testCase.firstB)
+			second, _ := NewTimeRange(testTime.Add(testCase.second[0]), testTime.Add(testCase.second[1]), testCase.secondB)
+
+			for _, intersection := range [2]*TimeRange{first.Intersection(second), second.Intersection(first)} {
+				repr := ""
+				if intersection != nil {
+					repr = hoursRepr(intersection)
+				}
+				if repr != testCase.expected {
+					t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.expected, repr)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeRangeDifference(t *testing.T) {
+	type TestCase struct {
+		name     string
+		first    [2]time.Duration
+		firstB   TimeRangeBound
+		second   [2]time.Duration
+		secondB  TimeRangeBound
+		expected string
+	}
+
+	testCases := []TestCase{
+		{"Cuts the upper part", [2]time.Duration{0, 2 * time.Hour}, TimeRangeIlEu, [2]time.Duration{time.Hour, 3 * time.Hour}, TimeRangeIlEu, "[0,1)"},
+		{"Cuts the lower part", [2]time.Duration{time.Hour, 3 * time.Hour}, TimeRangeIlEu, [2]time.Duration{0, 2 * time.Hour}, TimeRangeElIu, "(2,3)"},
+		{"Splits in two", [2]time.Duration{0, 3 * time.Hour}, TimeRangeBoundsInclusion, [2]time.Duration{time.Hour, 2 * time.Hour}, TimeRangeIlEu, "[0,1) [2,3]"},
+		{"Leaves the excluded bounds", [2]time.Duration{0, time.Hour}, TimeRangeBoundsInclusion, [2]time.Duration{0, time.Hour}, TimeRangeBoundsExclusion, "[0,0] [1,1]"},
+		{"Contained", [2]time.Duration{time.Hour, 2 * time.Hour}, TimeRangeBoundsInclusion, [2]time.Duration{0, 3 * time.Hour}, TimeRangeBoundsExclusion, ""},
+		{"Separated", [2]time.Duration{0, time.Hour}, TimeRangeIlEu, [2]time.Duration{time.Hour, 3 * time.Hour}, TimeRangeIlEu, "[0,1)"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			first, _ := NewTimeRange(testTime.Add(testCase.first[0]), testTime.Add(testCase.first[1]), testCase.firstB)
+			second, _ := NewTimeRange(testTime.Add(testCase.second[0]), testTime.Add(testCase.second[1]), testCase.secondB)
+
+			if repr := multiHoursRepr(first.Difference(second)); repr != testCase.expected {
+				t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.expected, repr)
+			}
+		})
+	}
+}
+
+func TestTimeRangeSymmetricDifference(t *testing.T) {
+	t.Run("Overlapping", func(t *testing.T) {
+		first, _ := NewTimeRange(testTime, testTime.Add(2*time.Hour), TimeRangeIlEu)
+		second, _ := NewTimeRange(testTime.Add(time.Hour), testTime.Add(3*time.Hour), TimeRangeBoundsInclusion)
+
+		expected := "[0,1) [2,3]"
+		if repr := multiHoursRepr(first.SymmetricDifference(second)); repr != expected {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+		}
+	})
+
+	t.Run("Touching ranges are merged", func(t *testing.T) {
+		first, _ := NewTimeRange(testTime, testTime.Add(time.Hour), TimeRangeIlEu)
+		second, _ := NewTimeRange(testTime.Add(time.Hour), testTime.Add(2*time.Hour), TimeRangeIlEu)
+
+		expected := "[0,2)"
+		if repr := multiHoursRepr(first.SymmetricDifference(second)); repr != expected {
+			t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", expected, repr)
+		}
+	})
+
+	t.Run("Equal ranges", func(t *testing.T) {
+		first, _ := NewTimeRange(testTime, testTime.Add(time.Hour), TimeRangeElIu)
+		if repr := multiHoursRepr(first.SymmetricDifference(first.Clone())); repr != "" {
+			t.Errorf("Symmetric difference should be empty. Instead: %s", repr)
+		}
+	})
+}
+
+// hoursRepr represents a TimeRange as the hours elapsed since testTime, e.g. "[0,1)"
+func hoursRepr(r *TimeRange) string {
+	if r.IsEmpty() {
+		return "empty"
+	}
+
+	lower, upper := "(", ")"
+	if r.lowerInclusion() {
+		lower = "["
+	}
+	if r.upperInclusion() {
+		upper = "]"
+	}
+	return lower + hoursBoundRepr(r.lowerBound, r.lowerLimit, "-inf") + "," +
+		hoursBoundRepr(r.upperBound, r.upperLimit, "inf") + upper
+}
+
+func hoursBoundRepr(bound time.Time, limit TimeRangeLimit, infinity string) string {
+	switch limit {
+	case TimeRangeUnbounded:
+		return ""
+	case TimeRangeInfinite:
+		return infinity
+	}
+	return fmt.Sprintf("%g", bound.Sub(testTime).Hours())
+}
+
+func multiHoursRepr(mr MultiTimeRange) string {
+	reprs := make([]string, len(mr))
+	for i, r := range mr {
+		reprs[i] = hoursRepr(r)
+	}
+	return strings.Join(reprs, " ")
+}
+
+func TestTimeRangeAccessors(t *testing.T) {
+	timeRange := hoursRange(1, 3, TimeRangeElIu)
+
+	if !timeRange.LowerBound().Equal(testTime.Add(time.Hour)) || !timeRange.UpperBound().Equal(testTime.Add(3*time.Hour)) {
+		t.Errorf("Bounds mismatch: %s", hoursRepr(timeRange))
+	}
+	if timeRange.Bounds() != TimeRangeElIu || timeRange.LowerInclusive() || !timeRange.UpperInclusive() {
+		t.Errorf("Bounds configuration mismatch: %s", hoursRepr(timeRange))
+	}
+	if timeRange.LowerLimit() != TimeRangeFinite || timeRange.UpperLimit() != TimeRangeFinite {
+		t.Errorf("Limits mismatch: %s", hoursRepr(timeRange))
+	}
+	if duration := timeRange.Duration(); duration != 2*time.Hour {
+		t.Errorf("Duration should be 2h. Instead: %s", duration)
+	}
+
+	since, _ := NewTimeRangeFrom(testTime, TimeRangeIlEu)
+	if !since.UpperBound().IsZero() || since.UpperLimit() != TimeRangeUnbounded {
+		t.Errorf("Missing upper bound should be the zero time: %s", hoursRepr(since))
+	}
+	if duration := EmptyTimeRange().Duration(); duration != 0 {
+		t.Errorf("Empty range duration should be 0. Instead: %s", duration)
+	}
+}
+
+func TestTimeRangeContainsTime(t *testing.T) {
+	timeRange := hoursRange(1, 3, TimeRangeElIu)
+
+	if timeRange.ContainsTime(testTime.Add(time.Hour)) {
+		t.Errorf("Excluded lower bound should NOT be contained in %s", hoursRepr(timeRange))
+	}
+	if !timeRange.ContainsTime(testTime.Add(2 * time.Hour)) {
+		t.Errorf("Inner instant should be contained in %s", hoursRepr(timeRange))
+	}
+	if !timeRange.ContainsTime(testTime.Add(3 * time.Hour)) {
+		t.Errorf("Included upper bound should be contained in %s", hoursRepr(timeRange))
+	}
+	if EmptyTimeRange().ContainsTime(testTime) {
+		t.Errorf("Empty range should NOT contain any instant")
+	}
+}
+
+func TestTimeRangeShiftExtend(t *testing.T) {
+	timeRange := hoursRange(1, 3, TimeRangeElIu)
+
+	if repr := hoursRepr(timeRange.Shift(-time.Hour)); repr != "(0,2]" {
+		t.Errorf("No expected Shift output:\nExpecting\t: (0,2]\nRecieved\t: %s", repr)
+	}
+	if repr := hoursRepr(timeRange.Extend(time.Hour, 30*time.Minute)); repr != "(0,3.5]" {
+		t.Errorf("No expected Extend output:\nExpecting\t: (0,3.5]\nRecieved\t: %s", repr)
+	}
+	if repr := hoursRepr(timeRange.Extend(-2*time.Hour, -time.Hour)); repr != "empty" {
+		t.Errorf("No expected Extend output:\nExpecting\t: empty\nRecieved\t: %s", repr)
+	}
+}
+
+func TestTimeRangeSplit(t *testing.T) {
+	type TestCase struct {
+		name      string
+		timeRange *TimeRange
+		step      time.Duration
+		expected  string
+	}
+
+	testCases := [4]TestCase{
+		{"Exact slots", hoursRange(0, 2, TimeRangeIlEu), 30 * time.Minute, "[0,0.5) [0.5,1) [1,1.5) [1.5,2)"},
+		{"Keeps outer bounds", hoursRange(0, 2, TimeRangeElIu), time.Hour, "(0,1) [1,2]"},
+		{"Shorter last slot", hoursRange(0, 2.5, TimeRangeIlEu), time.Hour, "[0,1) [1,2) [2,2.5)"},
+		{"Not positive step", hoursRange(0, 1, TimeRangeIlEu), 0, "[0,1)"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if repr := multiHoursRepr(testCase.timeRange.Split(testCase.step)); repr != testCase.expected {
+				t.Errorf("No expected output:\nExpecting\t: %s\nRecieved\t: %s", testCase.expected, repr)
+			}
+		})
+	}
+}
+
+func TestTimeRangeSplitAt(t *testing.T) {
+	timeRange := hoursRange(0, 2, TimeRangeBoundsInclusion)
+
+	before, after := timeRange.SplitAt(testTime.Add(time.Hour))
+	if before == nil || after == nil || hoursRepr(before) != "[0,1)" || hoursRepr(after) != "[1,2]" {
+		t.Errorf("No expected output:\nExpecting\t: [0,1) [1,2]\nRecieved\t: %v %v", before, after)
+	}
+
+	before, after = timeRange.SplitAt(testTime)
+	if before != nil || after == nil || hoursRepr(after) != "[0,2]" {
+		t.Errorf("Splitting at the lower bound should only have the second part")
+	}
+
+	before, after = timeRange.SplitAt(testTime.Add(3 * time.Hour))
+	if before == nil || after != nil || hoursRepr(before) != "[0,2]" {
+		t.Errorf("Splitting after the range should only have the first part")
+	}
+}
+
+func TestTimeRangeTruncate(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	timeRange, _ := NewTimeRange(base.Add(20*time.Minute), base.Add(150*time.Minute), TimeRangeBoundsInclusion)
+
+	truncated := timeRange.Truncate(time.Hour)
+	if !truncated.LowerBound().Equal(base) || !truncated.UpperBound().Equal(base.Add(2*time.Hour)) {
+		t.Errorf("No expected output:\nExpecting\t: [10:00,12:00]\nRecieved\t: %s", truncated.ToTstzrangeString())
+	}
+
+	// Both bounds truncated to 10:00
+	for bounds, empty := range map[TimeRangeBound]bool{
+		TimeRangeIlEu:            true,
+		TimeRangeElIu:            true,
+		TimeRangeBoundsExclusion: true,
+		TimeRangeBoundsInclusion: false,
+	} {
+		short, _ := NewTimeRange(base.Add(10*time.Minute), base.Add(20*time.Minute), bounds)
+		if truncated := short.Truncate(time.Hour); truncated.IsEmpty() != empty {
+			t.Errorf("No expected emptiness of %s:\nExpecting\t: %v\nRecieved\t: %v", truncated.ToTstzrangeString(), empty, truncated.IsEmpty())
+		}
+	}
+}
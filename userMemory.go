@@ -0,0 +1,420 @@
+package bookk
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	userNotFoundError         = errors.New("User not found")
+	userAlreadyExistsError    = errors.New("User already exists")
+	userRelationNotFoundError = errors.New("Users are not related")
+	userSelfRelationError     = errors.New("User cannot be related to itself")
+)
+
+/*
+MemoryUserRepository is an IUserRepository keeping users in memory.
+
+Deleting a user is a soft delete: its DeletedAt is set and the user is no longer returned nor
+accepted by any method, but it keeps its id. Relations between users are directed, so relating
+a user to another does not relate the second one back. Every user read or written is copied,
+so callers never share a user with the repository.
+
+A MemoryUserRepository is safe for concurrent use.
+*/
+type MemoryUserRepository struct {
+	mu        sync.RWMutex
+	users     map[string]*User
+	relations map[string][]string // Ids of the users related to every user, in the order they were related
+}
+
+var _ IUserRepository[User] = (*MemoryUserRepository)(nil)
+
+/*
+NewMemoryUserRepository creates an empty MemoryUserRepository.
+
+Returns:
+  - A pointer to a new MemoryUserRepository
+*/
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: map[string]*User{}, relations: map[string][]string{}}
+}
+
+func cloneUser(user *User) *User {
+	clone := *user
+	return &clone
+}
+
+// user returns a user not deleted. It must be called holding the lock
+func (r *MemoryUserRepository) user(id string) (*User, error) {
+	user, found := r.users[id]
+	if !found || !user.DeletedAt.IsZero() {
+		return nil, fmt.Errorf("%w: %q", userNotFoundError, id)
+	}
+	return user, nil
+}
+
+/*
+GetUser returns a user.
+
+Parameters:
+  - id: The id of the user
+
+Returns:
+  - A copy of the user
+  - An error if there is no user with the id or it was deleted
+*/
+func (r *MemoryUserRepository) GetUser(id string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, err := r.user(id)
+	if err != nil {
+		return nil, err
+	}
+	return cloneUser(user), nil
+}
+
+/*
+GetUserBatch returns a set of users. Missing and deleted users are skipped.
+
+Parameters:
+  - ids: The ids of the users
+
+Returns:
+  - Copies of the users found, in the order of ids
+  - An error, never returned by this implementation
+*/
+func (r *MemoryUserRepository) GetUserBatch(ids []string) ([]*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []*User{}
+	for _, id := range ids {
+		if user, err := r.user(id); err == nil {
+			users = append(users, cloneUser(user))
+		}
+	}
+	return users, nil
+}
+
+// prepareUser fills the id and creation time of a new user. It must be called holding the lock
+func (r *MemoryUserRepository) prepareUser(user *User, now time.Time) error {
+	if user.Id == "" {
+		user.Id = newRandomId()
+	} else if _, found := r.users[user.Id]; found {
+		return fmt.Errorf("%w: %q", userAlreadyExistsError, user.Id)
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	return nil
+}
+
+/*
+CreateUser stores a new user.
+
+An id is generated when the user has none, and CreatedAt is set to the current time when it is
+zero. Both are written to the given user.
+
+Parameters:
+  - user: The user to create
+
+Returns:
+  - The id of the user
+  - An error if the id is already taken, even by a deleted user
+*/
+func (r *MemoryUserRepository) CreateUser(user *User) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.prepareUser(user, time.Now()); err != nil {
+		return "", err
+	}
+	r.users[user.Id] = cloneUser(user)
+	return user.Id, nil
+}
+
+/*
+CreateUserBatch stores a set of new users as CreateUser does. Either every user is created or none is.
+
+Parameters:
+  - users: The users to create
+
+Returns:
+  - The ids of the users, in the order given
+  - An error if any id is already taken or repeated
+*/
+func (r *MemoryUserRepository) CreateUserBatch(users []*User) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	prepared := make([]*User, len(users))
+	ids := make([]string, len(users))
+	for i, user := range users {
+		prepared[i] = cloneUser(user)
+		if err := r.prepareUser(prepared[i], now); err != nil {
+			return nil, err
+		} else if slices.Contains(ids[:i], prepared[i].Id) {
+			return nil, fmt.Errorf("%w: %q", userAlreadyExistsError, prepared[i].Id)
+		}
+		ids[i] = prepared[i].Id
+	}
+
+	for i, user := range prepared {
+		users[i].Id, users[i].CreatedAt = user.Id, user.CreatedAt
+		r.users[user.Id] = user
+	}
+	return ids, nil
+}
+
+/*
+UpdateUser replaces a stored user. The CreatedAt of the stored user is kept when the given one
+is zero, and its DeletedAt is always kept; use DeleteUser to delete a user.
+
+Parameters:
+  - user: The user to update, identified by its id
+
+Returns:
+  - An error if there is no user with the id or it was deleted
+*/
+func (r *MemoryUserRepository) UpdateUser(user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, err := r.user(user.Id)
+	if err != nil {
+		return err
+	}
+	updated := cloneUser(user)
+	if updated.CreatedAt.IsZero() {
+		updated.CreatedAt = previous.CreatedAt
+	}
+	updated.DeletedAt = previous.DeletedAt
+	r.users[user.Id] = updated
+	return nil
+}
+
+/*
+DeleteUser soft deletes a user, setting its DeletedAt to the current time.
+
+Parameters:
+  - id: The id of the user
+
+Returns:
+  - An error if there is no user with the id or it was already deleted
+*/
+func (r *MemoryUserRepository) DeleteUser(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, err := r.user(id)
+	if err != nil {
+		return err
+	}
+	user.DeletedAt = time.Now()
+	return nil
+}
+
+/*
+DeleteUserBatch soft deletes a set of users as DeleteUser does. Missing and deleted users are skipped.
+
+Parameters:
+  - ids: The ids of the users
+
+Returns:
+  - An error, never returned by this implementation
+*/
+func (r *MemoryUserRepository) DeleteUserBatch(ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, id := range ids {
+		if user, err := r.user(id); err == nil {
+			user.DeletedAt = now
+		}
+	}
+	return nil
+}
+
+/*
+SetBan sets until when a user is banned.
+
+Parameters:
+  - id: The id of the user
+  - banUntil: The end of the ban. A zero time lifts the ban
+
+Returns:
+  - An error if there is no user with the id or it was deleted
+*/
+func (r *MemoryUserRepository) SetBan(id string, banUntil time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, err := r.user(id)
+	if err != nil {
+		return err
+	}
+	user.BannedUntil = banUntil
+	return nil
+}
+
+/*
+GetRelatedUsers returns the users a user is related to. Deleted users are skipped.
+
+Parameters:
+  - id: The id of the user
+
+Returns:
+  - Copies of the related users, in the order they were related
+  - An error if there is no user with the id or it was deleted
+*/
+func (r *MemoryUserRepository) GetRelatedUsers(id string) ([]*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, err := r.user(id); err != nil {
+		return nil, err
+	}
+	users := []*User{}
+	for _, relatedId := range r.relations[id] {
+		if user, err := r.user(relatedId); err == nil {
+			users = append(users, cloneUser(user))
+		}
+	}
+	return users, nil
+}
+
+/*
+GetRelatedUsersByRole returns the ids of the users with a role a user is related to. Deleted users are skipped.
+
+Parameters:
+  - id: The id of the user
+  - role: The role of the related users, such as ROLE_USER
+
+Returns:
+  - The ids of the related users, in the order they were related
+  - An error if there is no user with the id or it was deleted
+*/
+func (r *MemoryUserRepository) GetRelatedUsersByRole(id string, role int) ([]string, error) {
+	users, err := r.GetRelatedUsers(id)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, user := range users {
+		if user.Role == role {
+			ids = append(ids, user.Id)
+		}
+	}
+	return ids, nil
+}
+
+/*
+RelateUsers relates a user to another. Relating users already related does nothing.
+
+Parameters:
+  - userId: The id of the user
+  - relatedUserId: The id of the user to relate to
+
+Returns:
+  - An error if any of the users does not exist or was deleted, or both are the same user
+*/
+func (r *MemoryUserRepository) RelateUsers(userId, relatedUserId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if userId == relatedUserId {
+		return fmt.Errorf("%w: %q", userSelfRelationError, userId)
+	}
+	for _, id := range [2]string{userId, relatedUserId} {
+		if _, err := r.user(id); err != nil {
+			return err
+		}
+	}
+	if !slices.Contains(r.relations[userId], relatedUserId) {
+		r.relations[userId] = append(r.relations[userId], relatedUserId)
+	}
+	return nil
+}
+
+/*
+RemoveRelation removes the relation of a user to another.
+
+Parameters:
+  - userId: The id of the user
+  - relatedUserId: The id of the related user
+
+Returns:
+  - An error if the user is not related to the other one
+*/
+func (r *MemoryUserRepository) RemoveRelation(userId, relatedUserId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := slices.Index(r.relations[userId], relatedUserId)
+	if index < 0 {
+		return fmt.Errorf("%w: %q and %q", userRelationNotFoundError, userId, relatedUserId)
+	}
+	r.relations[userId] = slices.Delete(r.relations[userId], index, index+1)
+	return nil
+}
+
+// restoreUser stores a user as it is, even if deleted
+func (r *MemoryUserRepository) restoreUser(user *User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.Id] = cloneUser(user)
+}
+
+// storedUser returns a copy of a user as it is stored, even if deleted
+func (r *MemoryUserRepository) storedUser(id string) (*User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, found := r.users[id]
+	if !found {
+		return nil, false
+	}
+	return cloneUser(user), true
+}
+
+// replace takes the users and relations of another repository, which must not be used anymore
+func (r *MemoryUserRepository) replace(other *MemoryUserRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users, r.relations = other.users, other.relations
+}
+
+// restoreRelation relates a user to another, or removes the relation, without checking the users
+func (r *MemoryUserRepository) restoreRelation(link *fileStoreLink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	related := slices.DeleteFunc(r.relations[link.From], func(id string) bool { return id == link.To })
+	if !link.Removed {
+		related = append(related, link.To)
+	}
+	r.relations[link.From] = related
+}
+
+// snapshot copies every user, sorted by id, and their relations
+func (r *MemoryUserRepository) snapshot() ([]*User, map[string][]string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := []*User{}
+	for _, id := range slices.Sorted(maps.Keys(r.users)) {
+		users = append(users, cloneUser(r.users[id]))
+	}
+	relations := map[string][]string{}
+	for id, related := range r.relations {
+		if len(related) > 0 {
+			relations[id] = slices.Clone(related)
+		}
+	}
+	return users, relations
+}
@@ -0,0 +1,162 @@
+package bookk
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func userIds(users []*User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.Id
+	}
+	return ids
+}
+
+// newTestUserRepository creates a repository with users a, b and c, where b is an enterprise
+func newTestUserRepository(t *testing.T) *MemoryUserRepository {
+	repository := NewMemoryUserRepository()
+	_, err := repository.CreateUserBatch([]*User{
+		{BaseUser{Id: "a"}},
+		{BaseUser{Id: "b", Role: ROLE_ENTERPRISE}},
+		{BaseUser{Id: "c"}},
+	})
+	if err != nil {
+		t.Fatalf("Users should be created. Instead: %v", err)
+	}
+	return repository
+}
+
+func TestMemoryUserRepositoryCreate(t *testing.T) {
+	repository := newTestUserRepository(t)
+
+	t.Run("Generates id", func(t *testing.T) {
+		user := &User{BaseUser{Email: "user@bookk.dev"}}
+		id, err := repository.CreateUser(user)
+		if err != nil || id == "" || user.Id != id || user.CreatedAt.IsZero() {
+			t.Fatalf("User should be created with id and creation time. Instead: %q %v", id, err)
+		}
+		if stored, _ := repository.GetUser(id); stored.Email != user.Email {
+			t.Errorf("User should be stored. Instead: %v", stored)
+		}
+	})
+
+	t.Run("Existing id", func(t *testing.T) {
+		if _, err := repository.CreateUser(&User{BaseUser{Id: "a"}}); !errors.Is(err, userAlreadyExistsError) {
+			t.Errorf("User with an existing id should fail. Instead: %v", err)
+		}
+	})
+
+	t.Run("Batch is atomic", func(t *testing.T) {
+		_, err := repository.CreateUserBatch([]*User{{BaseUser{Id: "d"}}, {BaseUser{Id: "d"}}})
+		if !errors.Is(err, userAlreadyExistsError) {
+			t.Errorf("Batch with a repeated id should fail. Instead: %v", err)
+		}
+		if _, err := repository.GetUser("d"); !errors.Is(err, userNotFoundError) {
+			t.Errorf("No user of a failed batch should be created. Instead: %v", err)
+		}
+	})
+}
+
+func TestMemoryUserRepositoryDelete(t *testing.T) {
+	repository := newTestUserRepository(t)
+	if err := repository.DeleteUser("a"); err != nil {
+		t.Fatalf("User should be deleted. Instead: %v", err)
+	}
+	if _, err := repository.GetUser("a"); !errors.Is(err, userNotFoundError) {
+		t.Errorf("Deleted user should not be found. Instead: %v", err)
+	}
+	if err := repository.UpdateUser(&User{BaseUser{Id: "a"}}); !errors.Is(err, userNotFoundError) {
+		t.Errorf("Deleted user should not be updated. Instead: %v", err)
+	}
+	if _, err := repository.CreateUser(&User{BaseUser{Id: "a"}}); !errors.Is(err, userAlreadyExistsError) {
+		t.Errorf("Id of a deleted user should still be taken. Instead: %v", err)
+	}
+	if repository.users["a"].DeletedAt.IsZero() {
+		t.Errorf("Deleted user should keep its deletion time")
+	}
+
+	if err := repository.DeleteUserBatch([]string{"a", "b", "missing"}); err != nil {
+		t.Errorf("Batch deletion should skip missing users. Instead: %v", err)
+	}
+	if users, _ := repository.GetUserBatch([]string{"c", "b", "a"}); !slices.Equal(userIds(users), []string{"c"}) {
+		t.Errorf("Only user c should remain. Instead: %v", userIds(users))
+	}
+}
+
+func TestMemoryUserRepositoryUpdate(t *testing.T) {
+	repository := newTestUserRepository(t)
+	created, _ := repository.GetUser("a")
+
+	if err := repository.UpdateUser(&User{BaseUser{Id: "a", Email: "a@bookk.dev"}}); err != nil {
+		t.Fatalf("User should be updated. Instead: %v", err)
+	}
+	until := testTime.Add(time.Hour)
+	if err := repository.SetBan("a", until); err != nil {
+		t.Fatalf("User should be banned. Instead: %v", err)
+	}
+
+	user, _ := repository.GetUser("a")
+	if user.Email != "a@bookk.dev" || !user.CreatedAt.Equal(created.CreatedAt) || !user.BannedUntil.Equal(until) {
+		t.Errorf("User should be updated keeping its creation time. Instead: %v", user)
+	}
+}
+
+func TestMemoryUserRepositoryRelations(t *testing.T) {
+	repository := newTestUserRepository(t)
+	for _, relation := range [3][2]string{{"a", "c"}, {"a", "b"}, {"a", "c"}} {
+		if err := repository.RelateUsers(relation[0], relation[1]); err != nil {
+			t.Fatalf("Users %v should be related. Instead: %v", relation, err)
+		}
+	}
+
+	if users, _ := repository.GetRelatedUsers("a"); !slices.Equal(userIds(users), []string{"c", "b"}) {
+		t.Errorf("No expected related users. Instead: %v", userIds(users))
+	}
+	if users, _ := repository.GetRelatedUsers("c"); len(users) != 0 {
+		t.Errorf("Relations should be directed. Instead: %v", userIds(users))
+	}
+	if ids, _ := repository.GetRelatedUsersByRole("a", ROLE_ENTERPRISE); !slices.Equal(ids, []string{"b"}) {
+		t.Errorf("No expected related enterprises. Instead: %v", ids)
+	}
+
+	if err := repository.RelateUsers("a", "a"); !errors.Is(err, userSelfRelationError) {
+		t.Errorf("User should not be related to itself. Instead: %v", err)
+	}
+	if err := repository.RelateUsers("a", "missing"); !errors.Is(err, userNotFoundError) {
+		t.Errorf("User should not be related to a missing user. Instead: %v", err)
+	}
+
+	if err := repository.RemoveRelation("a", "c"); err != nil {
+		t.Fatalf("Relation should be removed. Instead: %v", err)
+	}
+	if err := repository.RemoveRelation("a", "c"); !errors.Is(err, userRelationNotFoundError) {
+		t.Errorf("Removing a missing relation should fail. Instead: %v", err)
+	}
+	repository.DeleteUser("b")
+	if users, _ := repository.GetRelatedUsers("a"); len(users) != 0 {
+		t.Errorf("Deleted users should not be related. Instead: %v", userIds(users))
+	}
+}
+
+func TestMemoryUserRepositoryConcurrency(t *testing.T) {
+	repository := NewMemoryUserRepository()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, _ := repository.CreateUser(&User{})
+			repository.GetUserBatch([]string{id})
+			repository.DeleteUser(id)
+		}()
+	}
+	wg.Wait()
+
+	if len(repository.users) != 50 {
+		t.Errorf("Every user should be created. Instead: %d", len(repository.users))
+	}
+}